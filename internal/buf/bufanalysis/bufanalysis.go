@@ -30,9 +30,46 @@ const (
 	FormatJSON
 	// FormatMSVS is the MSVS format for FileAnnotations.
 	FormatMSVS
+	// FormatSARIF is the SARIF 2.1.0 format for FileAnnotations.
+	//
+	// Unlike the other formats, all FileAnnotations are printed as a single SARIF log.
+	//
+	// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+	FormatSARIF
+	// FormatJUnit is the JUnit XML format for FileAnnotations.
+	//
+	// All FileAnnotations are printed as a single XML document, where each file
+	// is a testsuite and each FileAnnotation is a testcase. Only FileAnnotations
+	// with SeverityError are failing testcases.
+	FormatJUnit
+	// FormatGitHubActions is the GitHub Actions workflow command format for FileAnnotations.
+	//
+	// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#setting-an-error-message
+	FormatGitHubActions
+)
+
+const (
+	// SeverityError is the error severity.
+	//
+	// FileAnnotations with this severity result in a failure. This is the
+	// default severity.
+	SeverityError Severity = iota + 1
+	// SeverityWarning is the warning severity.
+	SeverityWarning
+	// SeverityInfo is the info severity.
+	SeverityInfo
 )
 
 var (
+	// AllSeverityStrings is all severity strings.
+	//
+	// Sorted from most to least severe.
+	AllSeverityStrings = []string{
+		"error",
+		"warning",
+		"info",
+	}
+
 	// AllFormatStrings is all format strings without aliases.
 	//
 	// Sorted in the order we want to display them.
@@ -40,6 +77,9 @@ var (
 		"text",
 		"json",
 		"msvs",
+		"sarif",
+		"junit",
+		"github-actions",
 	}
 	// AllFormatStringsWithAliases is all format strings with aliases.
 	//
@@ -49,19 +89,38 @@ var (
 		"gcc",
 		"json",
 		"msvs",
+		"sarif",
+		"junit",
+		"github-actions",
 	}
 
 	stringToFormat = map[string]Format{
 		"text": FormatText,
 		// alias for text
-		"gcc":  FormatText,
-		"json": FormatJSON,
-		"msvs": FormatMSVS,
+		"gcc":            FormatText,
+		"json":           FormatJSON,
+		"msvs":           FormatMSVS,
+		"sarif":          FormatSARIF,
+		"junit":          FormatJUnit,
+		"github-actions": FormatGitHubActions,
+	}
+	stringToSeverity = map[string]Severity{
+		"error":   SeverityError,
+		"warning": SeverityWarning,
+		"info":    SeverityInfo,
+	}
+	severityToString = map[Severity]string{
+		SeverityError:   "error",
+		SeverityWarning: "warning",
+		SeverityInfo:    "info",
 	}
 	formatToString = map[Format]string{
-		FormatText: "text",
-		FormatJSON: "json",
-		FormatMSVS: "msvs",
+		FormatText:          "text",
+		FormatJSON:          "json",
+		FormatMSVS:          "msvs",
+		FormatSARIF:         "sarif",
+		FormatJUnit:         "junit",
+		FormatGitHubActions: "github-actions",
 	}
 )
 
@@ -92,6 +151,33 @@ func ParseFormat(s string) (Format, error) {
 	return 0, fmt.Errorf("unknown format: %q", s)
 }
 
+// Severity is the severity of a FileAnnotation.
+type Severity int
+
+// String implements fmt.Stringer.
+func (s Severity) String() string {
+	str, ok := severityToString[s]
+	if !ok {
+		return strconv.Itoa(int(s))
+	}
+	return str
+}
+
+// ParseSeverity parses the Severity.
+//
+// The empty string defaults to SeverityError.
+func ParseSeverity(s string) (Severity, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return SeverityError, nil
+	}
+	severity, ok := stringToSeverity[s]
+	if ok {
+		return severity, nil
+	}
+	return 0, fmt.Errorf("unknown severity: %q", s)
+}
+
 // FileInfo is a minimal FileInfo interface.
 type FileInfo interface {
 	Path() string
@@ -138,9 +224,30 @@ type FileAnnotation interface {
 	Type() string
 	// Message is the message of the annotation.
 	Message() string
+	// Severity is the severity of the annotation.
+	//
+	// Only FileAnnotations with SeverityError should result in a failure.
+	// This is never 0.
+	//
+	// SeverityError is not printed in the text and JSON formats.
+	Severity() Severity
+}
+
+// Rule is the metadata of the rule that a FileAnnotation was created for.
+//
+// The ID is equal to the Type of the FileAnnotation.
+type Rule interface {
+	// ID returns the ID of the Rule.
+	ID() string
+	// Categories returns the categories of the Rule.
+	Categories() []string
+	// Purpose returns the purpose of the Rule.
+	Purpose() string
 }
 
 // NewFileAnnotation returns a new FileAnnotation.
+//
+// The FileAnnotation has SeverityError.
 func NewFileAnnotation(
 	fileInfo FileInfo,
 	startLine int,
@@ -161,6 +268,32 @@ func NewFileAnnotation(
 	)
 }
 
+// NewFileAnnotationWithSeverity returns a copy of the FileAnnotation with the given Severity.
+//
+// If severity is 0, SeverityError is used.
+func NewFileAnnotationWithSeverity(fileAnnotation FileAnnotation, severity Severity) FileAnnotation {
+	return newFileAnnotationWithSeverity(
+		fileAnnotation.FileInfo(),
+		fileAnnotation.StartLine(),
+		fileAnnotation.StartColumn(),
+		fileAnnotation.EndLine(),
+		fileAnnotation.EndColumn(),
+		fileAnnotation.Type(),
+		fileAnnotation.Message(),
+		severity,
+	)
+}
+
+// HasSeverityError returns true if any of the FileAnnotations has SeverityError.
+func HasSeverityError(fileAnnotations []FileAnnotation) bool {
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation.Severity() == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
 // SortFileAnnotations sorts the FileAnnotations.
 //
 // The order of sorting is:
@@ -197,11 +330,29 @@ func DeduplicateAndSortFileAnnotations(fileAnnotations []FileAnnotation) ([]File
 }
 
 // PrintFileAnnotations prints the file annotations separated by newlines.
-func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, formatString string) error {
+//
+// If the format is FormatSARIF or FormatJUnit, a single document is printed
+// instead, even if there are no FileAnnotations.
+func PrintFileAnnotations(
+	writer io.Writer,
+	fileAnnotations []FileAnnotation,
+	formatString string,
+	options ...PrintFileAnnotationsOption,
+) error {
 	format, err := ParseFormat(formatString)
 	if err != nil {
 		return err
 	}
+	printFileAnnotationsOptions := newPrintFileAnnotationsOptions()
+	for _, option := range options {
+		option(printFileAnnotationsOptions)
+	}
+	if format == FormatSARIF {
+		return printFileAnnotationsSARIF(writer, fileAnnotations, printFileAnnotationsOptions.rules)
+	}
+	if format == FormatJUnit {
+		return printFileAnnotationsJUnit(writer, fileAnnotations)
+	}
 	for _, fileAnnotation := range fileAnnotations {
 		s, err := FormatFileAnnotation(fileAnnotation, format)
 		if err != nil {
@@ -214,7 +365,24 @@ func PrintFileAnnotations(writer io.Writer, fileAnnotations []FileAnnotation, fo
 	return nil
 }
 
+// PrintFileAnnotationsOption is an option for PrintFileAnnotations.
+type PrintFileAnnotationsOption func(*printFileAnnotationsOptions)
+
+// PrintFileAnnotationsWithRules returns a new PrintFileAnnotationsOption that
+// adds the metadata of the given Rules to the output.
+//
+// This is only used by FormatSARIF. Rules that are not referenced by a
+// FileAnnotation are not printed.
+func PrintFileAnnotationsWithRules(rules []Rule) PrintFileAnnotationsOption {
+	return func(printFileAnnotationsOptions *printFileAnnotationsOptions) {
+		printFileAnnotationsOptions.rules = rules
+	}
+}
+
 // FormatFileAnnotation formats the FileAnnotation.
+//
+// FormatSARIF and FormatJUnit are not supported, as they can only be used for
+// multiple FileAnnotations, use PrintFileAnnotations instead.
 func FormatFileAnnotation(fileAnnotation FileAnnotation, format Format) (string, error) {
 	switch format {
 	case FormatText:
@@ -227,6 +395,10 @@ func FormatFileAnnotation(fileAnnotation FileAnnotation, format Format) (string,
 		return string(data), nil
 	case FormatMSVS:
 		return fileAnnotation.MSVSString(), nil
+	case FormatGitHubActions:
+		return formatFileAnnotationGitHubActions(fileAnnotation), nil
+	case FormatSARIF, FormatJUnit:
+		return "", fmt.Errorf("%v format can only be used to print multiple FileAnnotations", format)
 	default:
 		return "", fmt.Errorf("unknown FileAnnotation Format: %v", format)
 	}
@@ -316,3 +488,11 @@ func fileAnnotationCompareTo(a FileAnnotation, b FileAnnotation) int {
 	}
 	return 0
 }
+
+type printFileAnnotationsOptions struct {
+	rules []Rule
+}
+
+func newPrintFileAnnotationsOptions() *printFileAnnotationsOptions {
+	return &printFileAnnotationsOptions{}
+}
@@ -15,6 +15,7 @@
 package bufanalysistesting
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
@@ -43,6 +44,9 @@ func TestBasic(t *testing.T) {
 	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatMSVS)
 	require.NoError(t, err)
 	assert.Equal(t, `path/to/file.proto(1) : error FOO : Hello.`, s)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::error file=path/to/file.proto,line=1,endLine=1,title=FOO::Hello.`, s)
 
 	fileAnnotation = newFileAnnotation(
 		t,
@@ -63,4 +67,304 @@ func TestBasic(t *testing.T) {
 	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatMSVS)
 	require.NoError(t, err)
 	assert.Equal(t, `path/to/file.proto(2,1) : error FOO : Hello.`, s)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::error file=path/to/file.proto,line=2,endLine=2,col=1,endColumn=1,title=FOO::Hello.`, s)
+
+	fileAnnotation = newFileAnnotation(
+		t,
+		"",
+		0,
+		0,
+		0,
+		0,
+		"FOO",
+		"Hello, 100%\nworld.",
+	)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::error title=FOO::Hello, 100%25%0Aworld.`, s)
+}
+
+func TestSeverity(t *testing.T) {
+	t.Parallel()
+	fileAnnotation := bufanalysis.NewFileAnnotationWithSeverity(
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			2,
+			1,
+			2,
+			1,
+			"FOO",
+			"Hello.",
+		),
+		bufanalysis.SeverityWarning,
+	)
+	assert.Equal(t, bufanalysis.SeverityWarning, fileAnnotation.Severity())
+	s, err := bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatText)
+	require.NoError(t, err)
+	assert.Equal(t, `path/to/file.proto:2:1:warning:Hello.`, s)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, `{"path":"path/to/file.proto","start_line":2,"start_column":1,"end_line":2,"end_column":1,"type":"FOO","message":"Hello.","severity":"warning"}`, s)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatMSVS)
+	require.NoError(t, err)
+	assert.Equal(t, `path/to/file.proto(2,1) : warning FOO : Hello.`, s)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::warning file=path/to/file.proto,line=2,endLine=2,col=1,endColumn=1,title=FOO::Hello.`, s)
+	assert.False(t, bufanalysis.HasSeverityError([]bufanalysis.FileAnnotation{fileAnnotation}))
+
+	fileAnnotation = bufanalysis.NewFileAnnotationWithSeverity(fileAnnotation, bufanalysis.SeverityInfo)
+	s, err = bufanalysis.FormatFileAnnotation(fileAnnotation, bufanalysis.FormatGitHubActions)
+	require.NoError(t, err)
+	assert.Equal(t, `::notice file=path/to/file.proto,line=2,endLine=2,col=1,endColumn=1,title=FOO::Hello.`, s)
+	errorFileAnnotation := newFileAnnotation(t, "", 0, 0, 0, 0, "BAR", "")
+	assert.Equal(t, bufanalysis.SeverityError, errorFileAnnotation.Severity())
+	assert.True(t, bufanalysis.HasSeverityError([]bufanalysis.FileAnnotation{fileAnnotation, errorFileAnnotation}))
+
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, bufanalysis.PrintFileAnnotations(buffer, []bufanalysis.FileAnnotation{fileAnnotation}, "junit"))
+	assert.Equal(
+		t,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="1" failures="0">
+  <testsuite name="path/to/file.proto" tests="1" failures="0" errors="0">
+    <testcase name="FOO_2_1" classname="path/to/file.proto">
+      <system-out>path/to/file.proto:2:1:info:Hello.</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		buffer.String(),
+	)
+
+	severity, err := bufanalysis.ParseSeverity("")
+	require.NoError(t, err)
+	assert.Equal(t, bufanalysis.SeverityError, severity)
+	severity, err = bufanalysis.ParseSeverity("WARNING")
+	require.NoError(t, err)
+	assert.Equal(t, bufanalysis.SeverityWarning, severity)
+	_, err = bufanalysis.ParseSeverity("fatal")
+	require.Error(t, err)
+}
+
+func TestSARIF(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"path/to/file.proto",
+			2,
+			1,
+			2,
+			5,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"",
+			0,
+			0,
+			0,
+			0,
+			"BAR",
+			"",
+		),
+	}
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(
+		t,
+		bufanalysis.PrintFileAnnotations(
+			buffer,
+			fileAnnotations,
+			"sarif",
+			bufanalysis.PrintFileAnnotationsWithRules(
+				[]bufanalysis.Rule{
+					newRule("FOO", "Checks foo.", "BASIC"),
+					newRule("BAZ", "Checks baz.", "BASIC"),
+				},
+			),
+		),
+	)
+	assert.JSONEq(
+		t,
+		`{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "buf",
+          "informationUri": "https://github.com/bufbuild/buf",
+          "rules": [
+            {
+              "id": "BAR"
+            },
+            {
+              "id": "FOO",
+              "shortDescription": {
+                "text": "Checks foo."
+              },
+              "properties": {
+                "categories": ["BASIC"]
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "FOO",
+          "ruleIndex": 1,
+          "level": "error",
+          "message": {
+            "text": "Hello."
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "path/to/file.proto"
+                },
+                "region": {
+                  "startLine": 2,
+                  "startColumn": 1,
+                  "endLine": 2,
+                  "endColumn": 5
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "BAR",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "BAR"
+          }
+        }
+      ]
+    }
+  ]
+}`,
+		buffer.String(),
+	)
+	_, err := bufanalysis.FormatFileAnnotation(fileAnnotations[0], bufanalysis.FormatSARIF)
+	require.Error(t, err)
+}
+
+func TestJUnit(t *testing.T) {
+	t.Parallel()
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		newFileAnnotation(
+			t,
+			"",
+			0,
+			0,
+			0,
+			0,
+			"BAR",
+			"",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/a.proto",
+			2,
+			1,
+			2,
+			5,
+			"FOO",
+			"Hello.",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/a.proto",
+			3,
+			0,
+			3,
+			0,
+			"BAZ",
+			"<Hello>",
+		),
+		newFileAnnotation(
+			t,
+			"path/to/b.proto",
+			4,
+			2,
+			4,
+			3,
+			"FOO",
+			"Hello.",
+		),
+	}
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, bufanalysis.PrintFileAnnotations(buffer, fileAnnotations, "junit"))
+	assert.Equal(
+		t,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="4" failures="4">
+  <testsuite name="&lt;input&gt;" tests="1" failures="1" errors="0">
+    <testcase name="BAR" classname="&lt;input&gt;">
+      <failure message="BAR" type="BAR">&lt;input&gt;:1:1:BAR</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="path/to/a.proto" tests="2" failures="2" errors="0">
+    <testcase name="FOO_2_1" classname="path/to/a.proto">
+      <failure message="Hello." type="FOO">path/to/a.proto:2:1:Hello.</failure>
+    </testcase>
+    <testcase name="BAZ_3" classname="path/to/a.proto">
+      <failure message="&lt;Hello&gt;" type="BAZ">path/to/a.proto:3:1:&lt;Hello&gt;</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="path/to/b.proto" tests="1" failures="1" errors="0">
+    <testcase name="FOO_4_2" classname="path/to/b.proto">
+      <failure message="Hello." type="FOO">path/to/b.proto:4:2:Hello.</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		buffer.String(),
+	)
+	buffer.Reset()
+	require.NoError(t, bufanalysis.PrintFileAnnotations(buffer, nil, "junit"))
+	assert.Equal(
+		t,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="0" failures="0"></testsuites>
+`,
+		buffer.String(),
+	)
+	_, err := bufanalysis.FormatFileAnnotation(fileAnnotations[0], bufanalysis.FormatJUnit)
+	require.Error(t, err)
+}
+
+type testRule struct {
+	id         string
+	purpose    string
+	categories []string
+}
+
+func newRule(id string, purpose string, categories ...string) *testRule {
+	return &testRule{
+		id:         id,
+		purpose:    purpose,
+		categories: categories,
+	}
+}
+
+func (r *testRule) ID() string {
+	return r.id
+}
+
+func (r *testRule) Categories() []string {
+	return r.categories
+}
+
+func (r *testRule) Purpose() string {
+	return r.purpose
 }
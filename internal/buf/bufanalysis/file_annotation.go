@@ -28,6 +28,7 @@ type fileAnnotation struct {
 	endColumn   int
 	typeString  string
 	message     string
+	severity    Severity
 }
 
 func newFileAnnotation(
@@ -39,6 +40,31 @@ func newFileAnnotation(
 	typeString string,
 	message string,
 ) *fileAnnotation {
+	return newFileAnnotationWithSeverity(
+		fileInfo,
+		startLine,
+		startColumn,
+		endLine,
+		endColumn,
+		typeString,
+		message,
+		SeverityError,
+	)
+}
+
+func newFileAnnotationWithSeverity(
+	fileInfo FileInfo,
+	startLine int,
+	startColumn int,
+	endLine int,
+	endColumn int,
+	typeString string,
+	message string,
+	severity Severity,
+) *fileAnnotation {
+	if severity == 0 {
+		severity = SeverityError
+	}
 	return &fileAnnotation{
 		fileInfo:    fileInfo,
 		startLine:   startLine,
@@ -47,6 +73,7 @@ func newFileAnnotation(
 		endColumn:   endColumn,
 		typeString:  typeString,
 		message:     message,
+		severity:    severity,
 	}
 }
 
@@ -78,6 +105,10 @@ func (f *fileAnnotation) Message() string {
 	return f.message
 }
 
+func (f *fileAnnotation) Severity() Severity {
+	return f.severity
+}
+
 func (f *fileAnnotation) String() string {
 	if f == nil {
 		return ""
@@ -109,6 +140,12 @@ func (f *fileAnnotation) String() string {
 	_, _ = buffer.WriteRune(':')
 	_, _ = buffer.WriteString(strconv.Itoa(column))
 	_, _ = buffer.WriteRune(':')
+	// errors are not prefixed, so that the output for errors is unchanged
+	// from before severities were introduced
+	if f.severity != SeverityError {
+		_, _ = buffer.WriteString(f.severity.String())
+		_, _ = buffer.WriteRune(':')
+	}
 	_, _ = buffer.WriteString(message)
 	return buffer.String()
 }
@@ -154,7 +191,9 @@ func (f *fileAnnotation) MSVSString() string {
 		_, _ = buffer.WriteRune(',')
 		_, _ = buffer.WriteString(strconv.Itoa(column))
 	}
-	_, _ = buffer.WriteString(") : error ")
+	_, _ = buffer.WriteString(") : ")
+	_, _ = buffer.WriteString(f.severity.String())
+	_, _ = buffer.WriteRune(' ')
 	_, _ = buffer.WriteString(typeString)
 	_, _ = buffer.WriteString(" : ")
 	_, _ = buffer.WriteString(message)
@@ -166,6 +205,12 @@ func (f *fileAnnotation) toExternalFileAnnotation() externalFileAnnotation {
 	if f.fileInfo != nil {
 		path = f.fileInfo.ExternalPath()
 	}
+	// errors are omitted, so that the output for errors is unchanged
+	// from before severities were introduced
+	severity := ""
+	if f.severity != SeverityError {
+		severity = f.severity.String()
+	}
 	return externalFileAnnotation{
 		Path:        path,
 		StartLine:   f.startLine,
@@ -174,6 +219,7 @@ func (f *fileAnnotation) toExternalFileAnnotation() externalFileAnnotation {
 		EndColumn:   f.endColumn,
 		Type:        f.typeString,
 		Message:     f.message,
+		Severity:    severity,
 	}
 }
 
@@ -185,4 +231,5 @@ type externalFileAnnotation struct {
 	EndColumn   int    `json:"end_column,omitempty" yaml:"end_column,omitempty"`
 	Type        string `json:"type,omitempty" yaml:"type,omitempty"`
 	Message     string `json:"message,omitempty" yaml:"message,omitempty"`
+	Severity    string `json:"severity,omitempty" yaml:"severity,omitempty"`
 }
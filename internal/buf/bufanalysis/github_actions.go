@@ -0,0 +1,84 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"bytes"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	severityToGitHubActionsCommand = map[Severity]string{
+		SeverityError:   "error",
+		SeverityWarning: "warning",
+		SeverityInfo:    "notice",
+	}
+	// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts
+	githubActionsDataReplacer = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+	)
+	githubActionsPropertyReplacer = strings.NewReplacer(
+		"%", "%25",
+		"\r", "%0D",
+		"\n", "%0A",
+		":", "%3A",
+		",", "%2C",
+	)
+)
+
+// formatFileAnnotationGitHubActions formats the FileAnnotation as a GitHub
+// Actions error, warning, or notice workflow command.
+//
+// https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#setting-an-error-message
+func formatFileAnnotationGitHubActions(fileAnnotation FileAnnotation) string {
+	typeString := fileAnnotation.Type()
+	if typeString == "" {
+		// should never happen but just in case
+		typeString = "FAILURE"
+	}
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = typeString
+	}
+	var properties []string
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		properties = append(properties, "file="+githubActionsPropertyReplacer.Replace(filepath.ToSlash(fileInfo.ExternalPath())))
+		if fileAnnotation.StartLine() != 0 {
+			properties = append(properties, "line="+strconv.Itoa(fileAnnotation.StartLine()))
+			if fileAnnotation.EndLine() != 0 {
+				properties = append(properties, "endLine="+strconv.Itoa(fileAnnotation.EndLine()))
+			}
+			if fileAnnotation.StartColumn() != 0 {
+				properties = append(properties, "col="+strconv.Itoa(fileAnnotation.StartColumn()))
+				if fileAnnotation.EndColumn() != 0 {
+					properties = append(properties, "endColumn="+strconv.Itoa(fileAnnotation.EndColumn()))
+				}
+			}
+		}
+	}
+	properties = append(properties, "title="+githubActionsPropertyReplacer.Replace(typeString))
+	buffer := bytes.NewBuffer(nil)
+	_, _ = buffer.WriteString("::")
+	_, _ = buffer.WriteString(severityToGitHubActionsCommand[fileAnnotation.Severity()])
+	_, _ = buffer.WriteRune(' ')
+	_, _ = buffer.WriteString(strings.Join(properties, ","))
+	_, _ = buffer.WriteString("::")
+	_, _ = buffer.WriteString(githubActionsDataReplacer.Replace(message))
+	return buffer.String()
+}
@@ -0,0 +1,136 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// the name of the testsuite for FileAnnotations without a FileInfo,
+// this matches the path printed by the text format
+const junitNoFileTestSuiteName = "<input>"
+
+func printFileAnnotationsJUnit(writer io.Writer, fileAnnotations []FileAnnotation) error {
+	data, err := xml.MarshalIndent(newJUnitTestSuites(fileAnnotations), "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte(xml.Header)); err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+// each file is a testsuite and each FileAnnotation is a testcase
+//
+// only FileAnnotations with SeverityError are failing testcases, the text of
+// other FileAnnotations is added as the output of the testcase.
+//
+// testsuites are in the order that their files are first seen, so if the
+// FileAnnotations are sorted, so are the testsuites.
+func newJUnitTestSuites(fileAnnotations []FileAnnotation) *junitTestSuites {
+	testSuites := &junitTestSuites{}
+	nameToTestSuite := make(map[string]*junitTestSuite)
+	for _, fileAnnotation := range fileAnnotations {
+		name := junitNoFileTestSuiteName
+		if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+			name = fileInfo.ExternalPath()
+		}
+		testSuite, ok := nameToTestSuite[name]
+		if !ok {
+			testSuite = &junitTestSuite{
+				Name: name,
+			}
+			nameToTestSuite[name] = testSuite
+			testSuites.TestSuites = append(testSuites.TestSuites, testSuite)
+		}
+		testSuite.TestCases = append(testSuite.TestCases, newJUnitTestCase(name, fileAnnotation))
+		testSuite.Tests++
+		testSuites.Tests++
+		if fileAnnotation.Severity() == SeverityError {
+			testSuite.Failures++
+			testSuites.Failures++
+		}
+	}
+	return testSuites
+}
+
+func newJUnitTestCase(testSuiteName string, fileAnnotation FileAnnotation) *junitTestCase {
+	typeString := fileAnnotation.Type()
+	if typeString == "" {
+		// should never happen but just in case
+		typeString = "FAILURE"
+	}
+	// testcase names should be unique within a testsuite, so we add the location
+	name := typeString
+	if fileAnnotation.StartLine() != 0 {
+		name += "_" + strconv.Itoa(fileAnnotation.StartLine())
+		if fileAnnotation.StartColumn() != 0 {
+			name += "_" + strconv.Itoa(fileAnnotation.StartColumn())
+		}
+	}
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = typeString
+	}
+	testCase := &junitTestCase{
+		Name:      name,
+		ClassName: testSuiteName,
+	}
+	if fileAnnotation.Severity() != SeverityError {
+		testCase.SystemOut = fileAnnotation.String()
+		return testCase
+	}
+	testCase.Failure = &junitFailure{
+		Message: message,
+		Type:    typeString,
+		Text:    fileAnnotation.String(),
+	}
+	return testCase
+}
+
+// The types below are the subset of the JUnit XML format that is
+// commonly understood by CI systems.
+
+type junitTestSuites struct {
+	XMLName    xml.Name          `xml:"testsuites"`
+	Tests      int               `xml:"tests,attr"`
+	Failures   int               `xml:"failures,attr"`
+	TestSuites []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
@@ -0,0 +1,220 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufanalysis
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	sarifSchema             = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion            = "2.1.0"
+	sarifToolName           = "buf"
+	sarifToolInformationURI = "https://github.com/bufbuild/buf"
+)
+
+var severityToSARIFLevel = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityInfo:    "note",
+}
+
+func printFileAnnotationsSARIF(
+	writer io.Writer,
+	fileAnnotations []FileAnnotation,
+	rules []Rule,
+) error {
+	data, err := json.MarshalIndent(newSARIFLog(fileAnnotations, rules), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
+func newSARIFLog(fileAnnotations []FileAnnotation, rules []Rule) *sarifLog {
+	idToRule := make(map[string]Rule, len(rules))
+	for _, rule := range rules {
+		idToRule[rule.ID()] = rule
+	}
+	// only the rules referenced by a result are included, sorted by ID
+	ruleIDToIndex := make(map[string]int)
+	var ruleIDs []string
+	for _, fileAnnotation := range fileAnnotations {
+		if ruleID := fileAnnotation.Type(); ruleID != "" {
+			if _, ok := ruleIDToIndex[ruleID]; !ok {
+				ruleIDToIndex[ruleID] = 0
+				ruleIDs = append(ruleIDs, ruleID)
+			}
+		}
+	}
+	sort.Strings(ruleIDs)
+	reportingDescriptors := make([]*sarifReportingDescriptor, len(ruleIDs))
+	for i, ruleID := range ruleIDs {
+		ruleIDToIndex[ruleID] = i
+		reportingDescriptors[i] = newSARIFReportingDescriptor(ruleID, idToRule[ruleID])
+	}
+	results := make([]*sarifResult, len(fileAnnotations))
+	for i, fileAnnotation := range fileAnnotations {
+		results[i] = newSARIFResult(fileAnnotation, ruleIDToIndex)
+	}
+	return &sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []*sarifRun{
+			{
+				Tool: &sarifTool{
+					Driver: &sarifToolComponent{
+						Name:           sarifToolName,
+						InformationURI: sarifToolInformationURI,
+						Rules:          reportingDescriptors,
+					},
+				},
+				Results: results,
+			},
+		},
+	}
+}
+
+// rule may be nil, for example for compilation errors.
+func newSARIFReportingDescriptor(ruleID string, rule Rule) *sarifReportingDescriptor {
+	reportingDescriptor := &sarifReportingDescriptor{
+		ID: ruleID,
+	}
+	if rule != nil {
+		reportingDescriptor.ShortDescription = &sarifMessage{
+			Text: rule.Purpose(),
+		}
+		reportingDescriptor.Properties = &sarifRuleProperties{
+			Categories: rule.Categories(),
+		}
+	}
+	return reportingDescriptor
+}
+
+func newSARIFResult(fileAnnotation FileAnnotation, ruleIDToIndex map[string]int) *sarifResult {
+	message := fileAnnotation.Message()
+	if message == "" {
+		message = fileAnnotation.Type()
+		// should never happen but just in case
+		if message == "" {
+			message = "FAILURE"
+		}
+	}
+	result := &sarifResult{
+		Level: severityToSARIFLevel[fileAnnotation.Severity()],
+		Message: &sarifMessage{
+			Text: message,
+		},
+	}
+	if ruleID := fileAnnotation.Type(); ruleID != "" {
+		ruleIndex := ruleIDToIndex[ruleID]
+		result.RuleID = ruleID
+		result.RuleIndex = &ruleIndex
+	}
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		physicalLocation := &sarifPhysicalLocation{
+			ArtifactLocation: &sarifArtifactLocation{
+				URI: filepath.ToSlash(fileInfo.ExternalPath()),
+			},
+		}
+		// lines and columns are 1-indexed in both buf and sarif, and the end
+		// column is exclusive in both, so we can use these values as-is
+		if fileAnnotation.StartLine() > 0 {
+			physicalLocation.Region = &sarifRegion{
+				StartLine:   fileAnnotation.StartLine(),
+				StartColumn: fileAnnotation.StartColumn(),
+				EndLine:     fileAnnotation.EndLine(),
+				EndColumn:   fileAnnotation.EndColumn(),
+			}
+		}
+		result.Locations = []*sarifLocation{
+			{
+				PhysicalLocation: physicalLocation,
+			},
+		}
+	}
+	return result
+}
+
+// The types below are the subset of the SARIF 2.1.0 object model that we use.
+//
+// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+
+type sarifLog struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifToolComponent `json:"driver"`
+}
+
+type sarifToolComponent struct {
+	Name           string                      `json:"name"`
+	InformationURI string                      `json:"informationUri,omitempty"`
+	Rules          []*sarifReportingDescriptor `json:"rules,omitempty"`
+}
+
+type sarifReportingDescriptor struct {
+	ID               string               `json:"id"`
+	ShortDescription *sarifMessage        `json:"shortDescription,omitempty"`
+	Properties       *sarifRuleProperties `json:"properties,omitempty"`
+}
+
+type sarifRuleProperties struct {
+	Categories []string `json:"categories,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId,omitempty"`
+	RuleIndex *int             `json:"ruleIndex,omitempty"`
+	Level     string           `json:"level"`
+	Message   *sarifMessage    `json:"message"`
+	Locations []*sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
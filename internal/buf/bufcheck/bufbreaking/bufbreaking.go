@@ -19,6 +19,8 @@ package bufbreaking
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck"
@@ -42,6 +44,26 @@ type Handler interface {
 		previousImage bufimage.Image,
 		image bufimage.Image,
 	) ([]bufanalysis.FileAnnotation, error)
+	// Diff returns the changes between the previousImage and the image.
+	//
+	// Each Change is breaking if it is detected by a rule of the config that
+	// is not ignored for the Change and has bufanalysis.SeverityError.
+	//
+	// Failures of rules that are not known to detect any of the Changes, such
+	// as plugin rules, are attributed to the innermost Change that contains
+	// their location. If there is no such Change, a "changed" Change of kind
+	// "file" is returned for the failure.
+	//
+	// The image should have source code info for this to work properly. The previousImage
+	// does not need to have source code info.
+	//
+	// Images should be filtered with regards to imports before passing to this function.
+	Diff(
+		ctx context.Context,
+		config *Config,
+		previousImage bufimage.Image,
+		image bufimage.Image,
+	) ([]Change, error)
 }
 
 // NewHandler returns a new Handler.
@@ -65,7 +87,18 @@ type Config struct {
 	Rules                  []Rule
 	IgnoreIDToRootPaths    map[string]map[string]struct{}
 	IgnoreRootPaths        map[string]struct{}
+	IDToSeverity           map[string]bufanalysis.Severity
 	IgnoreUnstablePackages bool
+	ComparedOptions        []string
+
+	// pluginConfigBuilder is set if the config has plugins, whose rules are
+	// only added by LoadPlugins.
+	pluginConfigBuilder *internal.ConfigBuilder
+}
+
+// HasPlugins returns true if the config has plugins.
+func (c *Config) HasPlugins() bool {
+	return c.pluginConfigBuilder != nil
 }
 
 // GetRules returns the rules.
@@ -77,15 +110,36 @@ func (c *Config) GetRules() []bufcheck.Rule {
 
 // NewConfigV1Beta1 returns a new Config.
 func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) (*Config, error) {
-	internalConfig, err := internal.ConfigBuilder{
+	configBuilder := internal.ConfigBuilder{
 		Use:                           externalConfig.Use,
 		Except:                        externalConfig.Except,
 		IgnoreRootPaths:               externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths: externalConfig.IgnoreOnly,
+		IDOrCategoryToSeverity:        externalConfig.Severity,
 		IgnoreUnstablePackages:        externalConfig.IgnoreUnstablePackages,
-	}.NewConfig(
-		bufbreakingv1beta1.VersionSpec,
-	)
+		ComparedOptions:               externalConfig.ComparedOptions,
+		Plugins:                       internal.ExternalPluginsV1Beta1ToPlugins(externalConfig.Plugins),
+	}
+	internalConfig, err := configBuilder.NewConfig(bufbreakingv1beta1.VersionSpec)
+	if err != nil {
+		return nil, err
+	}
+	config := internalConfigToConfig(internalConfig)
+	if len(configBuilder.Plugins) > 0 {
+		config.pluginConfigBuilder = &configBuilder
+	}
+	return config, nil
+}
+
+// LoadPlugins returns the Config with the rules of its plugins.
+//
+// Listing the rules of the plugins runs them, so this is only done when
+// checking or listing rules. The Config is returned if it has no plugins.
+func LoadPlugins(ctx context.Context, config *Config) (*Config, error) {
+	if config.pluginConfigBuilder == nil {
+		return config, nil
+	}
+	internalConfig, err := config.pluginConfigBuilder.NewConfigWithPlugins(ctx, bufbreakingv1beta1.VersionSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +161,78 @@ func GetAllRulesV1Beta1() ([]bufcheck.Rule, error) {
 	return rulesToBufcheckRules(config.Rules), nil
 }
 
+// PrintFileAnnotations prints the FileAnnotations to the Writer.
+//
+// The metadata of all known rules is made available to formats that print it.
+func PrintFileAnnotations(
+	writer io.Writer,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	formatString string,
+) error {
+	config, err := NewConfigV1Beta1(
+		ExternalConfigV1Beta1{
+			Use: bufbreakingv1beta1.VersionSpec.AllCategories,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	return bufanalysis.PrintFileAnnotations(
+		writer,
+		fileAnnotations,
+		formatString,
+		bufanalysis.PrintFileAnnotationsWithRules(rulesToBufanalysisRules(config.Rules)),
+	)
+}
+
+// Change is a change between two images.
+type Change struct {
+	// Type is "added", "removed", or "changed".
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	// Kind is the kind of the changed element, such as "message", "field",
+	// or "option".
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Name is the path for files, and the full name otherwise. For options,
+	// the full name of the option in parentheses follows the full name of
+	// the element.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Path is the external path of the file of the changed element.
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// The location of the change in the image.
+	//
+	// Not set for removed elements.
+	StartLine   int    `json:"start_line,omitempty" yaml:"start_line,omitempty"`
+	StartColumn int    `json:"start_column,omitempty" yaml:"start_column,omitempty"`
+	EndLine     int    `json:"end_line,omitempty" yaml:"end_line,omitempty"`
+	EndColumn   int    `json:"end_column,omitempty" yaml:"end_column,omitempty"`
+	Message     string `json:"message,omitempty" yaml:"message,omitempty"`
+	// Compatibility is "breaking" if RuleIDs is not empty, and "compatible" otherwise.
+	Compatibility string `json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+	// RuleIDs are the ids of the rules of the config that detect the change.
+	RuleIDs []string `json:"rule_ids,omitempty" yaml:"rule_ids,omitempty"`
+}
+
+// PrintChanges prints the Changes to the Writer as a single JSON document.
+func PrintChanges(writer io.Writer, changes []Change) error {
+	if changes == nil {
+		changes = []Change{}
+	}
+	data, err := json.MarshalIndent(
+		struct {
+			Changes []Change `json:"changes"`
+		}{
+			Changes: changes,
+		},
+		"",
+		"  ",
+	)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
+
 // ExternalConfigV1Beta1 is an external config.
 type ExternalConfigV1Beta1 struct {
 	Use    []string `json:"use,omitempty" yaml:"use,omitempty"`
@@ -114,16 +240,27 @@ type ExternalConfigV1Beta1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly             map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	IgnoreUnstablePackages bool                `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	IgnoreOnly map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	// IDOrCategoryToSeverity
+	Severity               map[string]string `json:"severity,omitempty" yaml:"severity,omitempty"`
+	IgnoreUnstablePackages bool              `json:"ignore_unstable_packages,omitempty" yaml:"ignore_unstable_packages,omitempty"`
+	// ComparedOptions are the full names of the custom options compared by
+	// the OPTION_SAME_VALUE rule, such as "google.api.http".
+	ComparedOptions []string                `json:"compared_options,omitempty" yaml:"compared_options,omitempty"`
+	Plugins         []ExternalPluginV1Beta1 `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// ExternalPluginV1Beta1 is an external plugin.
+type ExternalPluginV1Beta1 = internal.ExternalPluginV1Beta1
+
 func internalConfigToConfig(internalConfig *internal.Config) *Config {
 	return &Config{
 		Rules:                  internalRulesToRules(internalConfig.Rules),
 		IgnoreIDToRootPaths:    internalConfig.IgnoreIDToRootPaths,
 		IgnoreRootPaths:        internalConfig.IgnoreRootPaths,
+		IDToSeverity:           internalConfig.IDToSeverity,
 		IgnoreUnstablePackages: internalConfig.IgnoreUnstablePackages,
+		ComparedOptions:        internalConfig.ComparedOptions,
 	}
 }
 
@@ -132,7 +269,9 @@ func configToInternalConfig(config *Config) *internal.Config {
 		Rules:                  rulesToInternalRules(config.Rules),
 		IgnoreIDToRootPaths:    config.IgnoreIDToRootPaths,
 		IgnoreRootPaths:        config.IgnoreRootPaths,
+		IDToSeverity:           config.IDToSeverity,
 		IgnoreUnstablePackages: config.IgnoreUnstablePackages,
+		ComparedOptions:        config.ComparedOptions,
 	}
 }
 
@@ -146,3 +285,14 @@ func rulesToBufcheckRules(rules []Rule) []bufcheck.Rule {
 	}
 	return s
 }
+
+func rulesToBufanalysisRules(rules []Rule) []bufanalysis.Rule {
+	if rules == nil {
+		return nil
+	}
+	s := make([]bufanalysis.Rule, len(rules))
+	for i, e := range rules {
+		s[i] = e
+	}
+	return s
+}
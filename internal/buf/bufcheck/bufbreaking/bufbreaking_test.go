@@ -16,18 +16,27 @@ package bufbreaking_test
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufanalysis/bufanalysistesting"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/internal/buf/bufconfig"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimagebuild"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufmodule"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufmodule/bufmodulebuild"
+	checkv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/check/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/protoencoding"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"github.com/bufbuild/buf/internal/pkg/storage"
 	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
@@ -35,6 +44,20 @@ import (
 	"go.uber.org/zap"
 )
 
+const testPluginEnvKey = "BUF_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	// the test binary is used as the plugin for TestDiffPlugin
+	if os.Getenv(testPluginEnvKey) != "" {
+		if err := testRunPlugin(os.Stdin, os.Stdout); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
 func TestRunBreakingEnumNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -113,6 +136,67 @@ func TestRunBreakingExtensionMessageNoDelete(t *testing.T) {
 	)
 }
 
+func TestRunBreakingExtensionNoDelete(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_extension_no_delete",
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "1.proto", "EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "1.proto", "EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 19, 1, 24, 2, "EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 23, 3, 23, 19, "EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "2.proto", "EXTENSION_NO_DELETE"),
+	)
+}
+
+func TestRunBreakingExtensionSameExtendee(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_extension_same_extendee",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 27, 8, 27, 38, "EXTENSION_SAME_EXTENDEE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 38, 8, 38, 11, "EXTENSION_SAME_EXTENDEE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 43, 10, 43, 13, "EXTENSION_SAME_EXTENDEE"),
+	)
+}
+
+func TestRunBreakingExtensionSameNumber(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_extension_same_number",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 32, 22, 32, 25, "EXTENSION_SAME_NUMBER"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 44, 24, 44, 27, "EXTENSION_SAME_NUMBER"),
+	)
+}
+
+func TestRunBreakingExtensionSameType(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_extension_same_type",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 33, 12, 33, 17, "EXTENSION_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 34, 12, 34, 15, "EXTENSION_SAME_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 35, 12, 35, 15, "EXTENSION_SAME_TYPE"),
+	)
+}
+
+func TestRunBreakingExtensionWireCompatibleType(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_extension_wire_compatible_type",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 18, 12, 18, 18, "EXTENSION_WIRE_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 20, 12, 20, 18, "EXTENSION_WIRE_COMPATIBLE_TYPE"),
+	)
+}
+
+func TestRunBreakingExtensionWireJSONCompatibleType(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_extension_wire_json_compatible_type",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 18, 12, 18, 18, "EXTENSION_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 19, 12, 19, 17, "EXTENSION_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 20, 12, 20, 18, "EXTENSION_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 21, 12, 21, 15, "EXTENSION_WIRE_JSON_COMPATIBLE_TYPE"),
+	)
+}
+
 func TestRunBreakingFieldNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -165,6 +249,18 @@ func TestRunBreakingFieldSameCType(t *testing.T) {
 	)
 }
 
+func TestRunBreakingFieldSameDefault(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_same_default",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 12, 25, 12, 36, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 14, 26, 14, 41, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 16, 23, 16, 40, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 17, 3, 17, 23, "FIELD_SAME_DEFAULT"),
+		bufanalysistesting.NewFileAnnotation(t, "2.proto", 6, 25, 6, 36, "FIELD_SAME_DEFAULT"),
+	)
+}
+
 func TestRunBreakingFieldSameJSONName(t *testing.T) {
 	testBreaking(
 		t,
@@ -275,6 +371,28 @@ func TestRunBreakingFieldSameOneof(t *testing.T) {
 	)
 }
 
+func TestRunBreakingFieldSamePacked(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_same_packed",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 25, 6, 38, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 10, 25, 10, 39, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "2.proto", 7, 25, 7, 39, "FIELD_SAME_PACKED"),
+		bufanalysistesting.NewFileAnnotation(t, "3.proto", 6, 3, 6, 24, "FIELD_SAME_PACKED"),
+	)
+}
+
+func TestRunBreakingFieldSamePresence(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_same_presence",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 6, 3, 6, 24, "FIELD_SAME_PRESENCE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 7, 3, 7, 15, "FIELD_SAME_PRESENCE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 10, 5, 10, 17, "FIELD_SAME_PRESENCE"),
+		bufanalysistesting.NewFileAnnotation(t, "2.proto", 6, 3, 6, 15, "FIELD_SAME_PRESENCE"),
+	)
+}
+
 func TestRunBreakingFieldSameType(t *testing.T) {
 	// TODO: double check all this
 	testBreaking(
@@ -300,6 +418,38 @@ func TestRunBreakingFieldSameType(t *testing.T) {
 	)
 }
 
+func TestRunBreakingFieldWireCompatibleType(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_wire_compatible_type",
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 26, 3, 26, 9, "FIELD_WIRE_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 28, 3, 28, 8, "FIELD_WIRE_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 31, 3, 31, 6, "FIELD_WIRE_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 32, 3, 32, 6, "FIELD_WIRE_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 37, 3, 37, 10, "FIELD_WIRE_COMPATIBLE_TYPE"),
+	)
+}
+
+func TestRunBreakingFieldWireJSONCompatibleType(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_field_wire_json_compatible_type",
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "1.proto", "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 22, 3, 22, 8, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 24, 3, 24, 7, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 25, 3, 25, 9, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 26, 3, 26, 9, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 28, 3, 28, 8, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 29, 3, 29, 8, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 30, 3, 30, 8, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 31, 3, 31, 6, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 32, 3, 32, 6, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 33, 3, 33, 6, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 34, 3, 34, 8, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 37, 3, 37, 10, "FIELD_WIRE_JSON_COMPATIBLE_TYPE"),
+	)
+}
+
 func TestRunBreakingFileNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -442,6 +592,406 @@ func TestRunBreakingOneofNoDelete(t *testing.T) {
 	)
 }
 
+func TestRunBreakingOptionSameValue(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_option_same_value",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 7, 1, 7, 33, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 13, 20, 13, 62, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 18, 14, 56, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 15, 20, 15, 49, "OPTION_SAME_VALUE"),
+		// the option was removed, so the location of the field is used
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 3, 16, 20, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 20, 3, 20, 34, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 25, 22, 25, 52, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 29, 3, 29, 41, "OPTION_SAME_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 32, 5, 32, 45, "OPTION_SAME_VALUE"),
+	)
+}
+
+func TestRunBreakingOptionSameValueUnknownComparedOption(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	logger := zap.NewNop()
+
+	_, previousImage, image := testGetConfigAndImages(ctx, t, logger, "breaking_option_same_value")
+	// options that are not defined in either image are likely typos
+	config, err := bufbreaking.NewConfigV1Beta1(
+		bufbreaking.ExternalConfigV1Beta1{
+			Use:             []string{"OPTION_SAME_VALUE"},
+			ComparedOptions: []string{"acme.owner", "acme.unknown"},
+		},
+	)
+	require.NoError(t, err)
+	handler := bufbreaking.NewHandler(logger)
+	_, err = handler.Check(ctx, config, previousImage, image)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"acme.unknown"`)
+	_, err = handler.Diff(ctx, config, previousImage, image)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"acme.unknown"`)
+}
+
+func TestDiffChangeset(t *testing.T) {
+	testDiff(
+		t,
+		"breaking_changeset",
+		bufbreaking.Change{
+			Type:          "removed",
+			Kind:          "field",
+			Name:          "a.One.three",
+			Path:          "testdata/breaking_changeset/a.proto",
+			Message:       `Previously present field "3" with name "three" on message "a.One" was deleted.`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"FIELD_NO_DELETE"},
+		},
+		bufbreaking.Change{
+			Type:          "removed",
+			Kind:          "message",
+			Name:          "a.Two",
+			Path:          "testdata/breaking_changeset/a.proto",
+			Message:       `Previously present message "a.Two" was deleted.`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"MESSAGE_NO_DELETE"},
+		},
+		bufbreaking.Change{
+			Type:          "removed",
+			Kind:          "rpc",
+			Name:          "a.Service.List",
+			Path:          "testdata/breaking_changeset/a.proto",
+			Message:       `Previously present RPC "List" on service "a.Service" was deleted.`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"RPC_NO_DELETE"},
+		},
+		bufbreaking.Change{
+			Type:          "changed",
+			Kind:          "file",
+			Name:          "a.proto",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     5,
+			StartColumn:   1,
+			EndLine:       5,
+			EndColumn:     28,
+			Message:       `File option "go_package" changed from "a/v1" to "a/v2".`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"FILE_SAME_GO_PACKAGE"},
+		},
+		bufbreaking.Change{
+			Type:          "changed",
+			Kind:          "field",
+			Name:          "a.One.two",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     13,
+			StartColumn:   3,
+			EndLine:       13,
+			EndColumn:     8,
+			Message:       `Field "2" on message "One" changed type from "int32" to "int64".`,
+			Compatibility: "compatible",
+		},
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "field",
+			Name:          "a.One.five",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     17,
+			StartColumn:   3,
+			EndLine:       17,
+			EndColumn:     19,
+			Message:       `Field "5" with name "five" was added to message "a.One".`,
+			Compatibility: "compatible",
+		},
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "message",
+			Name:          "a.Three",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     20,
+			StartColumn:   1,
+			EndLine:       25,
+			EndColumn:     2,
+			Message:       `Message "a.Three" was added.`,
+			Compatibility: "compatible",
+		},
+		bufbreaking.Change{
+			Type:          "changed",
+			Kind:          "enum_value",
+			Name:          "a.Enum.ENUM_TWO",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     30,
+			StartColumn:   14,
+			EndLine:       30,
+			EndColumn:     15,
+			Message:       `Enum value "2" on enum "Enum" changed name from "ENUM_TWO" to "ENUM_DOS".`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"ENUM_VALUE_SAME_NAME"},
+		},
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "enum_value",
+			Name:          "a.Enum.ENUM_THREE",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     31,
+			StartColumn:   3,
+			EndLine:       31,
+			EndColumn:     18,
+			Message:       `Enum value "3" with name "ENUM_THREE" was added to enum "a.Enum".`,
+			Compatibility: "compatible",
+		},
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "rpc",
+			Name:          "a.Service.Put",
+			Path:          "testdata/breaking_changeset/a.proto",
+			StartLine:     36,
+			StartColumn:   3,
+			EndLine:       36,
+			EndColumn:     37,
+			Message:       `RPC "Put" was added to service "a.Service".`,
+			Compatibility: "compatible",
+		},
+		bufbreaking.Change{
+			Type:          "removed",
+			Kind:          "file",
+			Name:          "b.proto",
+			Path:          "testdata_previous/breaking_changeset/b.proto",
+			Message:       `Previously present file "b.proto" was deleted.`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"FILE_NO_DELETE"},
+		},
+		bufbreaking.Change{
+			Type:          "removed",
+			Kind:          "message",
+			Name:          "b.Four",
+			Path:          "testdata_previous/breaking_changeset/b.proto",
+			Message:       `Previously present message "b.Four" was deleted.`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"FILE_NO_DELETE"},
+		},
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "file",
+			Name:          "c.proto",
+			Path:          "testdata/breaking_changeset/c.proto",
+			Message:       `File "c.proto" was added.`,
+			Compatibility: "compatible",
+		},
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "message",
+			Name:          "c.Five",
+			Path:          "testdata/breaking_changeset/c.proto",
+			StartLine:     5,
+			StartColumn:   1,
+			EndLine:       7,
+			EndColumn:     2,
+			Message:       `Message "c.Five" was added.`,
+			Compatibility: "compatible",
+		},
+	)
+}
+
+func TestDiffChangesetMap(t *testing.T) {
+	testDiff(
+		t,
+		"breaking_changeset_map",
+		bufbreaking.Change{
+			Type:          "changed",
+			Kind:          "field",
+			Name:          "a.One.one",
+			Path:          "testdata/breaking_changeset_map/a.proto",
+			StartLine:     6,
+			StartColumn:   3,
+			EndLine:       6,
+			EndColumn:     31,
+			Message:       `Field "2" on message "OneEntry" changed type from "int32" to "string".`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"FIELD_SAME_TYPE"},
+		},
+		bufbreaking.Change{
+			Type:          "changed",
+			Kind:          "field",
+			Name:          "a.One.two",
+			Path:          "testdata/breaking_changeset_map/a.proto",
+			StartLine:     7,
+			StartColumn:   3,
+			EndLine:       7,
+			EndColumn:     30,
+			Message:       `Field "1" on message "TwoEntry" changed type from "string" to "int64".`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"FIELD_SAME_TYPE"},
+		},
+	)
+}
+
+func TestDiffPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	testDiffConfigModifier(
+		t,
+		"breaking_changeset_plugin",
+		func(config *bufconfig.Config) {
+			pluginPath := filepath.Join(t.TempDir(), "buf-plugin-test")
+			require.NoError(
+				t,
+				os.WriteFile(
+					pluginPath,
+					[]byte(fmt.Sprintf("#!/bin/sh\n%s=1 exec %q\n", testPluginEnvKey, os.Args[0])),
+					0700,
+				),
+			)
+			breakingConfig, err := bufbreaking.NewConfigV1Beta1(
+				bufbreaking.ExternalConfigV1Beta1{
+					Use: []string{"FILE", "TEST"},
+					Plugins: []bufbreaking.ExternalPluginV1Beta1{
+						{
+							Name: "test",
+							Path: pluginPath,
+						},
+					},
+				},
+			)
+			require.NoError(t, err)
+			config.Breaking = breakingConfig
+		},
+		// the plugin rule is not known to the changes, and is attributed
+		// to the change that contains its failure
+		bufbreaking.Change{
+			Type:          "added",
+			Kind:          "message",
+			Name:          "a.Two",
+			Path:          "testdata/breaking_changeset_plugin/a.proto",
+			StartLine:     7,
+			StartColumn:   1,
+			EndLine:       7,
+			EndColumn:     15,
+			Message:       `Message "a.Two" was added.`,
+			Compatibility: "breaking",
+			RuleIDs:       []string{"TEST_MESSAGE_NO_ADD"},
+		},
+	)
+}
+
+// TestGetChangesAllRules checks that the changes account for the failures of
+// every rule, using the testdata of the rules, so that a rule that is not
+// mapped to the changes fails this test.
+func TestGetChangesAllRules(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	logger := zap.NewNop()
+
+	rules, err := bufbreaking.GetAllRulesV1Beta1()
+	require.NoError(t, err)
+	uncoveredIDs := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		uncoveredIDs[rule.ID()] = struct{}{}
+	}
+	dirEntries, err := os.ReadDir("testdata")
+	require.NoError(t, err)
+	for _, dirEntry := range dirEntries {
+		relDirPath := dirEntry.Name()
+		config, previousImage, image := testGetConfigAndImages(ctx, t, logger, relDirPath)
+		fileAnnotations, err := bufbreaking.NewHandler(logger).Check(ctx, config.Breaking, previousImage, image)
+		require.NoError(t, err)
+		previousFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
+		require.NoError(t, err)
+		files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+		require.NoError(t, err)
+		changes, err := bufbreakingcheck.GetChanges(previousFiles, files, config.Breaking.ComparedOptions)
+		require.NoError(t, err)
+		for _, fileAnnotation := range fileAnnotations {
+			assert.True(
+				t,
+				testChangesContainFileAnnotation(changes, fileAnnotation),
+				"%s: no change for %s",
+				relDirPath,
+				fileAnnotation.String(),
+			)
+			delete(uncoveredIDs, fileAnnotation.Type())
+		}
+	}
+	assert.Empty(t, uncoveredIDs)
+}
+
+// testChangesContainFileAnnotation returns true if a change with the rule of
+// the FileAnnotation is in the file of the FileAnnotation and its location
+// overlaps the location of the FileAnnotation, in the same way as Diff matches
+// the changes to the FileAnnotations.
+func testChangesContainFileAnnotation(changes []*bufbreakingcheck.Change, fileAnnotation bufanalysis.FileAnnotation) bool {
+	for _, change := range changes {
+		var hasRuleID bool
+		for _, ruleID := range change.RuleIDs {
+			if ruleID == fileAnnotation.Type() {
+				hasRuleID = true
+				break
+			}
+		}
+		if !hasRuleID {
+			continue
+		}
+		fileInfo := fileAnnotation.FileInfo()
+		if fileInfo == nil {
+			return true
+		}
+		descriptor := change.Descriptor
+		location := change.Location
+		if change.ReportDescriptor != nil {
+			descriptor = change.ReportDescriptor
+			location = change.ReportLocation
+		}
+		if descriptor == nil || descriptor.File().Path() != fileInfo.Path() {
+			continue
+		}
+		if location == nil || fileAnnotation.StartLine() == 0 {
+			return true
+		}
+		if testLocationContains(
+			location.StartLine(),
+			location.StartColumn(),
+			location.EndLine(),
+			location.EndColumn(),
+			fileAnnotation.StartLine(),
+			fileAnnotation.StartColumn(),
+		) || testLocationContains(
+			fileAnnotation.StartLine(),
+			fileAnnotation.StartColumn(),
+			fileAnnotation.EndLine(),
+			fileAnnotation.EndColumn(),
+			location.StartLine(),
+			location.StartColumn(),
+		) {
+			return true
+		}
+	}
+	return false
+}
+
+func testLocationContains(startLine int, startColumn int, endLine int, endColumn int, line int, column int) bool {
+	if line < startLine || line > endLine {
+		return false
+	}
+	if line == startLine && column < startColumn {
+		return false
+	}
+	if line == endLine && column > endColumn {
+		return false
+	}
+	return true
+}
+
+func TestRunBreakingPackageExtensionNoDelete(t *testing.T) {
+	testBreaking(
+		t,
+		"breaking_package_extension_no_delete",
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "1.proto", "PACKAGE_EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "1.proto", "PACKAGE_EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 19, 1, 24, 2, "PACKAGE_EXTENSION_NO_DELETE"),
+		bufanalysistesting.NewFileAnnotation(t, "1.proto", 23, 3, 23, 19, "PACKAGE_EXTENSION_NO_DELETE"),
+	)
+}
+
 func TestRunBreakingPackageNoDelete(t *testing.T) {
 	testBreaking(
 		t,
@@ -605,6 +1155,69 @@ func testBreaking(
 	defer cancel()
 	logger := zap.NewNop()
 
+	config, previousImage, image := testGetConfigAndImages(ctx, t, logger, relDirPath)
+	handler := bufbreaking.NewHandler(logger)
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
+
+func testDiff(
+	t *testing.T,
+	relDirPath string,
+	expectedChanges ...bufbreaking.Change,
+) {
+	testDiffConfigModifier(
+		t,
+		relDirPath,
+		nil,
+		expectedChanges...,
+	)
+}
+
+func testDiffConfigModifier(
+	t *testing.T,
+	relDirPath string,
+	configModifier func(*bufconfig.Config),
+	expectedChanges ...bufbreaking.Change,
+) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	logger := zap.NewNop()
+
+	config, previousImage, image := testGetConfigAndImages(ctx, t, logger, relDirPath)
+	if configModifier != nil {
+		configModifier(config)
+	}
+	handler := bufbreaking.NewHandler(logger)
+	changes, err := handler.Diff(
+		ctx,
+		config.Breaking,
+		previousImage,
+		image,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedChanges, changes)
+}
+
+// testGetConfigAndImages returns the config of the current directory, and the
+// previous and current images, with source code info only for the current image.
+func testGetConfigAndImages(
+	ctx context.Context,
+	t *testing.T,
+	logger *zap.Logger,
+	relDirPath string,
+) (*bufconfig.Config, bufimage.Image, bufimage.Image) {
 	previousDirPath := filepath.Join("testdata_previous", relDirPath)
 	dirPath := filepath.Join("testdata", relDirPath)
 
@@ -668,20 +1281,7 @@ func testBreaking(
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
 	image = bufimage.ImageWithoutImports(image)
-
-	handler := bufbreaking.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Breaking,
-		previousImage,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return config, previousImage, image
 }
 
 func testGetConfig(
@@ -695,3 +1295,78 @@ func testGetConfig(
 	require.NoError(t, err)
 	return config
 }
+
+// testRunPlugin is a check plugin with a single rule that checks that no
+// top-level message is added.
+func testRunPlugin(stdin io.Reader, stdout io.Writer) error {
+	requestData, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	request := &checkv1alpha1.CheckRequest{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(requestData, request); err != nil {
+		return err
+	}
+	response := &checkv1alpha1.CheckResponse{}
+	if len(request.GetRuleIds()) == 0 {
+		response.Rules = []*checkv1alpha1.Rule{
+			{
+				Id:         "TEST_MESSAGE_NO_ADD",
+				Categories: []string{"TEST"},
+				Purpose:    "no top-level message is added",
+			},
+		}
+	} else {
+		againstImage, err := bufimage.NewImageForProto(request.GetAgainstImage())
+		if err != nil {
+			return err
+		}
+		previousFullNames := make(map[string]struct{})
+		for _, imageFile := range againstImage.Files() {
+			fileDescriptorProto := imageFile.Proto()
+			for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+				previousFullNames[fileDescriptorProto.GetPackage()+"."+descriptorProto.GetName()] = struct{}{}
+			}
+		}
+		image, err := bufimage.NewImageForProto(request.GetImage())
+		if err != nil {
+			return err
+		}
+		for _, imageFile := range image.Files() {
+			if imageFile.IsImport() {
+				continue
+			}
+			fileDescriptorProto := imageFile.Proto()
+			for _, location := range fileDescriptorProto.GetSourceCodeInfo().GetLocation() {
+				// 4 is message_type, 1 is name
+				path := location.GetPath()
+				if len(path) != 3 || path[0] != 4 || path[2] != 1 {
+					continue
+				}
+				fullName := fileDescriptorProto.GetPackage() + "." + fileDescriptorProto.GetMessageType()[path[1]].GetName()
+				if _, ok := previousFullNames[fullName]; ok {
+					continue
+				}
+				span := location.GetSpan()
+				response.FileAnnotations = append(
+					response.FileAnnotations,
+					&checkv1alpha1.FileAnnotation{
+						Path:        imageFile.Path(),
+						StartLine:   uint32(span[0]) + 1,
+						StartColumn: uint32(span[1]) + 1,
+						EndLine:     uint32(span[0]) + 1,
+						EndColumn:   uint32(span[len(span)-1]) + 1,
+						RuleId:      "TEST_MESSAGE_NO_ADD",
+						Message:     fmt.Sprintf("Message %q was added.", fullName),
+					},
+				)
+			}
+		}
+	}
+	responseData, err := protoencoding.NewWireMarshaler().Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(responseData)
+	return err
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
@@ -47,6 +48,23 @@ func (h *handler) Check(
 	previousImage bufimage.Image,
 	image bufimage.Image,
 ) ([]bufanalysis.FileAnnotation, error) {
+	config, err := LoadPlugins(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return h.runner.Check(ctx, configToInternalConfig(config), previousImage, image)
+}
+
+func (h *handler) Diff(
+	ctx context.Context,
+	config *Config,
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) ([]Change, error) {
+	config, err := LoadPlugins(ctx, config)
+	if err != nil {
+		return nil, err
+	}
 	previousFiles, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
 	if err != nil {
 		return nil, err
@@ -55,5 +73,233 @@ func (h *handler) Check(
 	if err != nil {
 		return nil, err
 	}
-	return h.runner.Check(ctx, configToInternalConfig(config), previousFiles, files)
+	checkChanges, err := bufbreakingcheck.GetChanges(previousFiles, files, config.ComparedOptions)
+	if err != nil {
+		return nil, err
+	}
+	internalConfig := configToInternalConfig(config)
+	configRuleIDs := make(map[string]struct{}, len(config.Rules))
+	for _, rule := range config.Rules {
+		configRuleIDs[rule.ID()] = struct{}{}
+	}
+	// removed elements are reported in the file of the image if the file still exists
+	pathToExternalPath := make(map[string]string, len(image.Files()))
+	for _, imageFile := range image.Files() {
+		pathToExternalPath[imageFile.Path()] = imageFile.ExternalPath()
+	}
+	// the failures of rules that the changes do not account for, such as
+	// plugin rules, are attributed to the changes by location
+	fileAnnotations, err := h.runner.Check(ctx, internalConfig, previousImage, image)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]Change, len(checkChanges))
+	// the changes at the locations the check functions report them at, which
+	// differ from the changes for removed and moved elements
+	reportedChanges := make([]Change, len(checkChanges))
+	for i, checkChange := range checkChanges {
+		var ruleIDs []string
+		for _, ruleID := range checkChange.RuleIDs {
+			if _, ok := configRuleIDs[ruleID]; !ok {
+				continue
+			}
+			if severity, ok := config.IDToSeverity[ruleID]; ok && severity != bufanalysis.SeverityError {
+				continue
+			}
+			if h.runner.IsIgnored(internalConfig, ruleID, checkChange.Descriptor) {
+				continue
+			}
+			ruleIDs = append(ruleIDs, ruleID)
+		}
+		changes[i] = checkChangeToChange(checkChange, ruleIDs, pathToExternalPath)
+		reportedChanges[i] = getReportedChange(changes[i], checkChange, pathToExternalPath)
+	}
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation.Severity() != bufanalysis.SeverityError {
+			continue
+		}
+		if changesContainFileAnnotation(reportedChanges, fileAnnotation) {
+			continue
+		}
+		changes = addFileAnnotationToChanges(changes, fileAnnotation)
+	}
+	return changes, nil
+}
+
+// addFileAnnotationToChanges adds the rule of the FileAnnotation to the
+// innermost change that contains the start of the FileAnnotation, or adds a
+// change to the file of the FileAnnotation if there is no such change.
+func addFileAnnotationToChanges(changes []Change, fileAnnotation bufanalysis.FileAnnotation) []Change {
+	var path string
+	var externalPath string
+	if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+		path = fileInfo.Path()
+		externalPath = fileInfo.ExternalPath()
+	}
+	index := -1
+	for i, change := range changes {
+		if change.Path != externalPath || !changeContains(change, fileAnnotation.StartLine(), fileAnnotation.StartColumn()) {
+			continue
+		}
+		if index < 0 || changeContains(changes[index], change.StartLine, change.StartColumn) {
+			index = i
+		}
+	}
+	if index < 0 {
+		return append(
+			changes,
+			Change{
+				Type:          bufbreakingcheck.ChangeTypeChanged,
+				Kind:          bufbreakingcheck.ChangeKindFile,
+				Name:          path,
+				Path:          externalPath,
+				StartLine:     fileAnnotation.StartLine(),
+				StartColumn:   fileAnnotation.StartColumn(),
+				EndLine:       fileAnnotation.EndLine(),
+				EndColumn:     fileAnnotation.EndColumn(),
+				Message:       fileAnnotation.Message(),
+				Compatibility: "breaking",
+				RuleIDs:       []string{fileAnnotation.Type()},
+			},
+		)
+	}
+	change := &changes[index]
+	change.Compatibility = "breaking"
+	for _, ruleID := range change.RuleIDs {
+		if ruleID == fileAnnotation.Type() {
+			return changes
+		}
+	}
+	change.RuleIDs = append(change.RuleIDs, fileAnnotation.Type())
+	return changes
+}
+
+// changesContainFileAnnotation returns true if a change with the rule of the
+// FileAnnotation is in the file of the FileAnnotation and its location
+// overlaps the location of the FileAnnotation.
+//
+// FileAnnotations without a file, such as for deleted files, are matched by
+// rule only, and FileAnnotations or changes without a location are matched by
+// rule and file only.
+func changesContainFileAnnotation(changes []Change, fileAnnotation bufanalysis.FileAnnotation) bool {
+	fileInfo := fileAnnotation.FileInfo()
+	for _, change := range changes {
+		if !stringSliceContains(change.RuleIDs, fileAnnotation.Type()) {
+			continue
+		}
+		if fileInfo == nil {
+			return true
+		}
+		if change.Path != fileInfo.ExternalPath() {
+			continue
+		}
+		if change.StartLine == 0 ||
+			fileAnnotation.StartLine() == 0 ||
+			changeContains(change, fileAnnotation.StartLine(), fileAnnotation.StartColumn()) ||
+			locationContains(
+				fileAnnotation.StartLine(),
+				fileAnnotation.StartColumn(),
+				fileAnnotation.EndLine(),
+				fileAnnotation.EndColumn(),
+				change.StartLine,
+				change.StartColumn,
+			) {
+			return true
+		}
+	}
+	return false
+}
+
+// changeContains returns true if the location of the change contains the
+// given line and column.
+func changeContains(change Change, line int, column int) bool {
+	return locationContains(change.StartLine, change.StartColumn, change.EndLine, change.EndColumn, line, column)
+}
+
+// locationContains returns true if the location with the given start and end
+// contains the given line and column.
+func locationContains(startLine int, startColumn int, endLine int, endColumn int, line int, column int) bool {
+	if startLine == 0 || line == 0 {
+		return false
+	}
+	if line < startLine || line > endLine {
+		return false
+	}
+	if line == startLine && column < startColumn {
+		return false
+	}
+	if line == endLine && column > endColumn {
+		return false
+	}
+	return true
+}
+
+func stringSliceContains(values []string, value string) bool {
+	for _, element := range values {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
+
+// getReportedChange returns the change at the location the check functions
+// report it at, such as the location of the current parent of a removed
+// element.
+func getReportedChange(
+	change Change,
+	checkChange *bufbreakingcheck.Change,
+	pathToExternalPath map[string]string,
+) Change {
+	if checkChange.ReportDescriptor == nil {
+		return change
+	}
+	fileInfo := checkChange.ReportDescriptor.File()
+	change.Path = fileInfo.ExternalPath()
+	if externalPath, ok := pathToExternalPath[fileInfo.Path()]; ok {
+		change.Path = externalPath
+	}
+	change.StartLine = 0
+	change.StartColumn = 0
+	change.EndLine = 0
+	change.EndColumn = 0
+	if location := checkChange.ReportLocation; location != nil {
+		change.StartLine = location.StartLine()
+		change.StartColumn = location.StartColumn()
+		change.EndLine = location.EndLine()
+		change.EndColumn = location.EndColumn()
+	}
+	return change
+}
+
+func checkChangeToChange(
+	checkChange *bufbreakingcheck.Change,
+	ruleIDs []string,
+	pathToExternalPath map[string]string,
+) Change {
+	change := Change{
+		Type:          checkChange.Type,
+		Kind:          checkChange.Kind,
+		Name:          checkChange.Name,
+		Message:       checkChange.Message,
+		Compatibility: "compatible",
+		RuleIDs:       ruleIDs,
+	}
+	if len(ruleIDs) > 0 {
+		change.Compatibility = "breaking"
+	}
+	if checkChange.Descriptor != nil {
+		fileInfo := checkChange.Descriptor.File()
+		change.Path = fileInfo.ExternalPath()
+		if externalPath, ok := pathToExternalPath[fileInfo.Path()]; ok {
+			change.Path = externalPath
+		}
+	}
+	if checkChange.Location != nil {
+		change.StartLine = checkChange.Location.StartLine()
+		change.StartColumn = checkChange.Location.StartColumn()
+		change.EndLine = checkChange.Location.EndLine()
+		change.EndColumn = checkChange.Location.EndColumn()
+	}
+	return change
 }
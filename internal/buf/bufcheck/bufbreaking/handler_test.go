@@ -0,0 +1,50 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreaking
+
+import (
+	"testing"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis/bufanalysistesting"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangesContainFileAnnotation(t *testing.T) {
+	t.Parallel()
+	changes := []Change{
+		{
+			Path:        "a.proto",
+			StartLine:   5,
+			StartColumn: 3,
+			EndLine:     5,
+			EndColumn:   20,
+			RuleIDs:     []string{"FIELD_SAME_TYPE"},
+		},
+		{
+			Path:    "a.proto",
+			RuleIDs: []string{"FIELD_NO_DELETE"},
+		},
+	}
+	assert.True(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 14, "FIELD_SAME_TYPE")))
+	assert.True(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "a.proto", 4, 1, 6, 2, "FIELD_SAME_TYPE")))
+	// the same rule at another location or in another file is another change
+	assert.False(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 3, 9, 20, "FIELD_SAME_TYPE")))
+	assert.False(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "b.proto", 5, 9, 5, 14, "FIELD_SAME_TYPE")))
+	assert.False(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 14, "FIELD_SAME_LABEL")))
+	// removed elements have no location
+	assert.True(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "a.proto", 2, 1, 10, 2, "FIELD_NO_DELETE")))
+	assert.False(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotation(t, "b.proto", 2, 1, 10, 2, "FIELD_NO_DELETE")))
+	assert.True(t, changesContainFileAnnotation(changes, bufanalysistesting.NewFileAnnotationNoLocationOrPath(t, "FIELD_NO_DELETE")))
+}
@@ -21,8 +21,10 @@
 package bufbreakingbuild
 
 import (
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking/internal/bufbreakingcheck"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
 )
 
 var (
@@ -62,6 +64,42 @@ var (
 		"extension ranges are not deleted from a given message",
 		bufbreakingcheck.CheckExtensionMessageNoDelete,
 	)
+	// ExtensionNoDeleteRuleBuilder is a rule builder.
+	ExtensionNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_NO_DELETE",
+		"extensions are not deleted from a given file",
+		bufbreakingcheck.CheckExtensionNoDelete,
+	)
+	// ExtensionSameExtendeeRuleBuilder is a rule builder.
+	ExtensionSameExtendeeRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_SAME_EXTENDEE",
+		"extensions extend the same message",
+		bufbreakingcheck.CheckExtensionSameExtendee,
+	)
+	// ExtensionSameNumberRuleBuilder is a rule builder.
+	ExtensionSameNumberRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_SAME_NUMBER",
+		"extensions have the same numbers",
+		bufbreakingcheck.CheckExtensionSameNumber,
+	)
+	// ExtensionSameTypeRuleBuilder is a rule builder.
+	ExtensionSameTypeRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_SAME_TYPE",
+		"extensions have the same types",
+		bufbreakingcheck.CheckExtensionSameType,
+	)
+	// ExtensionWireCompatibleTypeRuleBuilder is a rule builder.
+	ExtensionWireCompatibleTypeRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_WIRE_COMPATIBLE_TYPE",
+		"extensions have wire-compatible types",
+		bufbreakingcheck.CheckExtensionWireCompatibleType,
+	)
+	// ExtensionWireJSONCompatibleTypeRuleBuilder is a rule builder.
+	ExtensionWireJSONCompatibleTypeRuleBuilder = internal.NewNopRuleBuilder(
+		"EXTENSION_WIRE_JSON_COMPATIBLE_TYPE",
+		"extensions have wire and JSON compatible types",
+		bufbreakingcheck.CheckExtensionWireJSONCompatibleType,
+	)
 	// FieldNoDeleteRuleBuilder is a rule builder.
 	FieldNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_NO_DELETE",
@@ -86,6 +124,12 @@ var (
 		"fields have the same value for the ctype option",
 		bufbreakingcheck.CheckFieldSameCType,
 	)
+	// FieldSameDefaultRuleBuilder is a rule builder.
+	FieldSameDefaultRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_SAME_DEFAULT",
+		"fields have the same default values, including implicit defaults",
+		bufbreakingcheck.CheckFieldSameDefault,
+	)
 	// FieldSameJSONNameRuleBuilder is a rule builder.
 	FieldSameJSONNameRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_JSON_NAME",
@@ -116,12 +160,36 @@ var (
 		"fields have the same oneofs in a given message",
 		bufbreakingcheck.CheckFieldSameOneof,
 	)
+	// FieldSamePackedRuleBuilder is a rule builder.
+	FieldSamePackedRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_SAME_PACKED",
+		"repeated fields have the same packed encoding, including the proto3 default of packed",
+		bufbreakingcheck.CheckFieldSamePacked,
+	)
+	// FieldSamePresenceRuleBuilder is a rule builder.
+	FieldSamePresenceRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_SAME_PRESENCE",
+		"singular fields have the same explicit or implicit presence",
+		bufbreakingcheck.CheckFieldSamePresence,
+	)
 	// FieldSameTypeRuleBuilder is a rule builder.
 	FieldSameTypeRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_SAME_TYPE",
 		"fields have the same types in a given message",
 		bufbreakingcheck.CheckFieldSameType,
 	)
+	// FieldWireCompatibleTypeRuleBuilder is a rule builder.
+	FieldWireCompatibleTypeRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_WIRE_COMPATIBLE_TYPE",
+		"fields have wire-compatible types in a given message",
+		bufbreakingcheck.CheckFieldWireCompatibleType,
+	)
+	// FieldWireJSONCompatibleTypeRuleBuilder is a rule builder.
+	FieldWireJSONCompatibleTypeRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_WIRE_JSON_COMPATIBLE_TYPE",
+		"fields have wire and JSON compatible types in a given message",
+		bufbreakingcheck.CheckFieldWireJSONCompatibleType,
+	)
 	// FileNoDeleteRuleBuilder is a rule builder.
 	FileNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"FILE_NO_DELETE",
@@ -278,12 +346,30 @@ var (
 		"oneofs are not deleted from a given message",
 		bufbreakingcheck.CheckOneofNoDelete,
 	)
+	// OptionSameValueRuleBuilder is a rule builder.
+	OptionSameValueRuleBuilder = internal.NewRuleBuilder(
+		"OPTION_SAME_VALUE",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return "custom options listed in compared_options have the same values (configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return bufbreakingcheck.CheckOptionSameValue(id, ignoreFunc, previousFiles, files, configBuilder.ComparedOptions)
+			}), nil
+		},
+	)
 	// PackageEnumNoDeleteRuleBuilder is a rule builder.
 	PackageEnumNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"PACKAGE_ENUM_NO_DELETE",
 		"enums are not deleted from a given package",
 		bufbreakingcheck.CheckPackageEnumNoDelete,
 	)
+	// PackageExtensionNoDeleteRuleBuilder is a rule builder.
+	PackageExtensionNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
+		"PACKAGE_EXTENSION_NO_DELETE",
+		"extensions are not deleted from a given package",
+		bufbreakingcheck.CheckPackageExtensionNoDelete,
+	)
 	// PackageMessageNoDeleteRuleBuilder is a rule builder.
 	PackageMessageNoDeleteRuleBuilder = internal.NewNopRuleBuilder(
 		"PACKAGE_MESSAGE_NO_DELETE",
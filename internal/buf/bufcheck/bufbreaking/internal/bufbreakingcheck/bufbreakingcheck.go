@@ -23,6 +23,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
 	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
 )
@@ -160,6 +162,192 @@ func checkExtensionMessageNoDelete(add addFunc, previousMessage protosource.Mess
 	return nil
 }
 
+// CheckExtensionNoDelete is a check function.
+var CheckExtensionNoDelete = newFilePairCheckFunc(checkExtensionNoDelete)
+
+func checkExtensionNoDelete(add addFunc, previousFile protosource.File, file protosource.File) error {
+	previousFullNameToExtension, err := protosource.FullNameToExtension(previousFile)
+	if err != nil {
+		return err
+	}
+	fullNameToExtension, err := protosource.FullNameToExtension(file)
+	if err != nil {
+		return err
+	}
+	var nestedNameToMessage map[string]protosource.Message
+	for previousFullName, previousExtension := range previousFullNameToExtension {
+		if _, ok := fullNameToExtension[previousFullName]; !ok {
+			if nestedNameToMessage == nil {
+				nestedNameToMessage, err = protosource.NestedNameToMessage(file)
+				if err != nil {
+					return err
+				}
+			}
+			descriptor, location := getDescriptorAndLocationForDeletedMessage(file, nestedNameToMessage, previousExtension.NestedName())
+			add(descriptor, location, `Previously present extension %q was deleted from file.`, previousExtension.NestedName())
+		}
+	}
+	return nil
+}
+
+// CheckExtensionSameExtendee is a check function.
+var CheckExtensionSameExtendee = newExtensionPairCheckFunc(checkExtensionSameExtendee)
+
+func checkExtensionSameExtendee(add addFunc, previousExtension protosource.Field, extension protosource.Field) error {
+	if previousExtension.Extendee() != extension.Extendee() {
+		add(
+			extension,
+			withBackupLocation(extension.ExtendeeLocation(), extension.Location()),
+			`Extension %q changed extendee from %q to %q.`,
+			extension.NestedName(),
+			strings.TrimPrefix(previousExtension.Extendee(), "."),
+			strings.TrimPrefix(extension.Extendee(), "."),
+		)
+	}
+	return nil
+}
+
+// CheckExtensionSameNumber is a check function.
+var CheckExtensionSameNumber = newExtensionPairCheckFunc(checkExtensionSameNumber)
+
+func checkExtensionSameNumber(add addFunc, previousExtension protosource.Field, extension protosource.Field) error {
+	if previousExtension.Number() != extension.Number() {
+		// otherwise prints as hex
+		previousNumberString := strconv.FormatInt(int64(previousExtension.Number()), 10)
+		numberString := strconv.FormatInt(int64(extension.Number()), 10)
+		add(extension, extension.NumberLocation(), `Extension %q changed number from %q to %q.`, extension.NestedName(), previousNumberString, numberString)
+	}
+	return nil
+}
+
+// CheckExtensionSameType is a check function.
+var CheckExtensionSameType = newExtensionPairCheckFunc(checkExtensionSameType)
+
+func checkExtensionSameType(add addFunc, previousExtension protosource.Field, extension protosource.Field) error {
+	if previousExtension.Type() != extension.Type() {
+		add(
+			extension,
+			withBackupLocation(extension.TypeLocation(), extension.TypeNameLocation()),
+			`Extension %q changed type from %q to %q.`,
+			extension.NestedName(),
+			previousExtension.Type().String(),
+			extension.Type().String(),
+		)
+		return nil
+	}
+	switch extension.Type() {
+	case protosource.FieldDescriptorProtoTypeEnum, protosource.FieldDescriptorProtoTypeGroup, protosource.FieldDescriptorProtoTypeMessage:
+		if previousExtension.TypeName() != extension.TypeName() {
+			add(
+				extension,
+				extension.TypeNameLocation(),
+				`Extension %q changed type from %q to %q.`,
+				extension.NestedName(),
+				strings.TrimPrefix(previousExtension.TypeName(), "."),
+				strings.TrimPrefix(extension.TypeName(), "."),
+			)
+		}
+	}
+	return nil
+}
+
+// CheckExtensionWireCompatibleType is a check function.
+var CheckExtensionWireCompatibleType = newExtensionNumberPairCheckFunc(checkExtensionWireCompatibleType)
+
+func checkExtensionWireCompatibleType(add addFunc, previousExtension protosource.Field, extension protosource.Field) error {
+	if !fieldTypesAreWireCompatible(previousExtension.Type(), extension.Type()) {
+		addExtensionChangedTypeNotWireCompatible(add, previousExtension, extension)
+		return nil
+	}
+	// enums with different names are still encoded as varints, so only
+	// messages and groups are compared by name
+	checkExtensionSameMessageOrGroupTypeName(add, previousExtension, extension)
+	return nil
+}
+
+// CheckExtensionWireJSONCompatibleType is a check function.
+var CheckExtensionWireJSONCompatibleType = newExtensionNumberPairCheckFunc(checkExtensionWireJSONCompatibleType)
+
+func checkExtensionWireJSONCompatibleType(add addFunc, previousExtension protosource.Field, extension protosource.Field) error {
+	if !fieldTypesAreWireCompatible(previousExtension.Type(), extension.Type()) {
+		addExtensionChangedTypeNotWireCompatible(add, previousExtension, extension)
+		return nil
+	}
+	// otherwise prints as hex
+	previousNumberString := strconv.FormatInt(int64(previousExtension.Number()), 10)
+	if !fieldTypesAreJSONCompatible(previousExtension.Type(), extension.Type()) {
+		add(
+			extension,
+			withBackupLocation(extension.TypeLocation(), extension.TypeNameLocation()),
+			`Extension %q on message %q changed type from %q to %q. While the types are wire compatible, this is not JSON compatible as %s values are encoded as %s and %s values are encoded as %s.`,
+			previousNumberString,
+			strings.TrimPrefix(extension.Extendee(), "."),
+			previousExtension.Type().String(),
+			extension.Type().String(),
+			previousExtension.Type().String(),
+			fieldTypeToJSONEncoding[previousExtension.Type()],
+			extension.Type().String(),
+			fieldTypeToJSONEncoding[extension.Type()],
+		)
+		return nil
+	}
+	if extension.Type() == protosource.FieldDescriptorProtoTypeEnum && previousExtension.TypeName() != extension.TypeName() {
+		add(
+			extension,
+			extension.TypeNameLocation(),
+			`Extension %q on message %q changed type from %q to %q. While enums are wire compatible as they are encoded as varints, this is not JSON compatible as enum values are encoded as JSON strings of their names, which are not compared across different enums.`,
+			previousNumberString,
+			strings.TrimPrefix(extension.Extendee(), "."),
+			strings.TrimPrefix(previousExtension.TypeName(), "."),
+			strings.TrimPrefix(extension.TypeName(), "."),
+		)
+		return nil
+	}
+	checkExtensionSameMessageOrGroupTypeName(add, previousExtension, extension)
+	return nil
+}
+
+func addExtensionChangedTypeNotWireCompatible(add addFunc, previousExtension protosource.Field, extension protosource.Field) {
+	// otherwise prints as hex
+	previousNumberString := strconv.FormatInt(int64(previousExtension.Number()), 10)
+	add(
+		extension,
+		withBackupLocation(extension.TypeLocation(), extension.TypeNameLocation()),
+		`Extension %q on message %q changed type from %q to %q. This is not wire compatible as %s values are encoded as %s and %s values are encoded as %s.`,
+		previousNumberString,
+		strings.TrimPrefix(extension.Extendee(), "."),
+		previousExtension.Type().String(),
+		extension.Type().String(),
+		previousExtension.Type().String(),
+		fieldTypeToWireEncoding[previousExtension.Type()],
+		extension.Type().String(),
+		fieldTypeToWireEncoding[extension.Type()],
+	)
+}
+
+// checkExtensionSameMessageOrGroupTypeName checks that message and group
+// extensions of the same type did not change their type name, as the fields of
+// different message types are not compared with each other.
+func checkExtensionSameMessageOrGroupTypeName(add addFunc, previousExtension protosource.Field, extension protosource.Field) {
+	if previousExtension.Type() != extension.Type() || previousExtension.TypeName() == extension.TypeName() {
+		return
+	}
+	switch extension.Type() {
+	case protosource.FieldDescriptorProtoTypeGroup, protosource.FieldDescriptorProtoTypeMessage:
+		// otherwise prints as hex
+		previousNumberString := strconv.FormatInt(int64(previousExtension.Number()), 10)
+		add(
+			extension,
+			extension.TypeNameLocation(),
+			`Extension %q on message %q changed type from %q to %q. The fields of different messages are not compared, so this is not known to be wire compatible.`,
+			previousNumberString,
+			strings.TrimPrefix(extension.Extendee(), "."),
+			strings.TrimPrefix(previousExtension.TypeName(), "."),
+			strings.TrimPrefix(extension.TypeName(), "."),
+		)
+	}
+}
+
 // CheckFieldNoDelete is a check function.
 var CheckFieldNoDelete = newMessagePairCheckFunc(checkFieldNoDelete)
 
@@ -310,6 +498,72 @@ func checkFieldSameOneof(add addFunc, previousField protosource.Field, field pro
 	return nil
 }
 
+// CheckFieldSameDefault is a check function.
+var CheckFieldSameDefault = newFieldPairWithEnumsCheckFunc(checkFieldSameDefault)
+
+func checkFieldSameDefault(
+	add addFunc,
+	previousFullNameToEnum map[string]protosource.Enum,
+	fullNameToEnum map[string]protosource.Enum,
+	previousField protosource.Field,
+	field protosource.Field,
+) error {
+	// type changes are handled by FIELD_SAME_TYPE and the wire compatibility rules
+	if previousField.Type() != field.Type() || !fieldCanHaveDefault(previousField) || !fieldCanHaveDefault(field) {
+		return nil
+	}
+	previousDefault, ok := getFieldDefault(previousField, previousFullNameToEnum)
+	if !ok {
+		return nil
+	}
+	currentDefault, ok := getFieldDefault(field, fullNameToEnum)
+	if !ok {
+		return nil
+	}
+	if !fieldDefaultsEqual(field.Type(), previousDefault, currentDefault) {
+		// otherwise prints as hex
+		previousNumberString := strconv.FormatInt(int64(previousField.Number()), 10)
+		add(field, withBackupLocation(field.DefaultValueLocation(), field.Location()), `Field %q on message %q changed default value from %q to %q.`, previousNumberString, field.Message().Name(), previousDefault, currentDefault)
+	}
+	return nil
+}
+
+// CheckFieldSamePacked is a check function.
+var CheckFieldSamePacked = newFieldPairCheckFunc(checkFieldSamePacked)
+
+func checkFieldSamePacked(add addFunc, previousField protosource.Field, field protosource.Field) error {
+	// label and type changes are handled by other rules
+	if !fieldCanBePacked(previousField) || !fieldCanBePacked(field) {
+		return nil
+	}
+	previousPacked := fieldIsPacked(previousField)
+	packed := fieldIsPacked(field)
+	if previousPacked != packed {
+		// otherwise prints as hex
+		previousNumberString := strconv.FormatInt(int64(previousField.Number()), 10)
+		add(field, withBackupLocation(field.PackedLocation(), field.Location()), `Field %q on message %q changed from %s to %s encoding.`, previousNumberString, field.Message().Name(), getPackedString(previousPacked), getPackedString(packed))
+	}
+	return nil
+}
+
+// CheckFieldSamePresence is a check function.
+var CheckFieldSamePresence = newFieldPairCheckFunc(checkFieldSamePresence)
+
+func checkFieldSamePresence(add addFunc, previousField protosource.Field, field protosource.Field) error {
+	// repeated fields do not track presence, and label changes are handled by FIELD_SAME_LABEL
+	if previousField.Label() == protosource.FieldDescriptorProtoLabelRepeated || field.Label() == protosource.FieldDescriptorProtoLabelRepeated {
+		return nil
+	}
+	previousHasPresence := fieldHasPresence(previousField)
+	hasPresence := fieldHasPresence(field)
+	if previousHasPresence != hasPresence {
+		// otherwise prints as hex
+		previousNumberString := strconv.FormatInt(int64(previousField.Number()), 10)
+		add(field, field.Location(), `Field %q on message %q changed from %s to %s presence.`, previousNumberString, field.Message().Name(), getPresenceString(previousHasPresence), getPresenceString(hasPresence))
+	}
+	return nil
+}
+
 // CheckFieldSameType is a check function.
 var CheckFieldSameType = newFieldPairCheckFunc(checkFieldSameType)
 
@@ -360,6 +614,103 @@ func checkFieldSameType(add addFunc, previousField protosource.Field, field prot
 	return nil
 }
 
+// CheckFieldWireCompatibleType is a check function.
+var CheckFieldWireCompatibleType = newFieldPairCheckFunc(checkFieldWireCompatibleType)
+
+func checkFieldWireCompatibleType(add addFunc, previousField protosource.Field, field protosource.Field) error {
+	if !fieldTypesAreWireCompatible(previousField.Type(), field.Type()) {
+		addFieldChangedTypeNotWireCompatible(add, previousField, field)
+		return nil
+	}
+	// enums with different names are still encoded as varints, so only
+	// messages and groups are compared by name
+	checkFieldSameMessageOrGroupTypeName(add, previousField, field)
+	return nil
+}
+
+// CheckFieldWireJSONCompatibleType is a check function.
+var CheckFieldWireJSONCompatibleType = newFieldPairCheckFunc(checkFieldWireJSONCompatibleType)
+
+func checkFieldWireJSONCompatibleType(add addFunc, previousField protosource.Field, field protosource.Field) error {
+	if !fieldTypesAreWireCompatible(previousField.Type(), field.Type()) {
+		addFieldChangedTypeNotWireCompatible(add, previousField, field)
+		return nil
+	}
+	// otherwise prints as hex
+	previousNumberString := strconv.FormatInt(int64(previousField.Number()), 10)
+	if !fieldTypesAreJSONCompatible(previousField.Type(), field.Type()) {
+		add(
+			field,
+			withBackupLocation(field.TypeLocation(), field.TypeNameLocation()),
+			`Field %q on message %q changed type from %q to %q. While the types are wire compatible, this is not JSON compatible as %s values are encoded as %s and %s values are encoded as %s.`,
+			previousNumberString,
+			field.Message().Name(),
+			previousField.Type().String(),
+			field.Type().String(),
+			previousField.Type().String(),
+			fieldTypeToJSONEncoding[previousField.Type()],
+			field.Type().String(),
+			fieldTypeToJSONEncoding[field.Type()],
+		)
+		return nil
+	}
+	if field.Type() == protosource.FieldDescriptorProtoTypeEnum && previousField.TypeName() != field.TypeName() {
+		add(
+			field,
+			field.TypeNameLocation(),
+			`Field %q on message %q changed type from %q to %q. While enums are wire compatible as they are encoded as varints, this is not JSON compatible as enum values are encoded as JSON strings of their names, which are not compared across different enums.`,
+			previousNumberString,
+			field.Message().Name(),
+			strings.TrimPrefix(previousField.TypeName(), "."),
+			strings.TrimPrefix(field.TypeName(), "."),
+		)
+		return nil
+	}
+	checkFieldSameMessageOrGroupTypeName(add, previousField, field)
+	return nil
+}
+
+func addFieldChangedTypeNotWireCompatible(add addFunc, previousField protosource.Field, field protosource.Field) {
+	// otherwise prints as hex
+	previousNumberString := strconv.FormatInt(int64(previousField.Number()), 10)
+	add(
+		field,
+		withBackupLocation(field.TypeLocation(), field.TypeNameLocation()),
+		`Field %q on message %q changed type from %q to %q. This is not wire compatible as %s values are encoded as %s and %s values are encoded as %s.`,
+		previousNumberString,
+		field.Message().Name(),
+		previousField.Type().String(),
+		field.Type().String(),
+		previousField.Type().String(),
+		fieldTypeToWireEncoding[previousField.Type()],
+		field.Type().String(),
+		fieldTypeToWireEncoding[field.Type()],
+	)
+}
+
+// checkFieldSameMessageOrGroupTypeName checks that message and group fields of
+// the same type did not change their type name, as the fields of different
+// message types are not compared with each other.
+func checkFieldSameMessageOrGroupTypeName(add addFunc, previousField protosource.Field, field protosource.Field) {
+	if previousField.Type() != field.Type() || previousField.TypeName() == field.TypeName() {
+		return
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeGroup, protosource.FieldDescriptorProtoTypeMessage:
+		// otherwise prints as hex
+		previousNumberString := strconv.FormatInt(int64(previousField.Number()), 10)
+		add(
+			field,
+			field.TypeNameLocation(),
+			`Field %q on message %q changed type from %q to %q. The fields of different messages are not compared, so this is not known to be wire compatible.`,
+			previousNumberString,
+			field.Message().Name(),
+			strings.TrimPrefix(previousField.TypeName(), "."),
+			strings.TrimPrefix(field.TypeName(), "."),
+		)
+	}
+}
+
 // CheckFileNoDelete is a check function.
 var CheckFileNoDelete = newFilesCheckFunc(checkFileNoDelete)
 
@@ -625,6 +976,32 @@ func checkOneofNoDelete(add addFunc, previousMessage protosource.Message, messag
 	return nil
 }
 
+// CheckOptionSameValue is a check function.
+var CheckOptionSameValue = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	previousFiles []protosource.File,
+	files []protosource.File,
+	comparedOptions []string,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesCheckFunc(
+		func(add addFunc, previousFiles []protosource.File, files []protosource.File) error {
+			return checkOptionSameValue(add, previousFiles, files, comparedOptions)
+		},
+	)(id, ignoreFunc, previousFiles, files)
+}
+
+func checkOptionSameValue(add addFunc, previousFiles []protosource.File, files []protosource.File, comparedOptions []string) error {
+	return forEachOptionValueChange(
+		previousFiles,
+		files,
+		comparedOptions,
+		func(optionValueChange *optionValueChange) {
+			add(optionValueChange.optionDescriptor, optionValueChange.location(), `%s changed option "(%s)" from %s to %s.`, getOptionDescriptorDescription(optionValueChange.optionDescriptor), optionValueChange.comparedOption, optionValueChange.previousValueString(), optionValueChange.valueString())
+		},
+	)
+}
+
 // CheckPackageEnumNoDelete is a check function.
 var CheckPackageEnumNoDelete = newFilesCheckFunc(checkPackageEnumNoDelete)
 
@@ -670,6 +1047,58 @@ func checkPackageEnumNoDelete(add addFunc, previousFiles []protosource.File, fil
 	return nil
 }
 
+// CheckPackageExtensionNoDelete is a check function.
+var CheckPackageExtensionNoDelete = newFilesCheckFunc(checkPackageExtensionNoDelete)
+
+func checkPackageExtensionNoDelete(add addFunc, previousFiles []protosource.File, files []protosource.File) error {
+	previousFullNameToExtension, err := protosource.FullNameToExtension(previousFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToExtension, err := protosource.FullNameToExtension(files...)
+	if err != nil {
+		return err
+	}
+	packageToFiles, err := protosource.PackageToFiles(files...)
+	if err != nil {
+		return err
+	}
+	// caching across loops
+	var filePathToFile map[string]protosource.File
+	for previousFullName, previousExtension := range previousFullNameToExtension {
+		if _, ok := fullNameToExtension[previousFullName]; ok {
+			continue
+		}
+		previousPackage := previousExtension.File().Package()
+		// deleted packages are handled by PACKAGE_NO_DELETE
+		if _, ok := packageToFiles[previousPackage]; !ok {
+			continue
+		}
+		// if cache not populated, populate it
+		if filePathToFile == nil {
+			filePathToFile, err = protosource.FilePathToFile(files...)
+			if err != nil {
+				return err
+			}
+		}
+		// check if the file still exists
+		file, ok := filePathToFile[previousExtension.File().Path()]
+		if ok {
+			// file exists, try to get a location to attach the error to
+			nestedNameToMessage, err := protosource.NestedNameToMessage(file)
+			if err != nil {
+				return err
+			}
+			descriptor, location := getDescriptorAndLocationForDeletedMessage(file, nestedNameToMessage, previousExtension.NestedName())
+			add(descriptor, location, `Previously present extension %q was deleted from package %q.`, previousExtension.NestedName(), previousPackage)
+		} else {
+			// file does not exist, we don't know where the extension was deleted from
+			add(nil, nil, `Previously present extension %q was deleted from package %q.`, previousExtension.NestedName(), previousPackage)
+		}
+	}
+	return nil
+}
+
 // CheckPackageMessageNoDelete is a check function.
 var CheckPackageMessageNoDelete = newFilesCheckFunc(checkPackageMessageNoDelete)
 
@@ -0,0 +1,1015 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+)
+
+const (
+	// ChangeTypeAdded is the type of changes where an element was added.
+	ChangeTypeAdded = "added"
+	// ChangeTypeRemoved is the type of changes where an element was removed.
+	ChangeTypeRemoved = "removed"
+	// ChangeTypeChanged is the type of changes where an element present in
+	// both the previous and current files was changed.
+	ChangeTypeChanged = "changed"
+)
+
+const (
+	// ChangeKindFile is the kind of changes to files.
+	ChangeKindFile = "file"
+	// ChangeKindMessage is the kind of changes to messages.
+	ChangeKindMessage = "message"
+	// ChangeKindField is the kind of changes to fields.
+	ChangeKindField = "field"
+	// ChangeKindOneof is the kind of changes to oneofs.
+	ChangeKindOneof = "oneof"
+	// ChangeKindEnum is the kind of changes to enums.
+	ChangeKindEnum = "enum"
+	// ChangeKindEnumValue is the kind of changes to enum values.
+	ChangeKindEnumValue = "enum_value"
+	// ChangeKindExtension is the kind of changes to extensions.
+	ChangeKindExtension = "extension"
+	// ChangeKindService is the kind of changes to services.
+	ChangeKindService = "service"
+	// ChangeKindRPC is the kind of changes to RPCs.
+	ChangeKindRPC = "rpc"
+	// ChangeKindOption is the kind of changes to the compared custom options.
+	ChangeKindOption = "option"
+)
+
+// Change is a change between the previous and current files.
+type Change struct {
+	// Type is one of the ChangeTypes.
+	Type string
+	// Kind is one of the ChangeKinds.
+	Kind string
+	// Name is the path for files, the full name followed by the option in
+	// parentheses for options, and the full name otherwise.
+	Name string
+	// Descriptor is the current descriptor of the change, or the previous
+	// descriptor for removed elements.
+	Descriptor protosource.Descriptor
+	// Location is the location of the change in the current files.
+	//
+	// Can be nil, and is always nil for removed elements.
+	Location protosource.Location
+	// ReportDescriptor is the current descriptor the check functions report
+	// the change at if it is not the Descriptor, such as the message that
+	// contained a removed field, or the previous file of a moved message.
+	//
+	// Can be nil.
+	ReportDescriptor protosource.Descriptor
+	// ReportLocation is the location the check functions report the change
+	// at if ReportDescriptor is set.
+	//
+	// Can be nil.
+	ReportLocation protosource.Location
+	// Message describes the change.
+	Message string
+	// RuleIDs are the ids of the rules that detect the change, regardless of
+	// whether the rules are configured.
+	RuleIDs []string
+}
+
+// GetChanges returns the changes between the previous and current files.
+//
+// Elements are paired between the previous and current files in the same way
+// as the check functions pair them, and the RuleIDs of each Change are
+// determined by running the check functions for the pair. Removed or added
+// elements within removed or added elements are not returned, and changes to
+// the key and value fields of map entries are returned as changes to the map
+// fields.
+//
+// Changes are sorted by path, location, kind, and name.
+func GetChanges(previousFiles []protosource.File, files []protosource.File, comparedOptions []string) ([]*Change, error) {
+	changeSetBuilder, err := newChangeSetBuilder(previousFiles, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range []func() error{
+		changeSetBuilder.addFileChanges,
+		changeSetBuilder.addMessageChanges,
+		changeSetBuilder.addEnumChanges,
+		changeSetBuilder.addExtensionChanges,
+		changeSetBuilder.addServiceChanges,
+	} {
+		if err := f(); err != nil {
+			return nil, err
+		}
+	}
+	if err := changeSetBuilder.addOptionChanges(comparedOptions); err != nil {
+		return nil, err
+	}
+	changes := changeSetBuilder.changes
+	sortChanges(changes)
+	return changes, nil
+}
+
+// ruleCheck is the check function of a rule applied to a pair of elements.
+type ruleCheck struct {
+	id    string
+	check func(addFunc) error
+}
+
+// recordedFailure is a failure of a check function recorded by a
+// recordingAddFunc.
+type recordedFailure struct {
+	descriptor protosource.Descriptor
+	location   protosource.Location
+	message    string
+}
+
+func newRecordingAddFunc(recordedFailures *[]*recordedFailure) addFunc {
+	return func(descriptor protosource.Descriptor, location protosource.Location, format string, args ...interface{}) {
+		*recordedFailures = append(
+			*recordedFailures,
+			&recordedFailure{
+				descriptor: descriptor,
+				location:   location,
+				message:    fmt.Sprintf(format, args...),
+			},
+		)
+	}
+}
+
+type changeSetBuilder struct {
+	previousFiles               []protosource.File
+	files                       []protosource.File
+	previousFilePathToFile      map[string]protosource.File
+	filePathToFile              map[string]protosource.File
+	packageToFiles              map[string][]protosource.File
+	previousFullNameToMessage   map[string]protosource.Message
+	fullNameToMessage           map[string]protosource.Message
+	previousFullNameToEnum      map[string]protosource.Enum
+	fullNameToEnum              map[string]protosource.Enum
+	previousFullNameToExtension map[string]protosource.Field
+	fullNameToExtension         map[string]protosource.Field
+	previousFullNameToService   map[string]protosource.Service
+	fullNameToService           map[string]protosource.Service
+	changes                     []*Change
+}
+
+func newChangeSetBuilder(previousFiles []protosource.File, files []protosource.File) (*changeSetBuilder, error) {
+	changeSetBuilder := &changeSetBuilder{
+		previousFiles: previousFiles,
+		files:         files,
+	}
+	var err error
+	if changeSetBuilder.previousFilePathToFile, err = protosource.FilePathToFile(previousFiles...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.filePathToFile, err = protosource.FilePathToFile(files...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.packageToFiles, err = protosource.PackageToFiles(files...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.previousFullNameToMessage, err = protosource.FullNameToMessage(previousFiles...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.fullNameToMessage, err = protosource.FullNameToMessage(files...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.previousFullNameToEnum, err = protosource.FullNameToEnum(previousFiles...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.fullNameToEnum, err = protosource.FullNameToEnum(files...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.previousFullNameToExtension, err = protosource.FullNameToExtension(previousFiles...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.fullNameToExtension, err = protosource.FullNameToExtension(files...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.previousFullNameToService, err = protosource.FullNameToService(previousFiles...); err != nil {
+		return nil, err
+	}
+	if changeSetBuilder.fullNameToService, err = protosource.FullNameToService(files...); err != nil {
+		return nil, err
+	}
+	return changeSetBuilder, nil
+}
+
+func (b *changeSetBuilder) addFileChanges() error {
+	for previousFilePath, previousFile := range b.previousFilePathToFile {
+		file, ok := b.filePathToFile[previousFilePath]
+		if !ok {
+			ruleIDs := []string{"FILE_NO_DELETE"}
+			if _, ok := b.packageToFiles[previousFile.Package()]; !ok {
+				ruleIDs = append(ruleIDs, "PACKAGE_NO_DELETE")
+			}
+			b.addRemoved(ChangeKindFile, previousFilePath, previousFile, nil, ruleIDs, `Previously present file %q was deleted.`, previousFilePath)
+			continue
+		}
+		for _, ruleCheck := range []ruleCheck{
+			newFilePairRuleCheck("FILE_SAME_CC_ENABLE_ARENAS", checkFileSameCcEnableArenas, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_CC_GENERIC_SERVICES", checkFileSameCcGenericServices, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_CSHARP_NAMESPACE", checkFileSameCsharpNamespace, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_GO_PACKAGE", checkFileSameGoPackage, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_JAVA_GENERIC_SERVICES", checkFileSameJavaGenericServices, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_JAVA_MULTIPLE_FILES", checkFileSameJavaMultipleFiles, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_JAVA_OUTER_CLASSNAME", checkFileSameJavaOuterClassname, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_JAVA_PACKAGE", checkFileSameJavaPackage, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_JAVA_STRING_CHECK_UTF8", checkFileSameJavaStringCheckUtf8, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_OBJC_CLASS_PREFIX", checkFileSameObjcClassPrefix, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_OPTIMIZE_FOR", checkFileSameOptimizeFor, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_PACKAGE", checkFileSamePackage, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_PHP_CLASS_PREFIX", checkFileSamePhpClassPrefix, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_PHP_GENERIC_SERVICES", checkFileSamePhpGenericServices, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_PHP_METADATA_NAMESPACE", checkFileSamePhpMetadataNamespace, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_PHP_NAMESPACE", checkFileSamePhpNamespace, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_PY_GENERIC_SERVICES", checkFileSamePyGenericServices, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_RUBY_PACKAGE", checkFileSameRubyPackage, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_SWIFT_PREFIX", checkFileSameSwiftPrefix, previousFile, file),
+			newFilePairRuleCheck("FILE_SAME_SYNTAX", checkFileSameSyntax, previousFile, file),
+		} {
+			if err := b.addChanged(ChangeKindFile, previousFilePath, ruleCheck); err != nil {
+				return err
+			}
+		}
+	}
+	for filePath, file := range b.filePathToFile {
+		if _, ok := b.previousFilePathToFile[filePath]; !ok {
+			b.addAdded(ChangeKindFile, filePath, file, nil, nil, `File %q was added.`, filePath)
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addMessageChanges() error {
+	for previousFullName, previousMessage := range b.previousFullNameToMessage {
+		if previousMessage.IsMapEntry() {
+			// changes to the key and value types are changes to the map field,
+			// and the removal of the map entry is part of the removal of the field
+			if message, ok := b.fullNameToMessage[previousFullName]; ok && message.IsMapEntry() {
+				if err := b.addMapEntryChanges(previousMessage, message); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		message, ok := b.fullNameToMessage[previousFullName]
+		if !ok {
+			if b.previousParentIsRemoved(previousMessage) {
+				continue
+			}
+			ruleIDs := b.getRemovedRuleIDs(previousMessage, "MESSAGE_NO_DELETE", "PACKAGE_MESSAGE_NO_DELETE")
+			b.addRemoved(ChangeKindMessage, previousFullName, previousMessage, b.getParent(previousMessage), ruleIDs, `Previously present message %q was deleted.`, previousFullName)
+			continue
+		}
+		if err := b.addMovedIfMoved(ChangeKindMessage, "Message", previousMessage, message, "MESSAGE_NO_DELETE"); err != nil {
+			return err
+		}
+		for _, ruleCheck := range []ruleCheck{
+			newMessagePairRuleCheck("EXTENSION_MESSAGE_NO_DELETE", checkExtensionMessageNoDelete, previousMessage, message),
+			newMessagePairRuleCheck("MESSAGE_NO_REMOVE_STANDARD_DESCRIPTOR_ACCESSOR", checkMessageNoRemoveStandardDescriptorAccessor, previousMessage, message),
+			newMessagePairRuleCheck("MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT", checkMessageSameMessageSetWireFormat, previousMessage, message),
+			newMessagePairRuleCheck("RESERVED_MESSAGE_NO_DELETE", checkReservedMessageNoDelete, previousMessage, message),
+		} {
+			if err := b.addChanged(ChangeKindMessage, previousFullName, ruleCheck); err != nil {
+				return err
+			}
+		}
+		if err := b.addFieldChanges(previousMessage, message); err != nil {
+			return err
+		}
+		if err := b.addOneofChanges(previousMessage, message); err != nil {
+			return err
+		}
+	}
+	for fullName, message := range b.fullNameToMessage {
+		if message.IsMapEntry() {
+			continue
+		}
+		if _, ok := b.previousFullNameToMessage[fullName]; !ok && !b.parentIsAdded(message) {
+			b.addAdded(ChangeKindMessage, fullName, message, message.Location(), nil, `Message %q was added.`, fullName)
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addFieldChanges(previousMessage protosource.Message, message protosource.Message) error {
+	previousNumberToField, err := protosource.NumberToMessageField(previousMessage)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(message)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousField := range previousNumberToField {
+		previousFieldFullName := previousMessage.FullName() + "." + previousField.Name()
+		field, ok := numberToField[previousNumber]
+		if !ok {
+			ruleIDs := []string{"FIELD_NO_DELETE"}
+			if !isDeletedFieldAllowedWithRules(previousField, message, true, false) {
+				ruleIDs = append(ruleIDs, "FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED")
+			}
+			if !isDeletedFieldAllowedWithRules(previousField, message, false, true) {
+				ruleIDs = append(ruleIDs, "FIELD_NO_DELETE_UNLESS_NAME_RESERVED")
+			}
+			if previousField.Label() == protosource.FieldDescriptorProtoLabelRequired {
+				ruleIDs = append(ruleIDs, "MESSAGE_SAME_REQUIRED_FIELDS")
+			}
+			b.addRemoved(ChangeKindField, previousFieldFullName, previousField, message, ruleIDs, `Previously present field "%d" with name %q on message %q was deleted.`, previousNumber, previousField.Name(), previousMessage.FullName())
+			continue
+		}
+		if err := b.addFieldPairChanges(previousFieldFullName, previousField, field); err != nil {
+			return err
+		}
+	}
+	for number, field := range numberToField {
+		if _, ok := previousNumberToField[number]; !ok {
+			var ruleIDs []string
+			if field.Label() == protosource.FieldDescriptorProtoLabelRequired {
+				ruleIDs = append(ruleIDs, "MESSAGE_SAME_REQUIRED_FIELDS")
+			}
+			b.addAdded(ChangeKindField, message.FullName()+"."+field.Name(), field, field.Location(), ruleIDs, `Field "%d" with name %q was added to message %q.`, number, field.Name(), message.FullName())
+		}
+	}
+	return nil
+}
+
+// addMapEntryChanges adds the changes to the key and value fields of a map
+// entry as changes to the map field the map entry is generated for.
+//
+// The key and value fields have no location, so the location of the map
+// field is used.
+func (b *changeSetBuilder) addMapEntryChanges(previousMapEntry protosource.Message, mapEntry protosource.Message) error {
+	mapField := b.getMapField(mapEntry)
+	if mapField == nil {
+		return nil
+	}
+	previousNumberToField, err := protosource.NumberToMessageField(previousMapEntry)
+	if err != nil {
+		return err
+	}
+	numberToField, err := protosource.NumberToMessageField(mapEntry)
+	if err != nil {
+		return err
+	}
+	numChanges := len(b.changes)
+	for previousNumber, previousField := range previousNumberToField {
+		if field, ok := numberToField[previousNumber]; ok {
+			if err := b.addFieldPairChanges(mapField.Message().FullName()+"."+mapField.Name(), previousField, field); err != nil {
+				return err
+			}
+		}
+	}
+	for _, change := range b.changes[numChanges:] {
+		if change.Location == nil {
+			change.Location = mapField.Location()
+		}
+	}
+	return nil
+}
+
+// addFieldPairChanges adds the changes to a field present in both the
+// previous and current files, with the given name.
+func (b *changeSetBuilder) addFieldPairChanges(name string, previousField protosource.Field, field protosource.Field) error {
+	for _, ruleCheck := range [][]ruleCheck{
+		{newFieldPairRuleCheck("FIELD_SAME_NAME", checkFieldSameName, previousField, field)},
+		{
+			newFieldPairRuleCheck("FIELD_SAME_TYPE", checkFieldSameType, previousField, field),
+			newFieldPairRuleCheck("FIELD_WIRE_JSON_COMPATIBLE_TYPE", checkFieldWireJSONCompatibleType, previousField, field),
+			newFieldPairRuleCheck("FIELD_WIRE_COMPATIBLE_TYPE", checkFieldWireCompatibleType, previousField, field),
+		},
+		{newFieldPairRuleCheck("FIELD_SAME_LABEL", checkFieldSameLabel, previousField, field)},
+		{newFieldPairRuleCheck("FIELD_SAME_ONEOF", checkFieldSameOneof, previousField, field)},
+		{newFieldPairRuleCheck("FIELD_SAME_JSON_NAME", checkFieldSameJSONName, previousField, field)},
+		{newFieldPairRuleCheck("FIELD_SAME_CTYPE", checkFieldSameCType, previousField, field)},
+		{newFieldPairRuleCheck("FIELD_SAME_JSTYPE", checkFieldSameJSType, previousField, field)},
+		{
+			{
+				id: "FIELD_SAME_DEFAULT",
+				check: func(add addFunc) error {
+					return checkFieldSameDefault(add, b.previousFullNameToEnum, b.fullNameToEnum, previousField, field)
+				},
+			},
+		},
+		{newFieldPairRuleCheck("FIELD_SAME_PACKED", checkFieldSamePacked, previousField, field)},
+		{newFieldPairRuleCheck("FIELD_SAME_PRESENCE", checkFieldSamePresence, previousField, field)},
+	} {
+		if err := b.addChanged(ChangeKindField, name, ruleCheck...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getMapField returns the map field the map entry is generated for, or nil if
+// there is no such field.
+func (b *changeSetBuilder) getMapField(mapEntry protosource.Message) protosource.Field {
+	message, ok := b.fullNameToMessage[getParentMessageFullName(mapEntry)]
+	if !ok {
+		return nil
+	}
+	for _, field := range message.Fields() {
+		if strings.TrimPrefix(field.TypeName(), ".") == mapEntry.FullName() {
+			return field
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addOneofChanges(previousMessage protosource.Message, message protosource.Message) error {
+	previousNameToOneof, err := protosource.NameToMessageOneof(previousMessage)
+	if err != nil {
+		return err
+	}
+	nameToOneof, err := protosource.NameToMessageOneof(message)
+	if err != nil {
+		return err
+	}
+	for previousName, previousOneof := range previousNameToOneof {
+		if _, ok := nameToOneof[previousName]; !ok {
+			b.addRemoved(ChangeKindOneof, previousOneof.FullName(), previousOneof, message, []string{"ONEOF_NO_DELETE"}, `Previously present oneof %q on message %q was deleted.`, previousName, previousMessage.FullName())
+		}
+	}
+	for name, oneof := range nameToOneof {
+		if _, ok := previousNameToOneof[name]; !ok {
+			b.addAdded(ChangeKindOneof, oneof.FullName(), oneof, oneof.Location(), nil, `Oneof %q was added to message %q.`, name, message.FullName())
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addEnumChanges() error {
+	for previousFullName, previousEnum := range b.previousFullNameToEnum {
+		enum, ok := b.fullNameToEnum[previousFullName]
+		if !ok {
+			if b.previousParentIsRemoved(previousEnum) {
+				continue
+			}
+			ruleIDs := b.getRemovedRuleIDs(previousEnum, "ENUM_NO_DELETE", "PACKAGE_ENUM_NO_DELETE")
+			b.addRemoved(ChangeKindEnum, previousFullName, previousEnum, b.getParent(previousEnum), ruleIDs, `Previously present enum %q was deleted.`, previousFullName)
+			continue
+		}
+		if err := b.addMovedIfMoved(ChangeKindEnum, "Enum", previousEnum, enum, "ENUM_NO_DELETE"); err != nil {
+			return err
+		}
+		if err := b.addChanged(
+			ChangeKindEnum,
+			previousFullName,
+			ruleCheck{
+				id: "RESERVED_ENUM_NO_DELETE",
+				check: func(add addFunc) error {
+					return checkReservedEnumNoDelete(add, previousEnum, enum)
+				},
+			},
+		); err != nil {
+			return err
+		}
+		if err := b.addEnumValueChanges(previousEnum, enum); err != nil {
+			return err
+		}
+	}
+	for fullName, enum := range b.fullNameToEnum {
+		if _, ok := b.previousFullNameToEnum[fullName]; !ok && !b.parentIsAdded(enum) {
+			b.addAdded(ChangeKindEnum, fullName, enum, enum.Location(), nil, `Enum %q was added.`, fullName)
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addEnumValueChanges(previousEnum protosource.Enum, enum protosource.Enum) error {
+	previousNumberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(previousEnum)
+	if err != nil {
+		return err
+	}
+	numberToNameToEnumValue, err := protosource.NumberToNameToEnumValue(enum)
+	if err != nil {
+		return err
+	}
+	for previousNumber, previousNameToEnumValue := range previousNumberToNameToEnumValue {
+		previousEnumValue := previousNameToEnumValue[getSortedEnumValueNames(previousNameToEnumValue)[0]]
+		nameToEnumValue, ok := numberToNameToEnumValue[previousNumber]
+		if !ok {
+			ruleIDs := []string{"ENUM_VALUE_NO_DELETE"}
+			if !isDeletedEnumValueAllowedWithRules(previousNumber, previousNameToEnumValue, enum, true, false) {
+				ruleIDs = append(ruleIDs, "ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED")
+			}
+			if !isDeletedEnumValueAllowedWithRules(previousNumber, previousNameToEnumValue, enum, false, true) {
+				ruleIDs = append(ruleIDs, "ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED")
+			}
+			b.addRemoved(ChangeKindEnumValue, previousEnumValue.FullName(), previousEnumValue, enum, ruleIDs, `Previously present enum value "%d" on enum %q was deleted.`, previousNumber, previousEnum.FullName())
+			continue
+		}
+		if err := b.addChanged(
+			ChangeKindEnumValue,
+			previousEnumValue.FullName(),
+			ruleCheck{
+				id: "ENUM_VALUE_SAME_NAME",
+				check: func(add addFunc) error {
+					return checkEnumValueSameName(add, previousNameToEnumValue, nameToEnumValue)
+				},
+			},
+		); err != nil {
+			return err
+		}
+	}
+	for number, nameToEnumValue := range numberToNameToEnumValue {
+		if _, ok := previousNumberToNameToEnumValue[number]; !ok {
+			enumValue := nameToEnumValue[getSortedEnumValueNames(nameToEnumValue)[0]]
+			b.addAdded(ChangeKindEnumValue, enumValue.FullName(), enumValue, enumValue.Location(), nil, `Enum value "%d" with name %q was added to enum %q.`, number, enumValue.Name(), enum.FullName())
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addExtensionChanges() error {
+	// the wire rules match extensions by extendee and number, so that renamed
+	// extensions are still compared
+	extensionNumberKeyToExtension := make(map[extensionNumberKey]protosource.Field, len(b.fullNameToExtension))
+	for _, extension := range b.fullNameToExtension {
+		extensionNumberKeyToExtension[getExtensionNumberKey(extension)] = extension
+	}
+	for previousFullName, previousExtension := range b.previousFullNameToExtension {
+		if numberExtension, ok := extensionNumberKeyToExtension[getExtensionNumberKey(previousExtension)]; ok && numberExtension.FullName() != previousFullName {
+			if err := b.addChanged(
+				ChangeKindExtension,
+				previousFullName,
+				newFieldPairRuleCheck("EXTENSION_WIRE_COMPATIBLE_TYPE", checkExtensionWireCompatibleType, previousExtension, numberExtension),
+				newFieldPairRuleCheck("EXTENSION_WIRE_JSON_COMPATIBLE_TYPE", checkExtensionWireJSONCompatibleType, previousExtension, numberExtension),
+			); err != nil {
+				return err
+			}
+		}
+		extension, ok := b.fullNameToExtension[previousFullName]
+		if !ok {
+			if b.previousParentIsRemoved(previousExtension) {
+				continue
+			}
+			ruleIDs := b.getRemovedRuleIDs(previousExtension, "EXTENSION_NO_DELETE", "PACKAGE_EXTENSION_NO_DELETE")
+			b.addRemoved(ChangeKindExtension, previousFullName, previousExtension, b.getParent(previousExtension), ruleIDs, `Previously present extension %q was deleted.`, previousFullName)
+			continue
+		}
+		if err := b.addMovedIfMoved(ChangeKindExtension, "Extension", previousExtension, extension, "EXTENSION_NO_DELETE"); err != nil {
+			return err
+		}
+		for _, ruleCheck := range []ruleCheck{
+			newFieldPairRuleCheck("EXTENSION_SAME_EXTENDEE", checkExtensionSameExtendee, previousExtension, extension),
+			newFieldPairRuleCheck("EXTENSION_SAME_NUMBER", checkExtensionSameNumber, previousExtension, extension),
+		} {
+			if err := b.addChanged(ChangeKindExtension, previousFullName, ruleCheck); err != nil {
+				return err
+			}
+		}
+		ruleChecks := []ruleCheck{
+			newFieldPairRuleCheck("EXTENSION_SAME_TYPE", checkExtensionSameType, previousExtension, extension),
+		}
+		if getExtensionNumberKey(previousExtension) == getExtensionNumberKey(extension) {
+			ruleChecks = append(
+				ruleChecks,
+				newFieldPairRuleCheck("EXTENSION_WIRE_COMPATIBLE_TYPE", checkExtensionWireCompatibleType, previousExtension, extension),
+				newFieldPairRuleCheck("EXTENSION_WIRE_JSON_COMPATIBLE_TYPE", checkExtensionWireJSONCompatibleType, previousExtension, extension),
+			)
+		}
+		if err := b.addChanged(ChangeKindExtension, previousFullName, ruleChecks...); err != nil {
+			return err
+		}
+	}
+	for fullName, extension := range b.fullNameToExtension {
+		if _, ok := b.previousFullNameToExtension[fullName]; !ok && !b.parentIsAdded(extension) {
+			b.addAdded(ChangeKindExtension, fullName, extension, extension.Location(), nil, `Extension %q was added.`, fullName)
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addServiceChanges() error {
+	for previousFullName, previousService := range b.previousFullNameToService {
+		service, ok := b.fullNameToService[previousFullName]
+		if !ok {
+			ruleIDs := b.getRemovedRuleIDs(previousService, "SERVICE_NO_DELETE", "PACKAGE_SERVICE_NO_DELETE")
+			b.addRemoved(ChangeKindService, previousFullName, previousService, nil, ruleIDs, `Previously present service %q was deleted.`, previousFullName)
+			continue
+		}
+		if err := b.addMovedIfMoved(ChangeKindService, "Service", previousService, service, "SERVICE_NO_DELETE"); err != nil {
+			return err
+		}
+		if err := b.addMethodChanges(previousService, service); err != nil {
+			return err
+		}
+	}
+	for fullName, service := range b.fullNameToService {
+		if _, ok := b.previousFullNameToService[fullName]; !ok {
+			b.addAdded(ChangeKindService, fullName, service, service.Location(), nil, `Service %q was added.`, fullName)
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addMethodChanges(previousService protosource.Service, service protosource.Service) error {
+	previousNameToMethod, err := protosource.NameToMethod(previousService)
+	if err != nil {
+		return err
+	}
+	nameToMethod, err := protosource.NameToMethod(service)
+	if err != nil {
+		return err
+	}
+	for previousName, previousMethod := range previousNameToMethod {
+		method, ok := nameToMethod[previousName]
+		if !ok {
+			b.addRemoved(ChangeKindRPC, previousMethod.FullName(), previousMethod, service, []string{"RPC_NO_DELETE"}, `Previously present RPC %q on service %q was deleted.`, previousName, previousService.FullName())
+			continue
+		}
+		for _, ruleCheck := range []ruleCheck{
+			newMethodPairRuleCheck("RPC_SAME_CLIENT_STREAMING", checkRPCSameClientStreaming, previousMethod, method),
+			newMethodPairRuleCheck("RPC_SAME_IDEMPOTENCY_LEVEL", checkRPCSameIdempotencyLevel, previousMethod, method),
+			newMethodPairRuleCheck("RPC_SAME_REQUEST_TYPE", checkRPCSameRequestType, previousMethod, method),
+			newMethodPairRuleCheck("RPC_SAME_RESPONSE_TYPE", checkRPCSameResponseType, previousMethod, method),
+			newMethodPairRuleCheck("RPC_SAME_SERVER_STREAMING", checkRPCSameServerStreaming, previousMethod, method),
+		} {
+			if err := b.addChanged(ChangeKindRPC, previousMethod.FullName(), ruleCheck); err != nil {
+				return err
+			}
+		}
+	}
+	for name, method := range nameToMethod {
+		if _, ok := previousNameToMethod[name]; !ok {
+			b.addAdded(ChangeKindRPC, method.FullName(), method, method.Location(), nil, `RPC %q was added to service %q.`, name, service.FullName())
+		}
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addOptionChanges(comparedOptions []string) error {
+	return forEachOptionValueChange(
+		b.previousFiles,
+		b.files,
+		comparedOptions,
+		func(optionValueChange *optionValueChange) {
+			changeType := ChangeTypeChanged
+			switch {
+			case !optionValueChange.previousOK:
+				changeType = ChangeTypeAdded
+			case !optionValueChange.ok:
+				changeType = ChangeTypeRemoved
+			}
+			name := "(" + optionValueChange.comparedOption + ")"
+			if namedDescriptor, ok := optionValueChange.optionDescriptor.(protosource.NamedDescriptor); ok {
+				name = namedDescriptor.FullName() + "." + name
+			}
+			b.changes = append(
+				b.changes,
+				&Change{
+					Type:       changeType,
+					Kind:       ChangeKindOption,
+					Name:       name,
+					Descriptor: optionValueChange.optionDescriptor,
+					Location:   optionValueChange.location(),
+					Message: fmt.Sprintf(
+						`%s changed option "(%s)" from %s to %s.`,
+						getOptionDescriptorDescription(optionValueChange.optionDescriptor),
+						optionValueChange.comparedOption,
+						optionValueChange.previousValueString(),
+						optionValueChange.valueString(),
+					),
+					RuleIDs: []string{"OPTION_SAME_VALUE"},
+				},
+			)
+		},
+	)
+}
+
+// addChanged adds a change for each failure of the first of the ruleChecks
+// that fails, with the ids of all the ruleChecks that fail.
+//
+// The ruleChecks should all check the same property of a pair of elements,
+// for example different levels of compatibility of field types.
+func (b *changeSetBuilder) addChanged(kind string, name string, ruleChecks ...ruleCheck) error {
+	var ruleIDs []string
+	var recordedFailures []*recordedFailure
+	for _, ruleCheck := range ruleChecks {
+		var ruleRecordedFailures []*recordedFailure
+		if err := ruleCheck.check(newRecordingAddFunc(&ruleRecordedFailures)); err != nil {
+			return err
+		}
+		if len(ruleRecordedFailures) == 0 {
+			continue
+		}
+		ruleIDs = append(ruleIDs, ruleCheck.id)
+		if recordedFailures == nil {
+			recordedFailures = ruleRecordedFailures
+		}
+	}
+	for _, recordedFailure := range recordedFailures {
+		b.changes = append(
+			b.changes,
+			&Change{
+				Type:       ChangeTypeChanged,
+				Kind:       kind,
+				Name:       name,
+				Descriptor: recordedFailure.descriptor,
+				Location:   recordedFailure.location,
+				Message:    recordedFailure.message,
+				RuleIDs:    ruleIDs,
+			},
+		)
+	}
+	return nil
+}
+
+func (b *changeSetBuilder) addAdded(
+	kind string,
+	name string,
+	descriptor protosource.Descriptor,
+	location protosource.Location,
+	ruleIDs []string,
+	format string,
+	args ...interface{},
+) {
+	b.changes = append(
+		b.changes,
+		&Change{
+			Type:       ChangeTypeAdded,
+			Kind:       kind,
+			Name:       name,
+			Descriptor: descriptor,
+			Location:   location,
+			Message:    fmt.Sprintf(format, args...),
+			RuleIDs:    ruleIDs,
+		},
+	)
+}
+
+func (b *changeSetBuilder) addRemoved(
+	kind string,
+	name string,
+	previousDescriptor protosource.Descriptor,
+	parentDescriptor protosource.LocationDescriptor,
+	ruleIDs []string,
+	format string,
+	args ...interface{},
+) {
+	// the removal is reported at the current parent of the element
+	var reportDescriptor protosource.Descriptor
+	var reportLocation protosource.Location
+	if parentDescriptor != nil {
+		reportDescriptor = parentDescriptor
+		reportLocation = parentDescriptor.Location()
+	}
+	b.changes = append(
+		b.changes,
+		&Change{
+			Type:             ChangeTypeRemoved,
+			Kind:             kind,
+			Name:             name,
+			Descriptor:       previousDescriptor,
+			ReportDescriptor: reportDescriptor,
+			ReportLocation:   reportLocation,
+			Message:          fmt.Sprintf(format, args...),
+			RuleIDs:          ruleIDs,
+		},
+	)
+}
+
+// addMovedIfMoved adds a change if the element present in both the previous
+// and current files moved to another file.
+//
+// The no delete rule of the element only detects the move if the previous
+// file still exists, otherwise the deletion of the file is detected.
+func (b *changeSetBuilder) addMovedIfMoved(
+	kind string,
+	description string,
+	previousNamedDescriptor protosource.NamedDescriptor,
+	namedDescriptor protosource.NamedDescriptor,
+	noDeleteRuleID string,
+) error {
+	previousFilePath := previousNamedDescriptor.File().Path()
+	filePath := namedDescriptor.File().Path()
+	if previousFilePath == filePath {
+		return nil
+	}
+	var ruleIDs []string
+	var reportDescriptor protosource.Descriptor
+	var reportLocation protosource.Location
+	if previousPathFile, ok := b.filePathToFile[previousFilePath]; ok {
+		ruleIDs = append(ruleIDs, noDeleteRuleID)
+		// the no delete rule reports the element as deleted from the file it
+		// was previously in
+		nestedNameToMessage, err := protosource.NestedNameToMessage(previousPathFile)
+		if err != nil {
+			return err
+		}
+		reportDescriptor, reportLocation = getDescriptorAndLocationForDeletedMessage(previousPathFile, nestedNameToMessage, previousNamedDescriptor.NestedName())
+	}
+	b.changes = append(
+		b.changes,
+		&Change{
+			Type:             ChangeTypeChanged,
+			Kind:             kind,
+			Name:             namedDescriptor.FullName(),
+			Descriptor:       namedDescriptor,
+			Location:         namedDescriptor.Location(),
+			ReportDescriptor: reportDescriptor,
+			ReportLocation:   reportLocation,
+			Message:          fmt.Sprintf(`%s %q moved from file %q to file %q.`, description, namedDescriptor.FullName(), previousFilePath, filePath),
+			RuleIDs:          ruleIDs,
+		},
+	)
+	return nil
+}
+
+// getRemovedRuleIDs returns the ids of the rules that detect the removal of
+// the element, given the ids of the no delete rules for the file and package
+// of the element.
+func (b *changeSetBuilder) getRemovedRuleIDs(
+	previousNamedDescriptor protosource.NamedDescriptor,
+	noDeleteRuleID string,
+	packageNoDeleteRuleID string,
+) []string {
+	previousFile := previousNamedDescriptor.File()
+	var ruleIDs []string
+	if _, ok := b.filePathToFile[previousFile.Path()]; ok {
+		ruleIDs = append(ruleIDs, noDeleteRuleID)
+	} else {
+		ruleIDs = append(ruleIDs, "FILE_NO_DELETE")
+	}
+	if _, ok := b.packageToFiles[previousFile.Package()]; ok {
+		ruleIDs = append(ruleIDs, packageNoDeleteRuleID)
+	} else {
+		ruleIDs = append(ruleIDs, "PACKAGE_NO_DELETE")
+	}
+	return ruleIDs
+}
+
+// previousParentIsRemoved returns true if the previous message the previous
+// element is nested in was removed, in which case the removal of the
+// element is part of the removal of the message.
+func (b *changeSetBuilder) previousParentIsRemoved(previousNamedDescriptor protosource.NamedDescriptor) bool {
+	parentFullName := getParentMessageFullName(previousNamedDescriptor)
+	if parentFullName == "" {
+		return false
+	}
+	_, ok := b.fullNameToMessage[parentFullName]
+	return !ok
+}
+
+// getParent returns the current message the previous element is nested in,
+// or nil if the element is at the top level of its file or the message was
+// removed.
+func (b *changeSetBuilder) getParent(previousNamedDescriptor protosource.NamedDescriptor) protosource.LocationDescriptor {
+	parentFullName := getParentMessageFullName(previousNamedDescriptor)
+	if parentFullName == "" {
+		return nil
+	}
+	message, ok := b.fullNameToMessage[parentFullName]
+	if !ok {
+		return nil
+	}
+	return message
+}
+
+// parentIsAdded returns true if the message the element is nested in was
+// added, in which case the addition of the element is part of the addition
+// of the message.
+func (b *changeSetBuilder) parentIsAdded(namedDescriptor protosource.NamedDescriptor) bool {
+	parentFullName := getParentMessageFullName(namedDescriptor)
+	if parentFullName == "" {
+		return false
+	}
+	_, ok := b.previousFullNameToMessage[parentFullName]
+	return !ok
+}
+
+// getParentMessageFullName returns the full name of the message the element
+// is nested in, or empty if the element is at the top level of its file.
+func getParentMessageFullName(namedDescriptor protosource.NamedDescriptor) string {
+	fullName := namedDescriptor.FullName()
+	if field, ok := namedDescriptor.(protosource.Field); ok {
+		// extensions are named after the scope they are declared in
+		if message := field.Message(); message != nil {
+			return message.FullName()
+		}
+		return ""
+	}
+	nestedName := namedDescriptor.NestedName()
+	index := strings.LastIndex(nestedName, ".")
+	if index < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(fullName, nestedName[index:])
+}
+
+func newFilePairRuleCheck(
+	id string,
+	check func(addFunc, protosource.File, protosource.File) error,
+	previousFile protosource.File,
+	file protosource.File,
+) ruleCheck {
+	return ruleCheck{
+		id: id,
+		check: func(add addFunc) error {
+			return check(add, previousFile, file)
+		},
+	}
+}
+
+func newMessagePairRuleCheck(
+	id string,
+	check func(addFunc, protosource.Message, protosource.Message) error,
+	previousMessage protosource.Message,
+	message protosource.Message,
+) ruleCheck {
+	return ruleCheck{
+		id: id,
+		check: func(add addFunc) error {
+			return check(add, previousMessage, message)
+		},
+	}
+}
+
+func newFieldPairRuleCheck(
+	id string,
+	check func(addFunc, protosource.Field, protosource.Field) error,
+	previousField protosource.Field,
+	field protosource.Field,
+) ruleCheck {
+	return ruleCheck{
+		id: id,
+		check: func(add addFunc) error {
+			return check(add, previousField, field)
+		},
+	}
+}
+
+func newMethodPairRuleCheck(
+	id string,
+	check func(addFunc, protosource.Method, protosource.Method) error,
+	previousMethod protosource.Method,
+	method protosource.Method,
+) ruleCheck {
+	return ruleCheck{
+		id: id,
+		check: func(add addFunc) error {
+			return check(add, previousMethod, method)
+		},
+	}
+}
+
+func sortChanges(changes []*Change) {
+	sort.SliceStable(
+		changes,
+		func(i int, j int) bool {
+			one := changes[i]
+			two := changes[j]
+			if onePath, twoPath := getChangePath(one), getChangePath(two); onePath != twoPath {
+				return onePath < twoPath
+			}
+			oneStartLine, oneStartColumn := getChangeStart(one)
+			twoStartLine, twoStartColumn := getChangeStart(two)
+			if oneStartLine != twoStartLine {
+				return oneStartLine < twoStartLine
+			}
+			if oneStartColumn != twoStartColumn {
+				return oneStartColumn < twoStartColumn
+			}
+			if one.Kind != two.Kind {
+				return one.Kind < two.Kind
+			}
+			if one.Name != two.Name {
+				return one.Name < two.Name
+			}
+			if one.Type != two.Type {
+				return one.Type < two.Type
+			}
+			return one.Message < two.Message
+		},
+	)
+}
+
+func getChangePath(change *Change) string {
+	if change.Descriptor == nil {
+		return ""
+	}
+	return change.Descriptor.File().Path()
+}
+
+func getChangeStart(change *Change) (int, int) {
+	if change.Location == nil {
+		return 0, 0
+	}
+	return change.Location.StartLine(), change.Location.StartColumn()
+}
@@ -0,0 +1,98 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+)
+
+// fieldTypeToWireEncoding maps each field type to how its values are encoded
+// on the wire.
+//
+// Field types with the same wire encoding are wire compatible. Changes between
+// 64-bit and 32-bit types truncate values just as a cast in C++ would, which is
+// accepted as compatible.
+//
+// See https://developers.google.com/protocol-buffers/docs/proto3#updating.
+var fieldTypeToWireEncoding = map[protosource.FieldDescriptorProtoType]string{
+	protosource.FieldDescriptorProtoTypeInt32:    "varints",
+	protosource.FieldDescriptorProtoTypeInt64:    "varints",
+	protosource.FieldDescriptorProtoTypeUint32:   "varints",
+	protosource.FieldDescriptorProtoTypeUint64:   "varints",
+	protosource.FieldDescriptorProtoTypeBool:     "varints",
+	protosource.FieldDescriptorProtoTypeEnum:     "varints",
+	protosource.FieldDescriptorProtoTypeSint32:   "zigzag-encoded varints",
+	protosource.FieldDescriptorProtoTypeSint64:   "zigzag-encoded varints",
+	protosource.FieldDescriptorProtoTypeFixed32:  "32-bit fixed-width integers",
+	protosource.FieldDescriptorProtoTypeSfixed32: "32-bit fixed-width integers",
+	protosource.FieldDescriptorProtoTypeFixed64:  "64-bit fixed-width integers",
+	protosource.FieldDescriptorProtoTypeSfixed64: "64-bit fixed-width integers",
+	protosource.FieldDescriptorProtoTypeFloat:    "32-bit floating point numbers",
+	protosource.FieldDescriptorProtoTypeDouble:   "64-bit floating point numbers",
+	protosource.FieldDescriptorProtoTypeString:   "length-delimited UTF-8 strings",
+	protosource.FieldDescriptorProtoTypeBytes:    "length-delimited bytes",
+	protosource.FieldDescriptorProtoTypeMessage:  "length-delimited messages",
+	protosource.FieldDescriptorProtoTypeGroup:    "groups delimited by start and end tags",
+}
+
+// fieldTypeToJSONEncoding maps each field type to how its values are encoded
+// in JSON.
+//
+// See https://developers.google.com/protocol-buffers/docs/proto3#json.
+var fieldTypeToJSONEncoding = map[protosource.FieldDescriptorProtoType]string{
+	protosource.FieldDescriptorProtoTypeInt32:    "JSON numbers",
+	protosource.FieldDescriptorProtoTypeUint32:   "JSON numbers",
+	protosource.FieldDescriptorProtoTypeSint32:   "JSON numbers",
+	protosource.FieldDescriptorProtoTypeFixed32:  "JSON numbers",
+	protosource.FieldDescriptorProtoTypeSfixed32: "JSON numbers",
+	protosource.FieldDescriptorProtoTypeFloat:    "JSON numbers",
+	protosource.FieldDescriptorProtoTypeDouble:   "JSON numbers",
+	protosource.FieldDescriptorProtoTypeInt64:    "decimal JSON strings",
+	protosource.FieldDescriptorProtoTypeUint64:   "decimal JSON strings",
+	protosource.FieldDescriptorProtoTypeSint64:   "decimal JSON strings",
+	protosource.FieldDescriptorProtoTypeFixed64:  "decimal JSON strings",
+	protosource.FieldDescriptorProtoTypeSfixed64: "decimal JSON strings",
+	protosource.FieldDescriptorProtoTypeBool:     "JSON booleans",
+	protosource.FieldDescriptorProtoTypeString:   "JSON strings",
+	protosource.FieldDescriptorProtoTypeBytes:    "base64-encoded JSON strings",
+	protosource.FieldDescriptorProtoTypeEnum:     "JSON strings of the enum value names",
+	protosource.FieldDescriptorProtoTypeMessage:  "JSON objects",
+	protosource.FieldDescriptorProtoTypeGroup:    "JSON objects",
+}
+
+// fieldTypesAreWireCompatible returns true if values of previousType can be
+// read as values of fieldType and vice versa.
+func fieldTypesAreWireCompatible(previousType protosource.FieldDescriptorProtoType, fieldType protosource.FieldDescriptorProtoType) bool {
+	if fieldTypeToWireEncoding[previousType] == fieldTypeToWireEncoding[fieldType] {
+		return true
+	}
+	// bytes can hold any length-delimited value, however this is only compatible
+	// with strings if the bytes are valid UTF-8, and only compatible with messages
+	// if the bytes are an encoded message
+	switch {
+	case previousType == protosource.FieldDescriptorProtoTypeBytes:
+		return fieldType == protosource.FieldDescriptorProtoTypeString || fieldType == protosource.FieldDescriptorProtoTypeMessage
+	case fieldType == protosource.FieldDescriptorProtoTypeBytes:
+		return previousType == protosource.FieldDescriptorProtoTypeString || previousType == protosource.FieldDescriptorProtoTypeMessage
+	default:
+		return false
+	}
+}
+
+// fieldTypesAreJSONCompatible returns true if values of previousType are
+// encoded in JSON the same way as values of fieldType.
+func fieldTypesAreJSONCompatible(previousType protosource.FieldDescriptorProtoType, fieldType protosource.FieldDescriptorProtoType) bool {
+	return fieldTypeToJSONEncoding[previousType] == fieldTypeToJSONEncoding[fieldType]
+}
@@ -0,0 +1,382 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufbreakingcheck
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// optionDescriptor is a descriptor that can have custom options set.
+type optionDescriptor interface {
+	protosource.Descriptor
+	protosource.OptionExtensionDescriptor
+}
+
+// optionValueChange is a change of the value of a compared option on a
+// descriptor, where previousOK and ok are false if the option is not set.
+type optionValueChange struct {
+	comparedOption   string
+	optionDescriptor optionDescriptor
+	extensionType    protoreflect.ExtensionType
+	fieldDescriptor  protoreflect.FieldDescriptor
+	previousValue    protoreflect.Value
+	previousOK       bool
+	value            protoreflect.Value
+	ok               bool
+}
+
+func (o *optionValueChange) previousValueString() string {
+	return getOptionValueString(o.fieldDescriptor, o.previousValue, o.previousOK)
+}
+
+func (o *optionValueChange) valueString() string {
+	return getOptionValueString(o.fieldDescriptor, o.value, o.ok)
+}
+
+// location returns the location of the option, or the location of the
+// descriptor if the option was removed.
+func (o *optionValueChange) location() protosource.Location {
+	if location := o.optionDescriptor.OptionExtensionLocation(o.extensionType); location != nil {
+		return location
+	}
+	if locationDescriptor, ok := o.optionDescriptor.(protosource.LocationDescriptor); ok {
+		return locationDescriptor.Location()
+	}
+	return nil
+}
+
+// forEachOptionValueChange calls f for each descriptor present in both the
+// previous and current files on which the value of a compared option changed.
+func forEachOptionValueChange(
+	previousFiles []protosource.File,
+	files []protosource.File,
+	comparedOptions []string,
+	f func(*optionValueChange),
+) error {
+	if len(comparedOptions) == 0 {
+		return nil
+	}
+	previousExtensionTypeResolver, err := protosource.NewExtensionTypeResolver(previousFiles...)
+	if err != nil {
+		return err
+	}
+	extensionTypeResolver, err := protosource.NewExtensionTypeResolver(files...)
+	if err != nil {
+		return err
+	}
+	for _, comparedOption := range comparedOptions {
+		// the same extension type is used to read both the previous and current
+		// values, so that the values can be compared even if only one of the
+		// previous and current files define the extension
+		extensionType, err := findComparedOptionExtensionType(comparedOption, extensionTypeResolver, previousExtensionTypeResolver)
+		if err != nil {
+			return err
+		}
+		if extensionType == nil {
+			return fmt.Errorf("compared option %q is not an extension defined in either the previous or current image", comparedOption)
+		}
+		fieldDescriptor := extensionType.TypeDescriptor()
+		optionsFullName := string(fieldDescriptor.ContainingMessage().FullName())
+		previousKeyToOptionDescriptor, err := getKeyToOptionDescriptor(previousFiles, optionsFullName)
+		if err != nil {
+			return fmt.Errorf("compared option %q: %w", comparedOption, err)
+		}
+		keyToOptionDescriptor, err := getKeyToOptionDescriptor(files, optionsFullName)
+		if err != nil {
+			return fmt.Errorf("compared option %q: %w", comparedOption, err)
+		}
+		for key, previousOptionDescriptor := range previousKeyToOptionDescriptor {
+			optionDescriptor, ok := keyToOptionDescriptor[key]
+			if !ok {
+				continue
+			}
+			previousValue, previousOK := previousOptionDescriptor.OptionExtension(extensionType)
+			value, ok := optionDescriptor.OptionExtension(extensionType)
+			if !optionValuesEqual(fieldDescriptor, previousValue, previousOK, value, ok) {
+				f(
+					&optionValueChange{
+						comparedOption:   comparedOption,
+						optionDescriptor: optionDescriptor,
+						extensionType:    extensionType,
+						fieldDescriptor:  fieldDescriptor,
+						previousValue:    previousValue,
+						previousOK:       previousOK,
+						value:            value,
+						ok:               ok,
+					},
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// findComparedOptionExtensionType finds the extension type of the compared
+// option in the current files, and then in the previous files.
+//
+// Returns nil if the extension is defined in neither.
+func findComparedOptionExtensionType(
+	comparedOption string,
+	extensionTypeResolver protoregistry.ExtensionTypeResolver,
+	previousExtensionTypeResolver protoregistry.ExtensionTypeResolver,
+) (protoreflect.ExtensionType, error) {
+	for _, resolver := range []protoregistry.ExtensionTypeResolver{extensionTypeResolver, previousExtensionTypeResolver} {
+		extensionType, err := resolver.FindExtensionByName(protoreflect.FullName(comparedOption))
+		if err != nil {
+			if errors.Is(err, protoregistry.NotFound) {
+				continue
+			}
+			return nil, err
+		}
+		return extensionType, nil
+	}
+	return nil, nil
+}
+
+// getKeyToOptionDescriptor returns the descriptors of the files that have the
+// options message with the given full name, keyed by what identifies them
+// between the previous and current files.
+func getKeyToOptionDescriptor(files []protosource.File, optionsFullName string) (map[string]optionDescriptor, error) {
+	keyToOptionDescriptor := make(map[string]optionDescriptor)
+	switch optionsFullName {
+	case "google.protobuf.FileOptions":
+		filePathToFile, err := protosource.FilePathToFile(files...)
+		if err != nil {
+			return nil, err
+		}
+		for filePath, file := range filePathToFile {
+			keyToOptionDescriptor[filePath] = file
+		}
+	case "google.protobuf.MessageOptions":
+		fullNameToMessage, err := protosource.FullNameToMessage(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, message := range fullNameToMessage {
+			keyToOptionDescriptor[fullName] = message
+		}
+	case "google.protobuf.FieldOptions":
+		fullNameToMessage, err := protosource.FullNameToMessage(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, message := range fullNameToMessage {
+			numberToField, err := protosource.NumberToMessageField(message)
+			if err != nil {
+				return nil, err
+			}
+			for number, field := range numberToField {
+				// fields are identified by number, as with the other field rules
+				keyToOptionDescriptor[fullName+":"+strconv.Itoa(number)] = field
+			}
+		}
+		fullNameToExtension, err := protosource.FullNameToExtension(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, extension := range fullNameToExtension {
+			keyToOptionDescriptor[fullName] = extension
+		}
+	case "google.protobuf.OneofOptions":
+		fullNameToMessage, err := protosource.FullNameToMessage(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, message := range fullNameToMessage {
+			nameToOneof, err := protosource.NameToMessageOneof(message)
+			if err != nil {
+				return nil, err
+			}
+			for name, oneof := range nameToOneof {
+				keyToOptionDescriptor[fullName+"."+name] = oneof
+			}
+		}
+	case "google.protobuf.EnumOptions":
+		fullNameToEnum, err := protosource.FullNameToEnum(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, enum := range fullNameToEnum {
+			keyToOptionDescriptor[fullName] = enum
+		}
+	case "google.protobuf.EnumValueOptions":
+		fullNameToEnum, err := protosource.FullNameToEnum(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, enum := range fullNameToEnum {
+			nameToEnumValue, err := protosource.NameToEnumValue(enum)
+			if err != nil {
+				return nil, err
+			}
+			for name, enumValue := range nameToEnumValue {
+				keyToOptionDescriptor[fullName+"."+name] = enumValue
+			}
+		}
+	case "google.protobuf.ServiceOptions":
+		fullNameToService, err := protosource.FullNameToService(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, service := range fullNameToService {
+			keyToOptionDescriptor[fullName] = service
+		}
+	case "google.protobuf.MethodOptions":
+		fullNameToMethod, err := protosource.FullNameToMethod(files...)
+		if err != nil {
+			return nil, err
+		}
+		for fullName, method := range fullNameToMethod {
+			keyToOptionDescriptor[fullName] = method
+		}
+	default:
+		return nil, fmt.Errorf("unknown options message %q", optionsFullName)
+	}
+	return keyToOptionDescriptor, nil
+}
+
+// getOptionDescriptorDescription returns the description of the descriptor
+// to use in messages.
+func getOptionDescriptorDescription(descriptor optionDescriptor) string {
+	switch t := descriptor.(type) {
+	case protosource.File:
+		return "File"
+	case protosource.Message:
+		return fmt.Sprintf("Message %q", t.Name())
+	case protosource.Field:
+		if t.Extendee() != "" {
+			return fmt.Sprintf("Extension %q", t.Name())
+		}
+		return fmt.Sprintf("Field %q on message %q", t.Name(), t.Message().Name())
+	case protosource.Oneof:
+		return fmt.Sprintf("Oneof %q on message %q", t.Name(), t.Message().Name())
+	case protosource.Enum:
+		return fmt.Sprintf("Enum %q", t.Name())
+	case protosource.EnumValue:
+		return fmt.Sprintf("Enum value %q on enum %q", t.Name(), t.Enum().Name())
+	case protosource.Service:
+		return fmt.Sprintf("Service %q", t.Name())
+	case protosource.Method:
+		return fmt.Sprintf("RPC %q on service %q", t.Name(), t.Service().Name())
+	default:
+		return "Element"
+	}
+}
+
+// optionValuesEqual returns true if the values of the option are equal,
+// where ok is false if the option is not set.
+func optionValuesEqual(
+	fieldDescriptor protoreflect.FieldDescriptor,
+	previousValue protoreflect.Value,
+	previousOK bool,
+	value protoreflect.Value,
+	ok bool,
+) bool {
+	if !previousOK || !ok {
+		return previousOK == ok
+	}
+	if fieldDescriptor.IsList() {
+		previousList := previousValue.List()
+		list := value.List()
+		if previousList.Len() != list.Len() {
+			return false
+		}
+		for i := 0; i < list.Len(); i++ {
+			if !singularOptionValuesEqual(fieldDescriptor, previousList.Get(i), list.Get(i)) {
+				return false
+			}
+		}
+		return true
+	}
+	return singularOptionValuesEqual(fieldDescriptor, previousValue, value)
+}
+
+func singularOptionValuesEqual(fieldDescriptor protoreflect.FieldDescriptor, previousValue protoreflect.Value, value protoreflect.Value) bool {
+	switch fieldDescriptor.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return proto.Equal(previousValue.Message().Interface(), value.Message().Interface())
+	case protoreflect.BytesKind:
+		return bytes.Equal(previousValue.Bytes(), value.Bytes())
+	default:
+		return previousValue.Interface() == value.Interface()
+	}
+}
+
+// getOptionValueString returns the value of the option to use in messages,
+// where ok is false if the option is not set.
+//
+// Message fields are printed in field order so that messages are stable.
+func getOptionValueString(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value, ok bool) string {
+	if !ok {
+		return "unset"
+	}
+	if fieldDescriptor.IsList() {
+		list := value.List()
+		elements := make([]string, list.Len())
+		for i := 0; i < list.Len(); i++ {
+			elements[i] = getSingularOptionValueString(fieldDescriptor, list.Get(i))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	}
+	if fieldDescriptor.IsMap() {
+		var entries []string
+		value.Map().Range(
+			func(key protoreflect.MapKey, value protoreflect.Value) bool {
+				entries = append(entries, getSingularOptionValueString(fieldDescriptor.MapKey(), key.Value())+": "+getSingularOptionValueString(fieldDescriptor.MapValue(), value))
+				return true
+			},
+		)
+		sort.Strings(entries)
+		return "{" + strings.Join(entries, ", ") + "}"
+	}
+	return getSingularOptionValueString(fieldDescriptor, value)
+}
+
+func getSingularOptionValueString(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch fieldDescriptor.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		message := value.Message()
+		fieldDescriptors := message.Descriptor().Fields()
+		var fields []string
+		for i := 0; i < fieldDescriptors.Len(); i++ {
+			fieldDescriptor := fieldDescriptors.Get(i)
+			if message.Has(fieldDescriptor) {
+				fields = append(fields, string(fieldDescriptor.Name())+": "+getOptionValueString(fieldDescriptor, message.Get(fieldDescriptor), true))
+			}
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case protoreflect.EnumKind:
+		if enumValueDescriptor := fieldDescriptor.Enum().Values().ByNumber(value.Enum()); enumValueDescriptor != nil {
+			return string(enumValueDescriptor.Name())
+		}
+		return strconv.Itoa(int(value.Enum()))
+	case protoreflect.StringKind:
+		return strconv.Quote(value.String())
+	case protoreflect.BytesKind:
+		return strconv.Quote(string(value.Bytes()))
+	default:
+		return value.String()
+	}
+}
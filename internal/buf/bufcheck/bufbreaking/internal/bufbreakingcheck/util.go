@@ -15,7 +15,10 @@
 package bufbreakingcheck
 
 import (
+	"math"
+	"math/big"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
@@ -167,6 +170,107 @@ func newFieldPairCheckFunc(
 	)
 }
 
+// newFieldPairWithEnumsCheckFunc is like newFieldPairCheckFunc, but also passes
+// maps from full name to Enum for the previous and current files, so that the
+// enums of enum fields can be resolved.
+func newFieldPairWithEnumsCheckFunc(
+	f func(addFunc, map[string]protosource.Enum, map[string]protosource.Enum, protosource.Field, protosource.Field) error,
+) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return func(id string, ignoreFunc internal.IgnoreFunc, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+		previousFullNameToEnum, err := protosource.FullNameToEnum(previousFiles...)
+		if err != nil {
+			return nil, err
+		}
+		fullNameToEnum, err := protosource.FullNameToEnum(files...)
+		if err != nil {
+			return nil, err
+		}
+		return newFieldPairCheckFunc(
+			func(add addFunc, previousField protosource.Field, field protosource.Field) error {
+				return f(add, previousFullNameToEnum, fullNameToEnum, previousField, field)
+			},
+		)(id, ignoreFunc, previousFiles, files)
+	}
+}
+
+func newExtensionPairCheckFunc(
+	f func(addFunc, protosource.Field, protosource.Field) error,
+) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesCheckFunc(
+		func(add addFunc, previousFiles []protosource.File, files []protosource.File) error {
+			previousFullNameToExtension, err := protosource.FullNameToExtension(previousFiles...)
+			if err != nil {
+				return err
+			}
+			fullNameToExtension, err := protosource.FullNameToExtension(files...)
+			if err != nil {
+				return err
+			}
+			for previousFullName, previousExtension := range previousFullNameToExtension {
+				if extension, ok := fullNameToExtension[previousFullName]; ok {
+					if err := f(add, previousExtension, extension); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// newExtensionNumberPairCheckFunc calls f for each pair of extensions that
+// extend the same message with the same number, regardless of their names, as
+// only the extendee and number of an extension are on the wire.
+func newExtensionNumberPairCheckFunc(
+	f func(addFunc, protosource.Field, protosource.Field) error,
+) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesCheckFunc(
+		func(add addFunc, previousFiles []protosource.File, files []protosource.File) error {
+			previousExtensionNumberKeyToExtension, err := getExtensionNumberKeyToExtension(previousFiles)
+			if err != nil {
+				return err
+			}
+			extensionNumberKeyToExtension, err := getExtensionNumberKeyToExtension(files)
+			if err != nil {
+				return err
+			}
+			for previousExtensionNumberKey, previousExtension := range previousExtensionNumberKeyToExtension {
+				if extension, ok := extensionNumberKeyToExtension[previousExtensionNumberKey]; ok {
+					if err := f(add, previousExtension, extension); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// extensionNumberKey identifies an extension on the wire.
+type extensionNumberKey struct {
+	extendee string
+	number   int
+}
+
+func getExtensionNumberKeyToExtension(files []protosource.File) (map[extensionNumberKey]protosource.Field, error) {
+	fullNameToExtension, err := protosource.FullNameToExtension(files...)
+	if err != nil {
+		return nil, err
+	}
+	extensionNumberKeyToExtension := make(map[extensionNumberKey]protosource.Field, len(fullNameToExtension))
+	for _, extension := range fullNameToExtension {
+		extensionNumberKeyToExtension[getExtensionNumberKey(extension)] = extension
+	}
+	return extensionNumberKeyToExtension, nil
+}
+
+func getExtensionNumberKey(extension protosource.Field) extensionNumberKey {
+	return extensionNumberKey{
+		extendee: strings.TrimPrefix(extension.Extendee(), "."),
+		number:   extension.Number(),
+	}
+}
+
 func newServicePairCheckFunc(
 	f func(addFunc, protosource.Service, protosource.Service) error,
 ) func(string, internal.IgnoreFunc, []protosource.File, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
@@ -260,3 +364,135 @@ func withBackupLocation(primary protosource.Location, secondary protosource.Loca
 	}
 	return secondary
 }
+
+// fieldCanHaveDefault returns true if the field is a singular scalar or enum
+// field, which are the only fields that have default values.
+func fieldCanHaveDefault(field protosource.Field) bool {
+	if field.Label() == protosource.FieldDescriptorProtoLabelRepeated {
+		return false
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeMessage, protosource.FieldDescriptorProtoTypeGroup:
+		return false
+	default:
+		return true
+	}
+}
+
+// getFieldDefault returns the default value of the field as it would appear in
+// default_value, including the implicit default if no default is set.
+//
+// The implicit default of an enum field is the first value of the enum, so
+// false is returned if the enum cannot be resolved.
+func getFieldDefault(field protosource.Field, fullNameToEnum map[string]protosource.Enum) (string, bool) {
+	// proto3 does not allow explicit defaults, so this is always nil for proto3 fields
+	if defaultValue := field.DefaultValue(); defaultValue != nil {
+		return *defaultValue, true
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeString, protosource.FieldDescriptorProtoTypeBytes:
+		return "", true
+	case protosource.FieldDescriptorProtoTypeBool:
+		return "false", true
+	case protosource.FieldDescriptorProtoTypeEnum:
+		enum, ok := fullNameToEnum[strings.TrimPrefix(field.TypeName(), ".")]
+		if !ok || len(enum.Values()) == 0 {
+			return "", false
+		}
+		return enum.Values()[0].Name(), true
+	default:
+		return "0", true
+	}
+}
+
+// fieldDefaultsEqual returns true if the default values are equal for the
+// given type, so that for example "1" and "1.0" are equal for doubles.
+func fieldDefaultsEqual(fieldType protosource.FieldDescriptorProtoType, previousDefault string, currentDefault string) bool {
+	if previousDefault == currentDefault {
+		return true
+	}
+	switch fieldType {
+	case protosource.FieldDescriptorProtoTypeFloat, protosource.FieldDescriptorProtoTypeDouble:
+		previousFloat, err := strconv.ParseFloat(previousDefault, 64)
+		if err != nil {
+			return false
+		}
+		currentFloat, err := strconv.ParseFloat(currentDefault, 64)
+		if err != nil {
+			return false
+		}
+		if math.IsNaN(previousFloat) && math.IsNaN(currentFloat) {
+			return true
+		}
+		return previousFloat == currentFloat
+	case protosource.FieldDescriptorProtoTypeString, protosource.FieldDescriptorProtoTypeBytes,
+		protosource.FieldDescriptorProtoTypeBool, protosource.FieldDescriptorProtoTypeEnum:
+		return false
+	default:
+		previousInt, ok := new(big.Int).SetString(previousDefault, 0)
+		if !ok {
+			return false
+		}
+		currentInt, ok := new(big.Int).SetString(currentDefault, 0)
+		if !ok {
+			return false
+		}
+		return previousInt.Cmp(currentInt) == 0
+	}
+}
+
+// fieldCanBePacked returns true if the field is a repeated scalar numeric, bool,
+// or enum field, which are the only fields that can use packed encoding.
+func fieldCanBePacked(field protosource.Field) bool {
+	if field.Label() != protosource.FieldDescriptorProtoLabelRepeated {
+		return false
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeString, protosource.FieldDescriptorProtoTypeBytes,
+		protosource.FieldDescriptorProtoTypeMessage, protosource.FieldDescriptorProtoTypeGroup:
+		return false
+	default:
+		return true
+	}
+}
+
+// fieldIsPacked returns true if the field uses packed encoding.
+//
+// Fields that can be packed are packed by default in proto3, and not packed by
+// default in proto2, unless the packed option is set.
+func fieldIsPacked(field protosource.Field) bool {
+	if packed := field.Packed(); packed != nil {
+		return *packed
+	}
+	return field.File().Syntax() == protosource.SyntaxProto3
+}
+
+func getPackedString(packed bool) string {
+	if packed {
+		return "packed"
+	}
+	return "unpacked"
+}
+
+// fieldHasPresence returns true if the singular field has explicit presence,
+// that is if it can tell if it was set to its default value or not set at all.
+//
+// All singular proto2 fields have explicit presence. In proto3, only message
+// fields, fields in a oneof, and optional fields have explicit presence.
+func fieldHasPresence(field protosource.Field) bool {
+	if field.File().Syntax() == protosource.SyntaxProto2 {
+		return true
+	}
+	switch field.Type() {
+	case protosource.FieldDescriptorProtoTypeMessage, protosource.FieldDescriptorProtoTypeGroup:
+		return true
+	}
+	return field.Oneof() != nil || field.Proto3Optional()
+}
+
+func getPresenceString(hasPresence bool) string {
+	if hasPresence {
+		return "explicit"
+	}
+	return "implicit"
+}
@@ -17,7 +17,10 @@
 // It uses bufbreakingfunc and bufbreakingbuild.
 package bufbreakingv1beta1
 
-import "github.com/bufbuild/buf/internal/buf/bufcheck/internal"
+import (
+	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
+	checkv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/check/v1alpha1"
+)
 
 // VersionSpec is the version specification for v1beta1.
 var VersionSpec = &internal.VersionSpec{
@@ -25,4 +28,5 @@ var VersionSpec = &internal.VersionSpec{
 	DefaultCategories: v1beta1DefaultCategories,
 	AllCategories:     v1beta1AllCategories,
 	IDToCategories:    v1beta1IDToCategories,
+	RuleType:          checkv1alpha1.RuleType_RULE_TYPE_BREAKING,
 }
@@ -28,16 +28,27 @@ var (
 		bufbreakingbuild.EnumValueNoDeleteUnlessNumberReservedRuleBuilder,
 		bufbreakingbuild.EnumValueSameNameRuleBuilder,
 		bufbreakingbuild.ExtensionMessageNoDeleteRuleBuilder,
+		bufbreakingbuild.ExtensionNoDeleteRuleBuilder,
+		bufbreakingbuild.ExtensionSameExtendeeRuleBuilder,
+		bufbreakingbuild.ExtensionSameNumberRuleBuilder,
+		bufbreakingbuild.ExtensionSameTypeRuleBuilder,
+		bufbreakingbuild.ExtensionWireCompatibleTypeRuleBuilder,
+		bufbreakingbuild.ExtensionWireJSONCompatibleTypeRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNameReservedRuleBuilder,
 		bufbreakingbuild.FieldNoDeleteUnlessNumberReservedRuleBuilder,
 		bufbreakingbuild.FieldSameCTypeRuleBuilder,
+		bufbreakingbuild.FieldSameDefaultRuleBuilder,
 		bufbreakingbuild.FieldSameJSONNameRuleBuilder,
 		bufbreakingbuild.FieldSameJSTypeRuleBuilder,
 		bufbreakingbuild.FieldSameLabelRuleBuilder,
 		bufbreakingbuild.FieldSameNameRuleBuilder,
 		bufbreakingbuild.FieldSameOneofRuleBuilder,
+		bufbreakingbuild.FieldSamePackedRuleBuilder,
+		bufbreakingbuild.FieldSamePresenceRuleBuilder,
 		bufbreakingbuild.FieldSameTypeRuleBuilder,
+		bufbreakingbuild.FieldWireCompatibleTypeRuleBuilder,
+		bufbreakingbuild.FieldWireJSONCompatibleTypeRuleBuilder,
 		bufbreakingbuild.FileNoDeleteRuleBuilder,
 		bufbreakingbuild.FileSameCsharpNamespaceRuleBuilder,
 		bufbreakingbuild.FileSameGoPackageRuleBuilder,
@@ -64,7 +75,9 @@ var (
 		bufbreakingbuild.MessageSameMessageSetWireFormatRuleBuilder,
 		bufbreakingbuild.MessageSameRequiredFieldsRuleBuilder,
 		bufbreakingbuild.OneofNoDeleteRuleBuilder,
+		bufbreakingbuild.OptionSameValueRuleBuilder,
 		bufbreakingbuild.PackageEnumNoDeleteRuleBuilder,
+		bufbreakingbuild.PackageExtensionNoDeleteRuleBuilder,
 		bufbreakingbuild.PackageMessageNoDeleteRuleBuilder,
 		bufbreakingbuild.PackageNoDeleteRuleBuilder,
 		bufbreakingbuild.PackageServiceNoDeleteRuleBuilder,
@@ -115,6 +128,31 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"EXTENSION_NO_DELETE": {
+			"FILE",
+		},
+		"EXTENSION_SAME_EXTENDEE": {
+			"FILE",
+			"PACKAGE",
+			"WIRE_JSON",
+			"WIRE",
+		},
+		"EXTENSION_SAME_NUMBER": {
+			"FILE",
+			"PACKAGE",
+			"WIRE_JSON",
+			"WIRE",
+		},
+		"EXTENSION_SAME_TYPE": {
+			"FILE",
+			"PACKAGE",
+		},
+		"EXTENSION_WIRE_COMPATIBLE_TYPE": {
+			"WIRE",
+		},
+		"EXTENSION_WIRE_JSON_COMPATIBLE_TYPE": {
+			"WIRE_JSON",
+		},
 		"FIELD_NO_DELETE": {
 			"FILE",
 			"PACKAGE",
@@ -130,6 +168,12 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"FIELD_SAME_DEFAULT": {
+			"FILE",
+			"PACKAGE",
+			"WIRE_JSON",
+			"WIRE",
+		},
 		"FIELD_SAME_JSON_NAME": {
 			"FILE",
 			"PACKAGE",
@@ -156,12 +200,24 @@ var (
 			"WIRE_JSON",
 			"WIRE",
 		},
+		"FIELD_SAME_PACKED": {
+			"FILE",
+			"PACKAGE",
+		},
+		"FIELD_SAME_PRESENCE": {
+			"FILE",
+			"PACKAGE",
+		},
 		"FIELD_SAME_TYPE": {
 			"FILE",
 			"PACKAGE",
-			"WIRE_JSON",
+		},
+		"FIELD_WIRE_COMPATIBLE_TYPE": {
 			"WIRE",
 		},
+		"FIELD_WIRE_JSON_COMPATIBLE_TYPE": {
+			"WIRE_JSON",
+		},
 		"FILE_NO_DELETE": {
 			"FILE",
 		},
@@ -267,9 +323,18 @@ var (
 			"FILE",
 			"PACKAGE",
 		},
+		"OPTION_SAME_VALUE": {
+			"FILE",
+			"PACKAGE",
+			"WIRE_JSON",
+			"WIRE",
+		},
 		"PACKAGE_ENUM_NO_DELETE": {
 			"PACKAGE",
 		},
+		"PACKAGE_EXTENSION_NO_DELETE": {
+			"PACKAGE",
+		},
 		"PACKAGE_MESSAGE_NO_DELETE": {
 			"PACKAGE",
 		},
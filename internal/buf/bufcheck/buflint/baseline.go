@@ -0,0 +1,319 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/internal/pkg/encoding"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+)
+
+const baselineVersion = "v1"
+
+// Baseline is a set of FileAnnotations that are known to exist.
+//
+// FileAnnotations are identified by their rule ID, path, and the fully-qualified
+// name of the symbol that they are on, as opposed to by their location, so that
+// unrelated edits to a file do not invalidate a Baseline.
+type Baseline struct {
+	Version string           `json:"version,omitempty" yaml:"version,omitempty"`
+	Entries []*BaselineEntry `json:"entries,omitempty" yaml:"entries,omitempty"`
+}
+
+// BaselineEntry is an entry in a Baseline.
+type BaselineEntry struct {
+	BaselineKey `yaml:",inline"`
+	// Count is the number of FileAnnotations with the BaselineKey.
+	Count int `json:"count,omitempty" yaml:"count,omitempty"`
+}
+
+// String returns the string representation of the BaselineEntry.
+func (b *BaselineEntry) String() string {
+	s := b.BaselineKey.String()
+	if b.Count > 1 {
+		s = fmt.Sprintf("%s (x%d)", s, b.Count)
+	}
+	return s
+}
+
+// BaselineKey identifies a FileAnnotation within a Baseline.
+type BaselineKey struct {
+	ID   string `json:"id,omitempty" yaml:"id,omitempty"`
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Symbol is the fully-qualified name of the innermost named descriptor
+	// that contains the FileAnnotation.
+	//
+	// This is empty for FileAnnotations that are not within a named descriptor,
+	// for example FileAnnotations on the package declaration.
+	Symbol string `json:"symbol,omitempty" yaml:"symbol,omitempty"`
+}
+
+// String returns the string representation of the BaselineKey.
+func (b BaselineKey) String() string {
+	if b.Symbol == "" {
+		return b.Path + ":" + b.ID
+	}
+	return b.Path + ":" + b.Symbol + ":" + b.ID
+}
+
+// GetBaselineKeys returns the BaselineKeys for the FileAnnotations, in the
+// same order as the FileAnnotations.
+//
+// The FileAnnotations must have been returned from Handler.Check for the image.
+func GetBaselineKeys(
+	ctx context.Context,
+	image bufimage.Image,
+	fileAnnotations []bufanalysis.FileAnnotation,
+) ([]BaselineKey, error) {
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return nil, err
+	}
+	filePathToFile, err := protosource.FilePathToFile(files...)
+	if err != nil {
+		return nil, err
+	}
+	baselineKeys := make([]BaselineKey, len(fileAnnotations))
+	for i, fileAnnotation := range fileAnnotations {
+		baselineKey := BaselineKey{
+			ID: fileAnnotation.Type(),
+		}
+		if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+			baselineKey.Path = fileInfo.Path()
+			if file, ok := filePathToFile[fileInfo.Path()]; ok {
+				baselineKey.Symbol = getBaselineSymbol(file, fileAnnotation)
+			}
+		}
+		baselineKeys[i] = baselineKey
+	}
+	return baselineKeys, nil
+}
+
+// NewBaseline returns a new Baseline for the BaselineKeys.
+func NewBaseline(baselineKeys []BaselineKey) *Baseline {
+	baselineKeyToCount := make(map[BaselineKey]int)
+	for _, baselineKey := range baselineKeys {
+		baselineKeyToCount[baselineKey]++
+	}
+	baseline := &Baseline{
+		Version: baselineVersion,
+		Entries: make([]*BaselineEntry, 0, len(baselineKeyToCount)),
+	}
+	for baselineKey, count := range baselineKeyToCount {
+		baseline.Entries = append(
+			baseline.Entries,
+			&BaselineEntry{
+				BaselineKey: baselineKey,
+				Count:       count,
+			},
+		)
+	}
+	sortBaselineEntries(baseline.Entries)
+	return baseline
+}
+
+// ReadBaseline reads a Baseline.
+func ReadBaseline(reader io.Reader) (*Baseline, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	baseline := &Baseline{}
+	if err := encoding.UnmarshalYAMLStrict(data, baseline); err != nil {
+		return nil, fmt.Errorf("could not read baseline: %v", err)
+	}
+	switch baseline.Version {
+	case baselineVersion:
+	case "":
+		return nil, fmt.Errorf("baseline version is required")
+	default:
+		return nil, fmt.Errorf("unknown baseline version: %q", baseline.Version)
+	}
+	for _, entry := range baseline.Entries {
+		if entry.ID == "" {
+			return nil, fmt.Errorf("baseline entry for path %q has no id", entry.Path)
+		}
+		if entry.Count < 1 {
+			entry.Count = 1
+		}
+	}
+	return baseline, nil
+}
+
+// WriteBaseline writes the Baseline.
+func WriteBaseline(writer io.Writer, baseline *Baseline) error {
+	data, err := encoding.MarshalYAML(baseline)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// Filter returns the FileAnnotations that are not in the Baseline, and the
+// BaselineEntries that no longer match any FileAnnotation.
+//
+// The BaselineKeys must be the BaselineKeys of the FileAnnotations, as returned
+// from GetBaselineKeys.
+//
+// Only BaselineEntries for the given paths can be stale, so that checking a
+// subset of files does not make the BaselineEntries for the other files stale.
+// If an entry only partially matches, the returned stale BaselineEntry has the
+// Count of FileAnnotations that no longer exist.
+func (b *Baseline) Filter(
+	fileAnnotations []bufanalysis.FileAnnotation,
+	baselineKeys []BaselineKey,
+	paths map[string]struct{},
+) ([]bufanalysis.FileAnnotation, []*BaselineEntry, error) {
+	if len(fileAnnotations) != len(baselineKeys) {
+		return nil, nil, fmt.Errorf("got %d FileAnnotations but %d BaselineKeys", len(fileAnnotations), len(baselineKeys))
+	}
+	baselineKeyToRemainingCount := make(map[BaselineKey]int, len(b.Entries))
+	for _, entry := range b.Entries {
+		baselineKeyToRemainingCount[entry.BaselineKey] += entry.Count
+	}
+	var newFileAnnotations []bufanalysis.FileAnnotation
+	for i, fileAnnotation := range fileAnnotations {
+		if baselineKeyToRemainingCount[baselineKeys[i]] > 0 {
+			baselineKeyToRemainingCount[baselineKeys[i]]--
+			continue
+		}
+		newFileAnnotations = append(newFileAnnotations, fileAnnotation)
+	}
+	var staleEntries []*BaselineEntry
+	for baselineKey, remainingCount := range baselineKeyToRemainingCount {
+		if remainingCount == 0 {
+			continue
+		}
+		if _, ok := paths[baselineKey.Path]; !ok {
+			continue
+		}
+		staleEntries = append(
+			staleEntries,
+			&BaselineEntry{
+				BaselineKey: baselineKey,
+				Count:       remainingCount,
+			},
+		)
+	}
+	sortBaselineEntries(staleEntries)
+	return newFileAnnotations, staleEntries, nil
+}
+
+// getBaselineSymbol returns the full name of the innermost named descriptor
+// whose location contains the start of the FileAnnotation.
+func getBaselineSymbol(file protosource.File, fileAnnotation bufanalysis.FileAnnotation) string {
+	if fileAnnotation.StartLine() == 0 {
+		return ""
+	}
+	var symbol string
+	var symbolLocation protosource.Location
+	visit := func(namedDescriptor protosource.NamedDescriptor) {
+		location := namedDescriptor.Location()
+		if location == nil || !locationContains(location, fileAnnotation.StartLine(), fileAnnotation.StartColumn()) {
+			return
+		}
+		// named descriptors are either nested or disjoint, so the containing
+		// location that starts last is the innermost
+		if symbolLocation == nil || locationStartsBefore(symbolLocation, location) {
+			symbol = namedDescriptor.FullName()
+			symbolLocation = location
+		}
+	}
+	_ = protosource.ForEachEnum(
+		func(enum protosource.Enum) error {
+			visit(enum)
+			for _, enumValue := range enum.Values() {
+				visit(enumValue)
+			}
+			return nil
+		},
+		file,
+	)
+	_ = protosource.ForEachMessage(
+		func(message protosource.Message) error {
+			visit(message)
+			for _, field := range message.Fields() {
+				visit(field)
+			}
+			for _, extension := range message.Extensions() {
+				visit(extension)
+			}
+			for _, oneof := range message.Oneofs() {
+				visit(oneof)
+			}
+			return nil
+		},
+		file,
+	)
+	for _, service := range file.Services() {
+		visit(service)
+		for _, method := range service.Methods() {
+			visit(method)
+		}
+	}
+	return symbol
+}
+
+// locationContains returns true if the line and column are within the location.
+//
+// A column of 0 means that the column is not known, in which case only the
+// line is checked.
+func locationContains(location protosource.Location, line int, column int) bool {
+	if line < location.StartLine() || line > location.EndLine() {
+		return false
+	}
+	if column == 0 {
+		return true
+	}
+	if line == location.StartLine() && column < location.StartColumn() {
+		return false
+	}
+	if line == location.EndLine() && column > location.EndColumn() {
+		return false
+	}
+	return true
+}
+
+func locationStartsBefore(one protosource.Location, two protosource.Location) bool {
+	if one.StartLine() != two.StartLine() {
+		return one.StartLine() < two.StartLine()
+	}
+	return one.StartColumn() < two.StartColumn()
+}
+
+func sortBaselineEntries(baselineEntries []*BaselineEntry) {
+	sort.Slice(
+		baselineEntries,
+		func(i int, j int) bool {
+			one := baselineEntries[i]
+			two := baselineEntries[j]
+			if one.Path != two.Path {
+				return one.Path < two.Path
+			}
+			if one.Symbol != two.Symbol {
+				return one.Symbol < two.Symbol
+			}
+			return one.ID < two.ID
+		},
+	)
+}
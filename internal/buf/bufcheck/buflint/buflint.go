@@ -44,7 +44,8 @@ type Handler interface {
 	//
 	// The image should have source code info for this to work properly.
 	//
-	// Images should be filtered with regards to imports before passing to this function.
+	// Only the non-import files of the image are checked. Imports should be kept in the
+	// image so that references to them can be resolved.
 	Check(
 		ctx context.Context,
 		config *Config,
@@ -73,7 +74,21 @@ type Config struct {
 	Rules               []Rule
 	IgnoreIDToRootPaths map[string]map[string]struct{}
 	IgnoreRootPaths     map[string]struct{}
+	IDToSeverity        map[string]bufanalysis.Severity
 	AllowCommentIgnores bool
+	// EnumZeroValueSuffix and ServiceSuffix are the suffixes used by the
+	// ENUM_ZERO_VALUE_SUFFIX and SERVICE_SUFFIX rules, and by Fix.
+	EnumZeroValueSuffix string
+	ServiceSuffix       string
+
+	// pluginConfigBuilder is set if the config has plugins, whose rules are
+	// only added by LoadPlugins.
+	pluginConfigBuilder *internal.ConfigBuilder
+}
+
+// HasPlugins returns true if the config has plugins.
+func (c *Config) HasPlugins() bool {
+	return c.pluginConfigBuilder != nil
 }
 
 // GetRules returns the rules.
@@ -83,20 +98,46 @@ func (c *Config) GetRules() []bufcheck.Rule {
 
 // NewConfigV1Beta1 returns a new Config.
 func NewConfigV1Beta1(externalConfig ExternalConfigV1Beta1) (*Config, error) {
-	internalConfig, err := internal.ConfigBuilder{
+	configBuilder := internal.ConfigBuilder{
 		Use:                                  externalConfig.Use,
 		Except:                               externalConfig.Except,
 		IgnoreRootPaths:                      externalConfig.Ignore,
 		IgnoreIDOrCategoryToRootPaths:        externalConfig.IgnoreOnly,
+		IDOrCategoryToSeverity:               externalConfig.Severity,
 		AllowCommentIgnores:                  externalConfig.AllowCommentIgnores,
+		CommentMinLength:                     externalConfig.CommentMinLength,
+		EnumValueNumberRanges:                externalNumberRangePolicyToInternal(externalConfig.EnumValueNumberRanges),
 		EnumZeroValueSuffix:                  externalConfig.EnumZeroValueSuffix,
+		FieldNumberMaxGap:                    externalConfig.FieldNumberMaxGap,
+		FieldNumberRanges:                    externalNumberRangePolicyToInternal(externalConfig.FieldNumberRanges),
 		RPCAllowSameRequestResponse:          externalConfig.RPCAllowSameRequestResponse,
 		RPCAllowGoogleProtobufEmptyRequests:  externalConfig.RPCAllowGoogleProtobufEmptyRequests,
 		RPCAllowGoogleProtobufEmptyResponses: externalConfig.RPCAllowGoogleProtobufEmptyResponses,
 		ServiceSuffix:                        externalConfig.ServiceSuffix,
-	}.NewConfig(
-		buflintv1beta1.VersionSpec,
-	)
+		PackageImportRestrictions:            externalPackageImportRestrictionsToInternal(externalConfig.PackageImportRestrictions),
+		RequiredOptions:                      externalRequiredOptionsToInternal(externalConfig.RequireOptions),
+		Plugins:                              internal.ExternalPluginsV1Beta1ToPlugins(externalConfig.Plugins),
+	}
+	internalConfig, err := configBuilder.NewConfig(buflintv1beta1.VersionSpec)
+	if err != nil {
+		return nil, err
+	}
+	config := internalConfigToConfig(internalConfig)
+	if len(configBuilder.Plugins) > 0 {
+		config.pluginConfigBuilder = &configBuilder
+	}
+	return config, nil
+}
+
+// LoadPlugins returns the Config with the rules of its plugins.
+//
+// Listing the rules of the plugins runs them, so this is only done when
+// checking or listing rules. The Config is returned if it has no plugins.
+func LoadPlugins(ctx context.Context, config *Config) (*Config, error) {
+	if config.pluginConfigBuilder == nil {
+		return config, nil
+	}
+	internalConfig, err := config.pluginConfigBuilder.NewConfigWithPlugins(ctx, buflintv1beta1.VersionSpec)
 	if err != nil {
 		return nil, err
 	}
@@ -125,28 +166,103 @@ type ExternalConfigV1Beta1 struct {
 	// IgnoreRootPaths
 	Ignore []string `json:"ignore,omitempty" yaml:"ignore,omitempty"`
 	// IgnoreIDOrCategoryToRootPaths
-	IgnoreOnly                           map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
-	EnumZeroValueSuffix                  string              `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
-	RPCAllowSameRequestResponse          bool                `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
-	RPCAllowGoogleProtobufEmptyRequests  bool                `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
-	RPCAllowGoogleProtobufEmptyResponses bool                `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
-	ServiceSuffix                        string              `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
-	AllowCommentIgnores                  bool                `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
+	IgnoreOnly map[string][]string `json:"ignore_only,omitempty" yaml:"ignore_only,omitempty"`
+	// IDOrCategoryToSeverity
+	Severity                             map[string]string                         `json:"severity,omitempty" yaml:"severity,omitempty"`
+	CommentMinLength                     int                                       `json:"comment_min_length,omitempty" yaml:"comment_min_length,omitempty"`
+	EnumValueNumberRanges                ExternalNumberRangePolicyV1Beta1          `json:"enum_value_number_ranges,omitempty" yaml:"enum_value_number_ranges,omitempty"`
+	EnumZeroValueSuffix                  string                                    `json:"enum_zero_value_suffix,omitempty" yaml:"enum_zero_value_suffix,omitempty"`
+	FieldNumberMaxGap                    int                                       `json:"field_number_max_gap,omitempty" yaml:"field_number_max_gap,omitempty"`
+	FieldNumberRanges                    ExternalNumberRangePolicyV1Beta1          `json:"field_number_ranges,omitempty" yaml:"field_number_ranges,omitempty"`
+	RPCAllowSameRequestResponse          bool                                      `json:"rpc_allow_same_request_response,omitempty" yaml:"rpc_allow_same_request_response,omitempty"`
+	RPCAllowGoogleProtobufEmptyRequests  bool                                      `json:"rpc_allow_google_protobuf_empty_requests,omitempty" yaml:"rpc_allow_google_protobuf_empty_requests,omitempty"`
+	RPCAllowGoogleProtobufEmptyResponses bool                                      `json:"rpc_allow_google_protobuf_empty_responses,omitempty" yaml:"rpc_allow_google_protobuf_empty_responses,omitempty"`
+	ServiceSuffix                        string                                    `json:"service_suffix,omitempty" yaml:"service_suffix,omitempty"`
+	PackageImportRestrictions            []ExternalPackageImportRestrictionV1Beta1 `json:"package_import_restrictions,omitempty" yaml:"package_import_restrictions,omitempty"`
+	RequireOptions                       []ExternalRequiredOptionV1Beta1           `json:"require_options,omitempty" yaml:"require_options,omitempty"`
+	Plugins                              []ExternalPluginV1Beta1                   `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	AllowCommentIgnores                  bool                                      `json:"allow_comment_ignores,omitempty" yaml:"allow_comment_ignores,omitempty"`
 }
 
+// ExternalPackageImportRestrictionV1Beta1 is an external package import restriction.
+//
+// Packages matching From may not import packages matching Disallow. Patterns are
+// either a package name, or a package name followed by ".*" to match all packages
+// nested within it.
+type ExternalPackageImportRestrictionV1Beta1 struct {
+	From     string `json:"from,omitempty" yaml:"from,omitempty"`
+	Disallow string `json:"disallow,omitempty" yaml:"disallow,omitempty"`
+}
+
+// ExternalRequiredOptionV1Beta1 is an external required option.
+//
+// The custom option Option, given by the full name of its extension, must be set
+// on all elements of kind On in the packages matching Packages, or in all packages
+// if Packages is empty.
+type ExternalRequiredOptionV1Beta1 struct {
+	Option   string   `json:"option,omitempty" yaml:"option,omitempty"`
+	On       string   `json:"on,omitempty" yaml:"on,omitempty"`
+	Packages []string `json:"packages,omitempty" yaml:"packages,omitempty"`
+}
+
+// ExternalNumberRangePolicyV1Beta1 is an external number range policy.
+//
+// Numbers must be within one of the Allow ranges if any are given, must not be
+// within any of the Forbid ranges, and must not be greater than Max if Max is
+// non-zero. Ranges are either a single number such as "5", or a range such as
+// "1 to 15" or "20000 to max".
+type ExternalNumberRangePolicyV1Beta1 struct {
+	Allow  []string `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Forbid []string `json:"forbid,omitempty" yaml:"forbid,omitempty"`
+	Max    int      `json:"max,omitempty" yaml:"max,omitempty"`
+}
+
+// ExternalPluginV1Beta1 is an external plugin.
+type ExternalPluginV1Beta1 = internal.ExternalPluginV1Beta1
+
 // PrintFileAnnotations prints the FileAnnotations to the Writer.
 //
-// Also accepts config-ignore-yaml.
+// Also accepts config-ignore-yaml. The metadata of all built-in rules and of
+// the rules of the Configs is made available to formats that print it. The
+// Configs should be returned by LoadPlugins so that they include the rules of
+// their plugins.
 func PrintFileAnnotations(
 	writer io.Writer,
 	fileAnnotations []bufanalysis.FileAnnotation,
 	formatString string,
+	configs ...*Config,
 ) error {
 	switch s := strings.ToLower(strings.TrimSpace(formatString)); s {
 	case "config-ignore-yaml":
 		return printFileAnnotationsConfigIgnoreYAML(writer, fileAnnotations)
 	default:
-		return bufanalysis.PrintFileAnnotations(writer, fileAnnotations, s)
+		config, err := NewConfigV1Beta1(
+			ExternalConfigV1Beta1{
+				Use: buflintv1beta1.VersionSpec.AllCategories,
+			},
+		)
+		if err != nil {
+			return err
+		}
+		rules := config.Rules
+		ids := make(map[string]struct{}, len(rules))
+		for _, rule := range rules {
+			ids[rule.ID()] = struct{}{}
+		}
+		for _, config := range configs {
+			for _, rule := range config.Rules {
+				if _, ok := ids[rule.ID()]; !ok {
+					ids[rule.ID()] = struct{}{}
+					rules = append(rules, rule)
+				}
+			}
+		}
+		return bufanalysis.PrintFileAnnotations(
+			writer,
+			fileAnnotations,
+			s,
+			bufanalysis.PrintFileAnnotationsWithRules(rulesToBufanalysisRules(rules)),
+		)
 	}
 }
 
@@ -206,12 +322,58 @@ lint:
 	return err
 }
 
+func externalPackageImportRestrictionsToInternal(
+	externalPackageImportRestrictions []ExternalPackageImportRestrictionV1Beta1,
+) []internal.PackageImportRestriction {
+	if externalPackageImportRestrictions == nil {
+		return nil
+	}
+	packageImportRestrictions := make([]internal.PackageImportRestriction, len(externalPackageImportRestrictions))
+	for i, externalPackageImportRestriction := range externalPackageImportRestrictions {
+		packageImportRestrictions[i] = internal.PackageImportRestriction{
+			From:     externalPackageImportRestriction.From,
+			Disallow: externalPackageImportRestriction.Disallow,
+		}
+	}
+	return packageImportRestrictions
+}
+
+func externalRequiredOptionsToInternal(
+	externalRequiredOptions []ExternalRequiredOptionV1Beta1,
+) []internal.RequiredOption {
+	if externalRequiredOptions == nil {
+		return nil
+	}
+	requiredOptions := make([]internal.RequiredOption, len(externalRequiredOptions))
+	for i, externalRequiredOption := range externalRequiredOptions {
+		requiredOptions[i] = internal.RequiredOption{
+			Option:   externalRequiredOption.Option,
+			On:       externalRequiredOption.On,
+			Packages: externalRequiredOption.Packages,
+		}
+	}
+	return requiredOptions
+}
+
+func externalNumberRangePolicyToInternal(
+	externalNumberRangePolicy ExternalNumberRangePolicyV1Beta1,
+) internal.NumberRangePolicy {
+	return internal.NumberRangePolicy{
+		Allow:  externalNumberRangePolicy.Allow,
+		Forbid: externalNumberRangePolicy.Forbid,
+		Max:    externalNumberRangePolicy.Max,
+	}
+}
+
 func internalConfigToConfig(internalConfig *internal.Config) *Config {
 	return &Config{
 		Rules:               internalRulesToRules(internalConfig.Rules),
 		IgnoreIDToRootPaths: internalConfig.IgnoreIDToRootPaths,
 		IgnoreRootPaths:     internalConfig.IgnoreRootPaths,
+		IDToSeverity:        internalConfig.IDToSeverity,
 		AllowCommentIgnores: internalConfig.AllowCommentIgnores,
+		EnumZeroValueSuffix: internalConfig.EnumZeroValueSuffix,
+		ServiceSuffix:       internalConfig.ServiceSuffix,
 	}
 }
 
@@ -220,7 +382,10 @@ func configToInternalConfig(config *Config) *internal.Config {
 		Rules:               rulesToInternalRules(config.Rules),
 		IgnoreIDToRootPaths: config.IgnoreIDToRootPaths,
 		IgnoreRootPaths:     config.IgnoreRootPaths,
+		IDToSeverity:        config.IDToSeverity,
 		AllowCommentIgnores: config.AllowCommentIgnores,
+		EnumZeroValueSuffix: config.EnumZeroValueSuffix,
+		ServiceSuffix:       config.ServiceSuffix,
 	}
 }
 
@@ -234,3 +399,14 @@ func rulesToBufcheckRules(rules []Rule) []bufcheck.Rule {
 	}
 	return s
 }
+
+func rulesToBufanalysisRules(rules []Rule) []bufanalysis.Rule {
+	if rules == nil {
+		return nil
+	}
+	s := make([]bufanalysis.Rule, len(rules))
+	for i, e := range rules {
+		s[i] = e
+	}
+	return s
+}
@@ -15,8 +15,13 @@
 package buflint_test
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -28,6 +33,8 @@ import (
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimagebuild"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufmodule"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufmodule/bufmodulebuild"
+	checkv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/check/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/protoencoding"
 	"github.com/bufbuild/buf/internal/pkg/storage"
 	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"github.com/stretchr/testify/assert"
@@ -35,10 +42,68 @@ import (
 	"go.uber.org/zap"
 )
 
+const testPluginEnvKey = "BUF_TEST_PLUGIN"
+
 // Hint on how to get these:
 // 1. cd into the specific diriectory
 // 2. buf lint --error-format=json | jq '[.path, ".", .start_line, .start_column, .end_line, .end_column, .type] | @csv' --raw-output
 
+func TestMain(m *testing.M) {
+	// the test binary is used as the plugin for TestRunPlugin
+	if os.Getenv(testPluginEnvKey) != "" {
+		if err := testRunPlugin(os.Stdin, os.Stdout); err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestRunAIP(t *testing.T) {
+	testLint(
+		t,
+		"aip",
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 25, 7, 25, 14, "AIP_HTTP_ANNOTATION"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 29, 7, 29, 10, "AIP_STANDARD_METHOD_NAMES"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 32, 42, 32, 58, "AIP_STANDARD_METHOD_NAMES"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 35, 19, 35, 37, "AIP_LIST_PAGINATION"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 35, 48, 35, 55, "AIP_LIST_PAGINATION"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 35, 48, 35, 55, "AIP_STANDARD_METHOD_NAMES"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 38, 19, 38, 37, "AIP_UPDATE_MASK"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 41, 19, 41, 37, "AIP_STANDARD_METHOD_NAMES"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 41, 48, 41, 52, "AIP_STANDARD_METHOD_NAMES"),
+	)
+}
+
+func TestRunCommentPlaceholder(t *testing.T) {
+	testLint(
+		t,
+		"comment_placeholder",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 6, 1, 21, 2, "COMMENT_MESSAGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 3, 8, 16, "COMMENT_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 3, 10, 23, "COMMENT_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 3, 12, 19, "COMMENT_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 17, 3, 17, 17, "COMMENT_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 29, 5, 29, 17, "COMMENT_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 31, 5, 31, 17, "COMMENT_FIELD"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 36, 1, 41, 2, "COMMENT_ENUM"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 38, 3, 38, 25, "COMMENT_ENUM_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 40, 3, 40, 17, "COMMENT_ENUM_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 46, 3, 46, 30, "COMMENT_RPC"),
+	)
+}
+
+func TestRunCommentStartsWithName(t *testing.T) {
+	testLint(
+		t,
+		"comment_starts_with_name",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 28, 1, 33, 2, "COMMENT_STARTS_WITH_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 32, 3, 32, 17, "COMMENT_STARTS_WITH_NAME"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 38, 3, 38, 30, "COMMENT_STARTS_WITH_NAME"),
+	)
+}
+
 func TestRunComments(t *testing.T) {
 	testLint(
 		t,
@@ -143,6 +208,37 @@ func TestRunComments(t *testing.T) {
 	)
 }
 
+func TestRunDeprecatedComment(t *testing.T) {
+	testLint(
+		t,
+		"deprecated_comment",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 3, 11, 45, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 13, 3, 13, 44, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 1, 24, 2, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 18, 3, 20, 4, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 30, 3, 30, 35, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 35, 1, 38, 2, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 40, 1, 50, 2, "DEPRECATED_COMMENT"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 46, 3, 48, 4, "DEPRECATED_COMMENT"),
+	)
+}
+
+func TestRunDeprecatedTypeNoUse(t *testing.T) {
+	testLint(
+		t,
+		"deprecated_type_no_use",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 3, 16, 6, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 18, 3, 18, 6, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 20, 12, 20, 15, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 21, 3, 21, 19, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 23, 5, 23, 8, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 9, 3, 9, 6, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 13, 11, 13, 14, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 14, 25, 14, 28, "DEPRECATED_TYPE_NO_USE"),
+		bufanalysistesting.NewFileAnnotation(t, "d.proto", 9, 3, 9, 6, "DEPRECATED_TYPE_NO_USE"),
+	)
+}
+
 func TestRunDirectorySamePackage(t *testing.T) {
 	testLint(
 		t,
@@ -174,6 +270,25 @@ func TestRunImportNoWeak(t *testing.T) {
 	)
 }
 
+func TestRunImportNoTransitive(t *testing.T) {
+	testLint(
+		t,
+		"import_no_transitive",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 5, 25, "IMPORT_NO_TRANSITIVE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 1, 5, 25, "IMPORT_NO_TRANSITIVE"),
+	)
+}
+
+func TestRunImportUsed(t *testing.T) {
+	testLint(
+		t,
+		"import_used",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 6, 1, 6, 42, "IMPORT_USED"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 1, 9, 25, "IMPORT_USED"),
+		bufanalysistesting.NewFileAnnotation(t, "b.proto", 6, 1, 6, 25, "IMPORT_USED"),
+	)
+}
+
 func TestRunEnumFirstValueZero(t *testing.T) {
 	testLint(
 		t,
@@ -219,6 +334,26 @@ func TestRunEnumPascalCase(t *testing.T) {
 	)
 }
 
+func TestRunEnumValueNotNegative(t *testing.T) {
+	testLint(
+		t,
+		"enum_value_not_negative",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 18, 8, 20, "ENUM_VALUE_NOT_NEGATIVE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 14, 20, 14, 31, "ENUM_VALUE_NOT_NEGATIVE"),
+	)
+}
+
+func TestRunEnumValueNumberRange(t *testing.T) {
+	testLint(
+		t,
+		"enum_value_number_range",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 20, 8, 22, "ENUM_VALUE_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 16, 9, 18, "ENUM_VALUE_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 10, 21, 10, 24, "ENUM_VALUE_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 18, 11, 20, "ENUM_VALUE_NUMBER_RANGE"),
+	)
+}
+
 func TestRunEnumValuePrefix(t *testing.T) {
 	testLint(
 		t,
@@ -304,6 +439,16 @@ func TestRunFieldLowerSnakeCase(t *testing.T) {
 	)
 }
 
+func TestRunFieldNoDefaultValue(t *testing.T) {
+	testLint(
+		t,
+		"field_no_default_value",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 6, 27, 6, 38, "FIELD_NO_DEFAULT_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 47, 8, 62, "FIELD_NO_DEFAULT_VALUE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 35, 16, 46, "FIELD_NO_DEFAULT_VALUE"),
+	)
+}
+
 func TestRunFieldNoDescriptor(t *testing.T) {
 	testLint(
 		t,
@@ -343,6 +488,41 @@ func TestRunFieldNoDescriptor(t *testing.T) {
 	)
 }
 
+func TestRunFieldNotRequired(t *testing.T) {
+	testLint(
+		t,
+		"field_not_required",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 6, 3, 6, 11, "FIELD_NOT_REQUIRED"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 5, 12, 13, "FIELD_NOT_REQUIRED"),
+	)
+}
+
+func TestRunFieldNumberMaxGap(t *testing.T) {
+	testLint(
+		t,
+		"field_number_max_gap",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 12, "FIELD_NUMBER_MAX_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 12, "FIELD_NUMBER_MAX_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 12, "FIELD_NUMBER_MAX_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 12, "FIELD_NUMBER_MAX_GAP"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 19, 11, 19, 14, "FIELD_NUMBER_MAX_GAP"),
+	)
+}
+
+func TestRunFieldNumberRange(t *testing.T) {
+	testLint(
+		t,
+		"field_number_range",
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 7, 22, 7, 24, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 8, 22, 8, 25, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 9, 22, 9, 25, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 11, 14, 11, 24, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 12, 14, 12, 25, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 16, 24, 16, 27, "FIELD_NUMBER_RANGE"),
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 22, 22, 22, 26, "FIELD_NUMBER_RANGE"),
+	)
+}
+
 func TestRunFileLowerSnakeCase(t *testing.T) {
 	testLint(
 		t,
@@ -423,6 +603,24 @@ func TestRunPackageLowerSnakeCase(t *testing.T) {
 	)
 }
 
+func TestRunPackageNoImportCycle(t *testing.T) {
+	testLint(
+		t,
+		"package_no_import_cycle",
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 5, 1, 5, 23, "PACKAGE_NO_IMPORT_CYCLE"),
+		bufanalysistesting.NewFileAnnotation(t, "b/v1/b.proto", 5, 1, 5, 23, "PACKAGE_NO_IMPORT_CYCLE"),
+		bufanalysistesting.NewFileAnnotation(t, "c/v1/c.proto", 5, 1, 5, 24, "PACKAGE_NO_IMPORT_CYCLE"),
+	)
+}
+
+func TestRunPackageNoImportRestricted(t *testing.T) {
+	testLint(
+		t,
+		"package_no_import_restricted",
+		bufanalysistesting.NewFileAnnotation(t, "acme/public/v1/public.proto", 5, 1, 5, 42, "PACKAGE_NO_IMPORT_RESTRICTED"),
+	)
+}
+
 func TestRunPackageSameDirectory(t *testing.T) {
 	testLint(
 		t,
@@ -490,6 +688,155 @@ func TestRunPackageVersionSuffix(t *testing.T) {
 	)
 }
 
+func TestRunPlugin(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	testLintConfigModifier(
+		t,
+		"plugin",
+		func(config *bufconfig.Config) {
+			pluginPath := filepath.Join(t.TempDir(), "buf-plugin-test")
+			require.NoError(
+				t,
+				os.WriteFile(
+					pluginPath,
+					[]byte(fmt.Sprintf("#!/bin/sh\n%s=1 exec %q\n", testPluginEnvKey, os.Args[0])),
+					0700,
+				),
+			)
+			lintConfig, err := buflint.NewConfigV1Beta1(
+				buflint.ExternalConfigV1Beta1{
+					Use: []string{"PACKAGE_DEFINED", "TEST"},
+					IgnoreOnly: map[string][]string{
+						"TEST_MESSAGE_NO_FOO": {"b"},
+					},
+					Plugins: []buflint.ExternalPluginV1Beta1{
+						{
+							Name: "test",
+							Path: pluginPath,
+							Options: map[string]string{
+								"name": "Foo",
+							},
+						},
+					},
+				},
+			)
+			require.NoError(t, err)
+			require.True(t, lintConfig.HasPlugins())
+			// the plugin is not run until LoadPlugins is called
+			require.Equal(t, []string{"PACKAGE_DEFINED"}, testGetRuleIDs(lintConfig))
+			loadedLintConfig, err := buflint.LoadPlugins(context.Background(), lintConfig)
+			require.NoError(t, err)
+			require.False(t, loadedLintConfig.HasPlugins())
+			require.Equal(t, []string{"PACKAGE_DEFINED", "TEST_MESSAGE_NO_FOO"}, testGetRuleIDs(loadedLintConfig))
+			config.Lint = lintConfig
+		},
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 12, "TEST_MESSAGE_NO_FOO"),
+	)
+}
+
+func TestRunPluginNotFound(t *testing.T) {
+	t.Parallel()
+	lintConfig, err := buflint.NewConfigV1Beta1(
+		buflint.ExternalConfigV1Beta1{
+			Use: []string{"TEST"},
+			Plugins: []buflint.ExternalPluginV1Beta1{
+				{
+					Name: "test",
+					Path: filepath.Join(t.TempDir(), "buf-plugin-test"),
+				},
+			},
+		},
+	)
+	// the plugin is only run by LoadPlugins
+	require.NoError(t, err)
+	_, err = buflint.LoadPlugins(context.Background(), lintConfig)
+	require.Error(t, err)
+}
+
+func TestRunPluginBuiltinCategory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	t.Parallel()
+	pluginPath := filepath.Join(t.TempDir(), "buf-plugin-test")
+	require.NoError(
+		t,
+		os.WriteFile(
+			pluginPath,
+			[]byte(fmt.Sprintf("#!/bin/sh\n%s=1 exec %q\n", testPluginEnvKey, os.Args[0])),
+			0700,
+		),
+	)
+	lintConfig, err := buflint.NewConfigV1Beta1(
+		buflint.ExternalConfigV1Beta1{
+			Use: []string{"DEFAULT"},
+			Plugins: []buflint.ExternalPluginV1Beta1{
+				{
+					Name: "test",
+					Path: pluginPath,
+					Options: map[string]string{
+						"category": "DEFAULT",
+					},
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	_, err = buflint.LoadPlugins(context.Background(), lintConfig)
+	require.EqualError(t, err, `plugin "test" returned category "DEFAULT" for rule "TEST_MESSAGE_NO_FOO" which is a built-in category`)
+}
+
+func TestPrintFileAnnotationsPluginRules(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	t.Parallel()
+	pluginPath := filepath.Join(t.TempDir(), "buf-plugin-test")
+	require.NoError(
+		t,
+		os.WriteFile(
+			pluginPath,
+			[]byte(fmt.Sprintf("#!/bin/sh\n%s=1 exec %q\n", testPluginEnvKey, os.Args[0])),
+			0700,
+		),
+	)
+	lintConfig, err := buflint.NewConfigV1Beta1(
+		buflint.ExternalConfigV1Beta1{
+			Use: []string{"TEST"},
+			Plugins: []buflint.ExternalPluginV1Beta1{
+				{
+					Name: "test",
+					Path: pluginPath,
+				},
+			},
+		},
+	)
+	require.NoError(t, err)
+	loadedLintConfig, err := buflint.LoadPlugins(context.Background(), lintConfig)
+	require.NoError(t, err)
+	fileAnnotations := []bufanalysis.FileAnnotation{
+		bufanalysistesting.NewFileAnnotation(t, "a.proto", 5, 9, 5, 12, "TEST_MESSAGE_NO_FOO"),
+	}
+	buffer := bytes.NewBuffer(nil)
+	require.NoError(t, buflint.PrintFileAnnotations(buffer, fileAnnotations, "sarif"))
+	assert.NotContains(t, buffer.String(), "top-level messages do not have the configured name")
+	buffer.Reset()
+	require.NoError(t, buflint.PrintFileAnnotations(buffer, fileAnnotations, "sarif", loadedLintConfig))
+	assert.Contains(t, buffer.String(), "top-level messages do not have the configured name")
+}
+
+func TestRunRequireOptions(t *testing.T) {
+	testLint(
+		t,
+		"require_options",
+		bufanalysistesting.NewFileAnnotation(t, "acme/billing/v1/billing.proto", 14, 9, 14, 15, "REQUIRE_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "acme/billing/v1/billing.proto", 20, 12, 20, 19, "REQUIRE_OPTIONS"),
+		bufanalysistesting.NewFileAnnotation(t, "acme/store/v1/store.proto", 5, 9, 5, 21, "REQUIRE_OPTIONS"),
+	)
+}
+
 func TestRunRPCNoStreaming(t *testing.T) {
 	testLint(
 		t,
@@ -675,6 +1022,24 @@ func TestRunServiceSuffixCustom(t *testing.T) {
 	)
 }
 
+func TestRunStablePackageNoImportUnstable(t *testing.T) {
+	testLint(
+		t,
+		"stable_package_no_import_unstable",
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 6, 1, 6, 28, "STABLE_PACKAGE_NO_IMPORT_UNSTABLE"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 7, 1, 7, 29, "STABLE_PACKAGE_NO_IMPORT_UNSTABLE"),
+		bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 8, 1, 8, 27, "STABLE_PACKAGE_NO_IMPORT_UNSTABLE"),
+	)
+}
+
+func TestRunSyntaxSpecified(t *testing.T) {
+	testLint(
+		t,
+		"syntax_specified",
+		bufanalysistesting.NewFileAnnotationNoLocation(t, "a.proto", "SYNTAX_SPECIFIED"),
+	)
+}
+
 func TestRunIgnores1(t *testing.T) {
 	testLint(
 		t,
@@ -855,8 +1220,38 @@ func testLintConfigModifier(
 	defer cancel()
 	logger := zap.NewNop()
 
-	dirPath := filepath.Join("testdata", relDirPath)
+	config, image := testBuild(ctx, t, filepath.Join("testdata", relDirPath))
+	if configModifier != nil {
+		configModifier(config)
+	}
+	handler := buflint.NewHandler(logger)
+	fileAnnotations, err := handler.Check(
+		ctx,
+		config.Lint,
+		image,
+	)
+	assert.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		expectedFileAnnotations,
+		fileAnnotations,
+	)
+}
 
+func testGetRuleIDs(config *buflint.Config) []string {
+	var ids []string
+	for _, rule := range config.GetRules() {
+		ids = append(ids, rule.ID())
+	}
+	return ids
+}
+
+// testBuild returns the config and the image with imports for the directory.
+func testBuild(
+	ctx context.Context,
+	t *testing.T,
+	dirPath string,
+) (*bufconfig.Config, bufimage.Image) {
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
 	readWriteBucket, err := storageosProvider.NewReadWriteBucket(
 		dirPath,
@@ -864,11 +1259,8 @@ func testLintConfigModifier(
 	)
 	require.NoError(t, err)
 
-	configProvider := bufconfig.NewProvider(logger)
+	configProvider := bufconfig.NewProvider(zap.NewNop())
 	config := testGetConfig(t, configProvider, readWriteBucket)
-	if configModifier != nil {
-		configModifier(config)
-	}
 
 	module, err := bufmodulebuild.NewModuleBucketBuilder(zap.NewNop()).BuildForBucket(
 		context.Background(),
@@ -890,20 +1282,7 @@ func testLintConfigModifier(
 	)
 	require.NoError(t, err)
 	require.Empty(t, fileAnnotations)
-	image = bufimage.ImageWithoutImports(image)
-
-	handler := buflint.NewHandler(logger)
-	fileAnnotations, err = handler.Check(
-		ctx,
-		config.Lint,
-		image,
-	)
-	assert.NoError(t, err)
-	bufanalysistesting.AssertFileAnnotationsEqual(
-		t,
-		expectedFileAnnotations,
-		fileAnnotations,
-	)
+	return config, image
 }
 
 func testGetConfig(
@@ -917,3 +1296,73 @@ func testGetConfig(
 	require.NoError(t, err)
 	return config
 }
+
+// testRunPlugin is a check plugin with a single rule that checks that no
+// top-level message is named by the "name" option. The rule is in the category
+// given by the "category" option, or in TEST.
+func testRunPlugin(stdin io.Reader, stdout io.Writer) error {
+	requestData, err := io.ReadAll(stdin)
+	if err != nil {
+		return err
+	}
+	request := &checkv1alpha1.CheckRequest{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(requestData, request); err != nil {
+		return err
+	}
+	response := &checkv1alpha1.CheckResponse{}
+	if len(request.GetRuleIds()) == 0 {
+		category := request.GetOptions()["category"]
+		if category == "" {
+			category = "TEST"
+		}
+		response.Rules = []*checkv1alpha1.Rule{
+			{
+				Id:         "TEST_MESSAGE_NO_FOO",
+				Categories: []string{category},
+				Purpose:    "top-level messages do not have the configured name",
+			},
+		}
+	} else {
+		name := request.GetOptions()["name"]
+		if name == "" {
+			response.Error = "option name must be set"
+		}
+		image, err := bufimage.NewImageForProto(request.GetImage())
+		if err != nil {
+			return err
+		}
+		for _, imageFile := range image.Files() {
+			if imageFile.IsImport() {
+				continue
+			}
+			fileDescriptorProto := imageFile.Proto()
+			for _, location := range fileDescriptorProto.GetSourceCodeInfo().GetLocation() {
+				// 4 is message_type, 1 is name
+				path := location.GetPath()
+				if len(path) != 3 || path[0] != 4 || path[2] != 1 ||
+					fileDescriptorProto.GetMessageType()[path[1]].GetName() != name {
+					continue
+				}
+				span := location.GetSpan()
+				response.FileAnnotations = append(
+					response.FileAnnotations,
+					&checkv1alpha1.FileAnnotation{
+						Path:        imageFile.Path(),
+						StartLine:   uint32(span[0]) + 1,
+						StartColumn: uint32(span[1]) + 1,
+						EndLine:     uint32(span[0]) + 1,
+						EndColumn:   uint32(span[len(span)-1]) + 1,
+						RuleId:      "TEST_MESSAGE_NO_FOO",
+						Message:     fmt.Sprintf("Message %q is not allowed.", name),
+					},
+				)
+			}
+		}
+	}
+	responseData, err := protoencoding.NewWireMarshaler().Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(responseData)
+	return err
+}
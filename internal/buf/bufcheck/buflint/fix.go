@@ -0,0 +1,989 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	enumValuePrefixID         = "ENUM_VALUE_PREFIX"
+	enumZeroValueSuffixID     = "ENUM_ZERO_VALUE_SUFFIX"
+	fieldLowerSnakeCaseID     = "FIELD_LOWER_SNAKE_CASE"
+	serviceSuffixID           = "SERVICE_SUFFIX"
+	rpcRequestStandardNameID  = "RPC_REQUEST_STANDARD_NAME"
+	rpcResponseStandardNameID = "RPC_RESPONSE_STANDARD_NAME"
+	packageSameGoPackageID    = "PACKAGE_SAME_GO_PACKAGE"
+
+	// these are the tags of the fields of the descriptor protos, as used
+	// in source code info paths
+	fileSyntaxTag            = 12
+	filePackageTag           = 2
+	fileMessageTypeTag       = 4
+	fileEnumTypeTag          = 5
+	fileExtensionTag         = 7
+	fileServiceTag           = 6
+	fileOptionsTag           = 8
+	messageFieldTag          = 2
+	messageNestedTypeTag     = 3
+	messageEnumTypeTag       = 4
+	messageExtensionRangeTag = 5
+	messageExtensionTag      = 6
+	messageOptionsTag        = 7
+	messageOneofDeclTag      = 8
+	fieldExtendeeTag         = 2
+	fieldTypeNameTag         = 6
+	fieldDefaultValueTag     = 7
+	fieldOptionsTag          = 8
+	oneofOptionsTag          = 2
+	extensionRangeOptionsTag = 3
+	enumValueTag             = 2
+	enumOptionsTag           = 3
+	enumValueOptionsTag      = 3
+	serviceMethodTag         = 2
+	serviceOptionsTag        = 3
+	methodInputTypeTag       = 2
+	methodOutputTypeTag      = 3
+	methodOptionsTag         = 4
+)
+
+// Fix fixes the FileAnnotations of the rules that have a single obvious fix.
+//
+// The following rules are fixed:
+//
+//	ENUM_ZERO_VALUE_SUFFIX      The enum value is renamed to the UPPER_SNAKE_CASE enum name with the suffix.
+//	ENUM_VALUE_PREFIX           The enum value is prefixed with the UPPER_SNAKE_CASE enum name.
+//	FIELD_LOWER_SNAKE_CASE      The field is renamed to lower_snake_case.
+//	SERVICE_SUFFIX              The service is suffixed.
+//	RPC_REQUEST_STANDARD_NAME   The request message is renamed to MethodNameRequest.
+//	RPC_RESPONSE_STANDARD_NAME  The response message is renamed to MethodNameResponse.
+//	PACKAGE_SAME_GO_PACKAGE     The go_package option is set to the most common value within the package.
+//
+// When a descriptor is renamed, every reference to it within the files of
+// pathToData is updated. pathToData is the map from path to data of the files
+// that can be changed, and should contain all files of the module. The Image
+// must be built from these files with source code info.
+//
+// A FileAnnotation is not fixed if the new name would conflict with an
+// existing name, if a file that would need to change is not in pathToData, or
+// for the RPC rules, if the message is used by more than one RPC. No descriptor
+// is renamed if a file of pathToData is not in the Image, as references within
+// that file could not be updated.
+//
+// The fixed files are compiled before they are returned, and an error is
+// returned if they do not compile, so that fixes are never written that
+// would break the module.
+//
+// Returns the map from path to fixed data for the files that changed, and the
+// FileAnnotations that were not fixed.
+func Fix(
+	ctx context.Context,
+	config *Config,
+	image bufimage.Image,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	pathToData map[string][]byte,
+) (map[string][]byte, []bufanalysis.FileAnnotation, error) {
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return nil, nil, err
+	}
+	fixer, err := newFixer(config, image, files, fileAnnotations, pathToData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := fixer.fix(files); err != nil {
+		return nil, nil, err
+	}
+	pathToFixedData, err := fixer.apply(image)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkFixedDataCompiles(image, pathToData, pathToFixedData); err != nil {
+		return nil, nil, err
+	}
+	return pathToFixedData, fixer.getUnfixedFileAnnotations(), nil
+}
+
+// checkFixedDataCompiles compiles the files of pathToData with the fixed data
+// of pathToFixedData, and returns an error if they do not compile.
+//
+// Files that are not in pathToData are resolved from the Image.
+func checkFixedDataCompiles(
+	image bufimage.Image,
+	pathToData map[string][]byte,
+	pathToFixedData map[string][]byte,
+) error {
+	if len(pathToFixedData) == 0 {
+		return nil
+	}
+	paths := make([]string, 0, len(pathToData))
+	for path := range pathToData {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	parser := protoparse.Parser{
+		Accessor: func(path string) (io.ReadCloser, error) {
+			if data, ok := pathToFixedData[path]; ok {
+				return io.NopCloser(bytes.NewReader(data)), nil
+			}
+			if data, ok := pathToData[path]; ok {
+				return io.NopCloser(bytes.NewReader(data)), nil
+			}
+			return nil, fmt.Errorf("%s does not exist", path)
+		},
+		// the parser only calls this if the Accessor could not find the file
+		LookupImportProto: func(path string) (*descriptorpb.FileDescriptorProto, error) {
+			imageFile := image.GetFile(path)
+			if imageFile == nil {
+				return nil, fmt.Errorf("%s does not exist in image", path)
+			}
+			return imageFile.Proto(), nil
+		},
+	}
+	if _, err := parser.ParseFiles(paths...); err != nil {
+		return fmt.Errorf("fixes would result in files that do not compile, no files were fixed: %w", err)
+	}
+	return nil
+}
+
+type fixer struct {
+	config          *Config
+	pathToData      map[string][]byte
+	pathToImageFile map[string]bufimage.ImageFile
+	fileAnnotations []bufanalysis.FileAnnotation
+	// canRename is false if a file of pathToData is not in the image.
+	canRename bool
+
+	keyToFileAnnotationIndexes map[fixKey][]int
+	fixedFileAnnotationIndexes map[int]struct{}
+
+	fullNameToMessage         map[string]protosource.Message
+	fullNameToMessageProto    map[string]*descriptorpb.DescriptorProto
+	messageFullNameToRPCCount map[string]int
+	// extendeeToExtensions is the map from the fully-qualified name of each
+	// extended message to its extensions, used to resolve custom options.
+	extendeeToExtensions map[string][]*fixExtension
+	// fullNames are the fully-qualified names of all descriptors, updated as
+	// descriptors are renamed so that conflicts can be detected.
+	//
+	// Enum values are scoped to the parent of their enum, as in Protobuf.
+	fullNames map[string]struct{}
+	// fullNameToNewName is the map from the fully-qualified name of each renamed
+	// descriptor to its new name.
+	fullNameToNewName map[string]string
+
+	pathToEdits map[string][]*fixEdit
+}
+
+// fixKey identifies the FileAnnotations for a rule at a location.
+type fixKey struct {
+	id          string
+	path        string
+	startLine   int
+	startColumn int
+	endLine     int
+	endColumn   int
+}
+
+// fixEdit replaces the bytes from start to end with text.
+type fixEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+func newFixer(
+	config *Config,
+	image bufimage.Image,
+	files []protosource.File,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	pathToData map[string][]byte,
+) (*fixer, error) {
+	fullNameToMessage, err := protosource.FullNameToMessage(files...)
+	if err != nil {
+		return nil, err
+	}
+	fixer := &fixer{
+		config:                     config,
+		pathToData:                 pathToData,
+		pathToImageFile:            make(map[string]bufimage.ImageFile),
+		fileAnnotations:            fileAnnotations,
+		keyToFileAnnotationIndexes: make(map[fixKey][]int),
+		fixedFileAnnotationIndexes: make(map[int]struct{}),
+		fullNameToMessage:          fullNameToMessage,
+		fullNameToMessageProto:     make(map[string]*descriptorpb.DescriptorProto),
+		messageFullNameToRPCCount:  make(map[string]int),
+		extendeeToExtensions:       make(map[string][]*fixExtension),
+		fullNames:                  make(map[string]struct{}),
+		fullNameToNewName:          make(map[string]string),
+		pathToEdits:                make(map[string][]*fixEdit),
+	}
+	for i, fileAnnotation := range fileAnnotations {
+		fileInfo := fileAnnotation.FileInfo()
+		if fileInfo == nil {
+			continue
+		}
+		key := fixKey{
+			id:          fileAnnotation.Type(),
+			path:        fileInfo.Path(),
+			startLine:   fileAnnotation.StartLine(),
+			startColumn: fileAnnotation.StartColumn(),
+			endLine:     fileAnnotation.EndLine(),
+			endColumn:   fileAnnotation.EndColumn(),
+		}
+		fixer.keyToFileAnnotationIndexes[key] = append(fixer.keyToFileAnnotationIndexes[key], i)
+	}
+	for _, imageFile := range image.Files() {
+		fixer.pathToImageFile[imageFile.Path()] = imageFile
+		fileDescriptorProto := imageFile.Proto()
+		fixer.addFullNames(fileDescriptorProto.GetPackage(), fileDescriptorProto.GetMessageType(), fileDescriptorProto.GetEnumType())
+		for _, fieldDescriptorProto := range fileDescriptorProto.GetExtension() {
+			fixer.fullNames[joinFullName(fileDescriptorProto.GetPackage(), fieldDescriptorProto.GetName())] = struct{}{}
+			fixer.addExtension(fileDescriptorProto.GetPackage(), fieldDescriptorProto)
+		}
+		for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+			serviceFullName := joinFullName(fileDescriptorProto.GetPackage(), serviceDescriptorProto.GetName())
+			fixer.fullNames[serviceFullName] = struct{}{}
+			for _, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
+				fixer.fullNames[joinFullName(serviceFullName, methodDescriptorProto.GetName())] = struct{}{}
+				fixer.messageFullNameToRPCCount[strings.TrimPrefix(methodDescriptorProto.GetInputType(), ".")]++
+				fixer.messageFullNameToRPCCount[strings.TrimPrefix(methodDescriptorProto.GetOutputType(), ".")]++
+			}
+		}
+	}
+	fixer.canRename = true
+	for path := range pathToData {
+		if _, ok := fixer.pathToImageFile[path]; !ok {
+			fixer.canRename = false
+			break
+		}
+	}
+	return fixer, nil
+}
+
+func (f *fixer) addFullNames(
+	scope string,
+	descriptorProtos []*descriptorpb.DescriptorProto,
+	enumDescriptorProtos []*descriptorpb.EnumDescriptorProto,
+) {
+	for _, enumDescriptorProto := range enumDescriptorProtos {
+		f.fullNames[joinFullName(scope, enumDescriptorProto.GetName())] = struct{}{}
+		for _, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
+			f.fullNames[joinFullName(scope, enumValueDescriptorProto.GetName())] = struct{}{}
+		}
+	}
+	for _, descriptorProto := range descriptorProtos {
+		fullName := joinFullName(scope, descriptorProto.GetName())
+		f.fullNames[fullName] = struct{}{}
+		f.fullNameToMessageProto[fullName] = descriptorProto
+		for _, fieldDescriptorProto := range descriptorProto.GetField() {
+			f.fullNames[joinFullName(fullName, fieldDescriptorProto.GetName())] = struct{}{}
+		}
+		for _, fieldDescriptorProto := range descriptorProto.GetExtension() {
+			f.fullNames[joinFullName(fullName, fieldDescriptorProto.GetName())] = struct{}{}
+			f.addExtension(fullName, fieldDescriptorProto)
+		}
+		for _, oneofDescriptorProto := range descriptorProto.GetOneofDecl() {
+			f.fullNames[joinFullName(fullName, oneofDescriptorProto.GetName())] = struct{}{}
+		}
+		f.addFullNames(fullName, descriptorProto.GetNestedType(), descriptorProto.GetEnumType())
+	}
+}
+
+func (f *fixer) addExtension(scope string, fieldDescriptorProto *descriptorpb.FieldDescriptorProto) {
+	extendee := strings.TrimPrefix(fieldDescriptorProto.GetExtendee(), ".")
+	f.extendeeToExtensions[extendee] = append(
+		f.extendeeToExtensions[extendee],
+		&fixExtension{
+			fullName:             joinFullName(scope, fieldDescriptorProto.GetName()),
+			fieldDescriptorProto: fieldDescriptorProto,
+		},
+	)
+}
+
+func (f *fixer) fix(files []protosource.File) error {
+	for _, file := range files {
+		if _, ok := f.pathToData[file.Path()]; !ok {
+			continue
+		}
+		if err := protosource.ForEachEnum(f.fixEnum, file); err != nil {
+			return err
+		}
+		if err := protosource.ForEachMessage(f.fixMessage, file); err != nil {
+			return err
+		}
+		for _, service := range file.Services() {
+			if err := f.fixService(service); err != nil {
+				return err
+			}
+		}
+	}
+	return f.fixGoPackages(files)
+}
+
+func (f *fixer) fixEnum(enum protosource.Enum) error {
+	scope := getParentFullName(enum.FullName())
+	expectedPrefix := fieldToUpperSnakeCase(enum.Name()) + "_"
+	for _, enumValue := range enum.Values() {
+		fullName := joinFullName(scope, enumValue.Name())
+		if indexes := f.getFileAnnotationIndexes(enumZeroValueSuffixID, enumValue, enumValue.NameLocation()); len(indexes) > 0 && f.config.EnumZeroValueSuffix != "" {
+			newName := strings.TrimSuffix(expectedPrefix, "_") + f.config.EnumZeroValueSuffix
+			if f.rename(enumValue, fullName, scope, newName) {
+				f.markFixed(indexes)
+			}
+		}
+		if indexes := f.getFileAnnotationIndexes(enumValuePrefixID, enumValue, enumValue.NameLocation()); len(indexes) > 0 {
+			// the enum value may have already been renamed with the prefix above
+			if newName, ok := f.fullNameToNewName[fullName]; ok {
+				if strings.HasPrefix(newName, expectedPrefix) {
+					f.markFixed(indexes)
+				}
+				continue
+			}
+			if f.rename(enumValue, fullName, scope, expectedPrefix+enumValue.Name()) {
+				f.markFixed(indexes)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fixer) fixMessage(message protosource.Message) error {
+	if message.IsMapEntry() {
+		return nil
+	}
+	for _, field := range message.Fields() {
+		if indexes := f.getFileAnnotationIndexes(fieldLowerSnakeCaseID, field, field.NameLocation()); len(indexes) > 0 {
+			fullName := joinFullName(message.FullName(), field.Name())
+			if f.rename(field, fullName, message.FullName(), fieldToLowerSnakeCase(field.Name())) {
+				f.markFixed(indexes)
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fixer) fixService(service protosource.Service) error {
+	if indexes := f.getFileAnnotationIndexes(serviceSuffixID, service, service.NameLocation()); len(indexes) > 0 && f.config.ServiceSuffix != "" {
+		if f.rename(service, service.FullName(), service.File().Package(), service.Name()+f.config.ServiceSuffix) {
+			f.markFixed(indexes)
+		}
+	}
+	for _, method := range service.Methods() {
+		if indexes := f.getFileAnnotationIndexes(rpcRequestStandardNameID, method, method.InputTypeLocation()); len(indexes) > 0 {
+			if f.renameRPCMessage(method.InputTypeName(), stringutil.ToPascalCase(method.Name())+"Request") {
+				f.markFixed(indexes)
+			}
+		}
+		if indexes := f.getFileAnnotationIndexes(rpcResponseStandardNameID, method, method.OutputTypeLocation()); len(indexes) > 0 {
+			if f.renameRPCMessage(method.OutputTypeName(), stringutil.ToPascalCase(method.Name())+"Response") {
+				f.markFixed(indexes)
+			}
+		}
+	}
+	return nil
+}
+
+// renameRPCMessage renames the request or response message of a single RPC.
+//
+// Messages that are used by more than one RPC are not renamed, as the new
+// name would only be correct for one of them.
+func (f *fixer) renameRPCMessage(typeName string, newName string) bool {
+	fullName := strings.TrimPrefix(typeName, ".")
+	if f.messageFullNameToRPCCount[fullName] != 1 {
+		return false
+	}
+	message, ok := f.fullNameToMessage[fullName]
+	if !ok {
+		return false
+	}
+	return f.rename(message, fullName, getParentFullName(fullName), newName)
+}
+
+// fixGoPackages sets the go_package option of the files in each package to
+// the most common value within the package.
+func (f *fixer) fixGoPackages(files []protosource.File) error {
+	packageToFiles := make(map[string][]protosource.File)
+	fileToIndexes := make(map[protosource.File][]int)
+	for _, file := range files {
+		if _, ok := f.pathToData[file.Path()]; !ok {
+			continue
+		}
+		if indexes := f.getFileAnnotationIndexes(packageSameGoPackageID, file, file.GoPackageLocation()); len(indexes) > 0 {
+			packageToFiles[file.Package()] = append(packageToFiles[file.Package()], file)
+			fileToIndexes[file] = indexes
+		}
+	}
+	pkgs := make([]string, 0, len(packageToFiles))
+	for pkg := range packageToFiles {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		goPackage := getMostCommonGoPackage(packageToFiles[pkg])
+		if goPackage == "" {
+			continue
+		}
+		for _, file := range packageToFiles[pkg] {
+			if f.setGoPackage(file, goPackage) {
+				f.markFixed(fileToIndexes[file])
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fixer) setGoPackage(file protosource.File, goPackage string) bool {
+	if file.GoPackage() == goPackage {
+		return true
+	}
+	data := f.pathToData[file.Path()]
+	statement := fmt.Sprintf("option go_package = %s;", strconv.Quote(goPackage))
+	if location := file.GoPackageLocation(); location != nil {
+		// the location is the entire option statement
+		start, end, ok := getLocationRange(data, location)
+		if !ok || !bytes.HasPrefix(data[start:end], []byte("option")) {
+			return false
+		}
+		f.addEdit(file.Path(), start, end, statement)
+		return true
+	}
+	// insert the option after the last file option, or else after the
+	// package or syntax statement
+	imageFile, ok := f.pathToImageFile[file.Path()]
+	if !ok {
+		return false
+	}
+	var lastSpan []int32
+	var lastIsOption bool
+	for _, location := range imageFile.Proto().GetSourceCodeInfo().GetLocation() {
+		var isOption bool
+		switch path := location.GetPath(); {
+		case len(path) >= 2 && path[0] == fileOptionsTag:
+			isOption = true
+		case len(path) == 1 && (path[0] == filePackageTag || path[0] == fileSyntaxTag):
+		default:
+			continue
+		}
+		if lastSpan == nil || compareSpanEnds(location.GetSpan(), lastSpan) > 0 {
+			lastSpan = location.GetSpan()
+			lastIsOption = isOption
+		}
+	}
+	if lastSpan == nil {
+		f.addEdit(file.Path(), 0, 0, statement+"\n\n")
+		return true
+	}
+	_, offset, ok := getSpanRange(data, lastSpan)
+	if !ok {
+		return false
+	}
+	if lastIsOption {
+		f.addEdit(file.Path(), offset, offset, "\n"+statement)
+	} else {
+		f.addEdit(file.Path(), offset, offset, "\n\n"+statement)
+	}
+	return true
+}
+
+// rename renames the descriptor with the fully-qualified name within the scope.
+//
+// Returns false if the descriptor cannot be renamed.
+func (f *fixer) rename(descriptor protosource.NamedDescriptor, fullName string, scope string, newName string) bool {
+	if !f.canRename {
+		return false
+	}
+	path := descriptor.File().Path()
+	data, ok := f.pathToData[path]
+	if !ok {
+		return false
+	}
+	if _, ok := f.fullNameToNewName[fullName]; ok {
+		return false
+	}
+	newFullName := joinFullName(scope, newName)
+	if _, ok := f.fullNames[newFullName]; ok {
+		return false
+	}
+	location := descriptor.NameLocation()
+	if location == nil {
+		return false
+	}
+	start, end, ok := getLocationRange(data, location)
+	if !ok || string(data[start:end]) != descriptor.Name() {
+		return false
+	}
+	f.addEdit(path, start, end, newName)
+	delete(f.fullNames, fullName)
+	f.fullNames[newFullName] = struct{}{}
+	f.fullNameToNewName[fullName] = newName
+	return true
+}
+
+// apply adds the edits for all references to renamed descriptors, and then
+// applies all edits.
+func (f *fixer) apply(image bufimage.Image) (map[string][]byte, error) {
+	if len(f.fullNameToNewName) > 0 {
+		for _, imageFile := range image.Files() {
+			if _, ok := f.pathToData[imageFile.Path()]; !ok {
+				continue
+			}
+			if err := f.addReferenceEdits(imageFile); err != nil {
+				return nil, err
+			}
+		}
+	}
+	pathToFixedData := make(map[string][]byte)
+	for path, edits := range f.pathToEdits {
+		data := f.pathToData[path]
+		sort.SliceStable(
+			edits,
+			func(i int, j int) bool {
+				return edits[i].start < edits[j].start
+			},
+		)
+		buffer := bytes.NewBuffer(nil)
+		offset := 0
+		var previousEdit *fixEdit
+		for _, edit := range edits {
+			if previousEdit != nil && previousEdit.start == edit.start && previousEdit.end == edit.end {
+				// multiple extension fields share the location of their extendee
+				if previousEdit.text != edit.text {
+					return nil, fmt.Errorf("%s: conflicting fixes at offset %d", path, edit.start)
+				}
+				continue
+			}
+			if edit.start < offset {
+				return nil, fmt.Errorf("%s: overlapping fixes at offset %d", path, edit.start)
+			}
+			_, _ = buffer.Write(data[offset:edit.start])
+			_, _ = buffer.WriteString(edit.text)
+			offset = edit.end
+			previousEdit = edit
+		}
+		_, _ = buffer.Write(data[offset:])
+		if fixedData := buffer.Bytes(); !bytes.Equal(data, fixedData) {
+			pathToFixedData[path] = fixedData
+		}
+	}
+	return pathToFixedData, nil
+}
+
+func (f *fixer) addReferenceEdits(imageFile bufimage.ImageFile) error {
+	fileDescriptorProto := imageFile.Proto()
+	pathKeyToSpan := make(map[string][]int32)
+	for _, location := range fileDescriptorProto.GetSourceCodeInfo().GetLocation() {
+		pathKeyToSpan[getPathKey(location.GetPath())] = location.GetSpan()
+	}
+	referenceEditor := &referenceEditor{
+		fixer:         f,
+		path:          imageFile.Path(),
+		data:          f.pathToData[imageFile.Path()],
+		pathKeyToSpan: pathKeyToSpan,
+	}
+	// each option has a location within its options message, and repeated
+	// options have an additional location with the same span
+	optionSpanKeys := make(map[string]struct{})
+	for _, location := range fileDescriptorProto.GetSourceCodeInfo().GetLocation() {
+		prefixLength, optionsFullName, ok := getOptionsPathPrefixLength(location.GetPath())
+		if !ok || len(location.GetPath()) <= prefixLength {
+			continue
+		}
+		spanKey := getPathKey(location.GetSpan())
+		if _, ok := optionSpanKeys[spanKey]; ok {
+			continue
+		}
+		optionSpanKeys[spanKey] = struct{}{}
+		referenceEditor.addOptionEdits(location.GetSpan(), optionsFullName)
+	}
+	for i, descriptorProto := range fileDescriptorProto.GetMessageType() {
+		if err := referenceEditor.addMessageEdits([]int32{fileMessageTypeTag, int32(i)}, descriptorProto); err != nil {
+			return err
+		}
+	}
+	for i, fieldDescriptorProto := range fileDescriptorProto.GetExtension() {
+		if err := referenceEditor.addFieldEdits([]int32{fileExtensionTag, int32(i)}, fieldDescriptorProto); err != nil {
+			return err
+		}
+	}
+	for i, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+		for j, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
+			methodPath := []int32{fileServiceTag, int32(i), serviceMethodTag, int32(j)}
+			if err := referenceEditor.addTypeNameEdit(appendPath(methodPath, methodInputTypeTag), methodDescriptorProto.GetInputType()); err != nil {
+				return err
+			}
+			if err := referenceEditor.addTypeNameEdit(appendPath(methodPath, methodOutputTypeTag), methodDescriptorProto.GetOutputType()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fixer) getFileAnnotationIndexes(id string, descriptor protosource.Descriptor, location protosource.Location) []int {
+	key := fixKey{
+		id:   id,
+		path: descriptor.File().Path(),
+	}
+	if location != nil {
+		key.startLine = location.StartLine()
+		key.startColumn = location.StartColumn()
+		key.endLine = location.EndLine()
+		key.endColumn = location.EndColumn()
+	}
+	return f.keyToFileAnnotationIndexes[key]
+}
+
+func (f *fixer) markFixed(indexes []int) {
+	for _, index := range indexes {
+		f.fixedFileAnnotationIndexes[index] = struct{}{}
+	}
+}
+
+func (f *fixer) addEdit(path string, start int, end int, text string) {
+	f.pathToEdits[path] = append(
+		f.pathToEdits[path],
+		&fixEdit{
+			start: start,
+			end:   end,
+			text:  text,
+		},
+	)
+}
+
+func (f *fixer) getUnfixedFileAnnotations() []bufanalysis.FileAnnotation {
+	var unfixedFileAnnotations []bufanalysis.FileAnnotation
+	for i, fileAnnotation := range f.fileAnnotations {
+		if _, ok := f.fixedFileAnnotationIndexes[i]; !ok {
+			unfixedFileAnnotations = append(unfixedFileAnnotations, fileAnnotation)
+		}
+	}
+	return unfixedFileAnnotations
+}
+
+// referenceEditor adds the edits for the references to renamed descriptors within a file.
+type referenceEditor struct {
+	fixer         *fixer
+	path          string
+	data          []byte
+	pathKeyToSpan map[string][]int32
+}
+
+func (r *referenceEditor) addMessageEdits(messagePath []int32, descriptorProto *descriptorpb.DescriptorProto) error {
+	for i, fieldDescriptorProto := range descriptorProto.GetField() {
+		if err := r.addFieldEdits(appendPath(messagePath, messageFieldTag, int32(i)), fieldDescriptorProto); err != nil {
+			return err
+		}
+	}
+	for i, fieldDescriptorProto := range descriptorProto.GetExtension() {
+		if err := r.addFieldEdits(appendPath(messagePath, messageExtensionTag, int32(i)), fieldDescriptorProto); err != nil {
+			return err
+		}
+	}
+	for i, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+		if err := r.addMessageEdits(appendPath(messagePath, messageNestedTypeTag, int32(i)), nestedDescriptorProto); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *referenceEditor) addFieldEdits(fieldPath []int32, fieldDescriptorProto *descriptorpb.FieldDescriptorProto) error {
+	if err := r.addTypeNameEdit(appendPath(fieldPath, fieldExtendeeTag), fieldDescriptorProto.GetExtendee()); err != nil {
+		return err
+	}
+	typeName := fieldDescriptorProto.GetTypeName()
+	if mapEntryDescriptorProto, ok := r.fixer.fullNameToMessageProto[strings.TrimPrefix(typeName, ".")]; ok && mapEntryDescriptorProto.GetOptions().GetMapEntry() {
+		return r.addMapValueTypeNameEdit(appendPath(fieldPath, fieldTypeNameTag), mapEntryDescriptorProto)
+	}
+	if err := r.addTypeNameEdit(appendPath(fieldPath, fieldTypeNameTag), typeName); err != nil {
+		return err
+	}
+	if fieldDescriptorProto.GetType() == descriptorpb.FieldDescriptorProto_TYPE_ENUM && fieldDescriptorProto.DefaultValue != nil {
+		// enum values are scoped to the parent of their enum
+		fullName := joinFullName(getParentFullName(strings.TrimPrefix(typeName, ".")), fieldDescriptorProto.GetDefaultValue())
+		if newName, ok := r.fixer.fullNameToNewName[fullName]; ok {
+			// the location is the entire default option, such as "default = FOO"
+			start, end, ok := r.getRange(appendPath(fieldPath, fieldDefaultValueTag))
+			if !ok || !bytes.HasSuffix(r.data[start:end], []byte(fieldDescriptorProto.GetDefaultValue())) {
+				return fmt.Errorf("%s: could not update the default value reference to %q", r.path, fullName)
+			}
+			r.fixer.addEdit(r.path, end-len(fieldDescriptorProto.GetDefaultValue()), end, newName)
+		}
+	}
+	return nil
+}
+
+// addMapValueTypeNameEdit adds the edit for the value type of a map field.
+//
+// The type name location of a map field is the entire map type, such as
+// "map<string, foo.Bar>", and the map entry has no locations.
+func (r *referenceEditor) addMapValueTypeNameEdit(typeNamePath []int32, mapEntryDescriptorProto *descriptorpb.DescriptorProto) error {
+	var valueTypeName string
+	for _, fieldDescriptorProto := range mapEntryDescriptorProto.GetField() {
+		if fieldDescriptorProto.GetNumber() == 2 {
+			valueTypeName = fieldDescriptorProto.GetTypeName()
+		}
+	}
+	if valueTypeName == "" {
+		return nil
+	}
+	start, end, ok := r.getRange(typeNamePath)
+	if !ok {
+		return nil
+	}
+	text := string(r.data[start:end])
+	commaIndex := strings.Index(text, ",")
+	greaterIndex := strings.LastIndex(text, ">")
+	if commaIndex < 0 || greaterIndex < commaIndex {
+		return fmt.Errorf("%s: could not parse the map type %q", r.path, text)
+	}
+	valueText := text[commaIndex+1 : greaterIndex]
+	trimmedValueText := strings.TrimSpace(valueText)
+	valueStart := start + commaIndex + 1 + strings.Index(valueText, trimmedValueText)
+	return r.addReferenceEdit(valueStart, valueStart+len(trimmedValueText), valueTypeName)
+}
+
+func (r *referenceEditor) addTypeNameEdit(typeNamePath []int32, typeName string) error {
+	if typeName == "" {
+		return nil
+	}
+	start, end, ok := r.getRange(typeNamePath)
+	if !ok {
+		return nil
+	}
+	return r.addReferenceEdit(start, end, typeName)
+}
+
+// addReferenceEdit adds the edit for the reference from start to end that
+// resolves to the type name, if any of its components were renamed.
+//
+// The components of a reference are always a suffix of the components of
+// the fully-qualified name that it resolves to.
+func (r *referenceEditor) addReferenceEdit(start int, end int, typeName string) error {
+	fullNameComponents := strings.Split(strings.TrimPrefix(typeName, "."), ".")
+	indexToNewName := make(map[int]string)
+	for i := range fullNameComponents {
+		if newName, ok := r.fixer.fullNameToNewName[strings.Join(fullNameComponents[:i+1], ".")]; ok {
+			indexToNewName[i] = newName
+		}
+	}
+	if len(indexToNewName) == 0 {
+		return nil
+	}
+	text := string(r.data[start:end])
+	components := strings.Split(strings.TrimPrefix(text, "."), ".")
+	offset := len(fullNameComponents) - len(components)
+	if offset < 0 {
+		return fmt.Errorf("%s: could not update the reference %q to %q", r.path, text, typeName)
+	}
+	for i, component := range components {
+		if component != fullNameComponents[offset+i] {
+			return fmt.Errorf("%s: could not update the reference %q to %q", r.path, text, typeName)
+		}
+	}
+	changed := false
+	for i, newName := range indexToNewName {
+		if i >= offset {
+			components[i-offset] = newName
+			changed = true
+		}
+	}
+	if !changed {
+		// only a component that the reference is relative to was renamed
+		return nil
+	}
+	newText := strings.Join(components, ".")
+	if strings.HasPrefix(text, ".") {
+		newText = "." + newText
+	}
+	r.fixer.addEdit(r.path, start, end, newText)
+	return nil
+}
+
+func (r *referenceEditor) getRange(path []int32) (int, int, bool) {
+	span, ok := r.pathKeyToSpan[getPathKey(path)]
+	if !ok {
+		return 0, 0, false
+	}
+	return getSpanRange(r.data, span)
+}
+
+// getMostCommonGoPackage returns the most common non-empty go_package value
+// of the files.
+//
+// Ties are broken by taking the lowest value.
+func getMostCommonGoPackage(files []protosource.File) string {
+	goPackageToCount := make(map[string]int)
+	for _, file := range files {
+		if goPackage := file.GoPackage(); goPackage != "" {
+			goPackageToCount[goPackage]++
+		}
+	}
+	var mostCommonGoPackage string
+	for goPackage, count := range goPackageToCount {
+		mostCommonCount := goPackageToCount[mostCommonGoPackage]
+		if count > mostCommonCount || (count == mostCommonCount && goPackage < mostCommonGoPackage) {
+			mostCommonGoPackage = goPackage
+		}
+	}
+	return mostCommonGoPackage
+}
+
+// getLocationRange returns the byte range of the location within data.
+func getLocationRange(data []byte, location protosource.Location) (int, int, bool) {
+	start, ok := getOffset(data, location.StartLine(), location.StartColumn())
+	if !ok {
+		return 0, 0, false
+	}
+	end, ok := getOffset(data, location.EndLine(), location.EndColumn())
+	if !ok || end < start {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// getSpanRange returns the byte range of the zero-indexed source code info
+// span within data.
+func getSpanRange(data []byte, span []int32) (int, int, bool) {
+	var startLine, startColumn, endLine, endColumn int32
+	switch len(span) {
+	case 3:
+		startLine, startColumn, endLine, endColumn = span[0], span[1], span[0], span[2]
+	case 4:
+		startLine, startColumn, endLine, endColumn = span[0], span[1], span[2], span[3]
+	default:
+		return 0, 0, false
+	}
+	start, ok := getOffset(data, int(startLine)+1, int(startColumn)+1)
+	if !ok {
+		return 0, 0, false
+	}
+	end, ok := getOffset(data, int(endLine)+1, int(endColumn)+1)
+	if !ok || end < start {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// getOffset returns the byte offset of the line and column within data.
+//
+// The line and column are not zero-indexed. Columns are counted the same way
+// as by the parser: tabs advance to the next multiple of 8, carriage returns
+// do not advance, and all other characters advance by one.
+func getOffset(data []byte, line int, column int) (int, bool) {
+	offset := 0
+	for currentLine := 1; currentLine < line; currentLine++ {
+		index := bytes.IndexByte(data[offset:], '\n')
+		if index < 0 {
+			return 0, false
+		}
+		offset += index + 1
+	}
+	currentColumn := 1
+	for currentColumn < column {
+		if offset >= len(data) {
+			return 0, false
+		}
+		r, size := utf8.DecodeRune(data[offset:])
+		switch r {
+		case '\n':
+			return 0, false
+		case '\r':
+		case '\t':
+			currentColumn += 8 - (currentColumn-1)%8
+		default:
+			currentColumn++
+		}
+		offset += size
+	}
+	return offset, currentColumn == column
+}
+
+// compareSpanEnds compares the ends of the zero-indexed source code info spans.
+func compareSpanEnds(one []int32, two []int32) int {
+	oneLine, oneColumn := getSpanEnd(one)
+	twoLine, twoColumn := getSpanEnd(two)
+	switch {
+	case oneLine != twoLine:
+		return int(oneLine - twoLine)
+	default:
+		return int(oneColumn - twoColumn)
+	}
+}
+
+func getSpanEnd(span []int32) (int32, int32) {
+	switch len(span) {
+	case 3:
+		return span[0], span[2]
+	case 4:
+		return span[2], span[3]
+	default:
+		return 0, 0
+	}
+}
+
+func getPathKey(path []int32) string {
+	strs := make([]string, len(path))
+	for i, element := range path {
+		strs[i] = strconv.Itoa(int(element))
+	}
+	return strings.Join(strs, ".")
+}
+
+func appendPath(path []int32, elements ...int32) []int32 {
+	newPath := make([]int32, 0, len(path)+len(elements))
+	newPath = append(newPath, path...)
+	return append(newPath, elements...)
+}
+
+func joinFullName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+func getParentFullName(fullName string) string {
+	if index := strings.LastIndex(fullName, "."); index >= 0 {
+		return fullName[:index]
+	}
+	return ""
+}
+
+func fieldToLowerSnakeCase(s string) string {
+	// this must be equal to the expected name of FIELD_LOWER_SNAKE_CASE
+	return stringutil.ToLowerSnakeCase(s)
+}
+
+func fieldToUpperSnakeCase(s string) string {
+	// this must be equal to the expected prefix of ENUM_VALUE_PREFIX
+	return stringutil.ToUpperSnakeCase(s)
+}
@@ -0,0 +1,352 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint
+
+import (
+	"strings"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// fixExtension is an extension within the image.
+type fixExtension struct {
+	fullName             string
+	fieldDescriptorProto *descriptorpb.FieldDescriptorProto
+}
+
+// optionToken is a token of the source of an option.
+type optionToken struct {
+	start int
+	end   int
+	text  string
+}
+
+// addOptionEdits adds the edits for the references to renamed descriptors
+// within the option at the span, where optionsFullName is the full name of
+// the options message the option is set on.
+//
+// The option is either an option statement such as `option (foo).bar = BAZ;`,
+// or a compact option such as `(foo) = { bar: BAZ }`. References are the
+// names of fields in the option name and in message literals, and the names
+// of enum values.
+func (r *referenceEditor) addOptionEdits(span []int32, optionsFullName string) {
+	start, end, ok := getSpanRange(r.data, span)
+	if !ok {
+		return
+	}
+	optionEditor := &optionEditor{
+		referenceEditor: r,
+		tokens:          tokenizeOption(r.data, start, end),
+	}
+	if optionEditor.peek() == "option" {
+		optionEditor.index++
+	}
+	fieldDescriptorProto := optionEditor.addOptionNameEdits(optionsFullName)
+	if fieldDescriptorProto == nil || optionEditor.peek() != "=" {
+		return
+	}
+	optionEditor.index++
+	optionEditor.addValueEdits(fieldDescriptorProto)
+}
+
+// optionEditor adds the edits for the references within the tokens of an
+// option.
+type optionEditor struct {
+	*referenceEditor
+	tokens []optionToken
+	index  int
+}
+
+// addOptionNameEdits adds the edits for the option name, and returns the
+// field that the option name resolves to, or nil if it cannot be resolved.
+func (o *optionEditor) addOptionNameEdits(optionsFullName string) *descriptorpb.FieldDescriptorProto {
+	messageFullName := optionsFullName
+	for {
+		var fieldDescriptorProto *descriptorpb.FieldDescriptorProto
+		if o.peek() == "(" {
+			o.index++
+			fieldDescriptorProto = o.addExtensionNameEdit(messageFullName, ")")
+		} else {
+			fieldDescriptorProto = o.addFieldNameEdit(messageFullName)
+		}
+		if fieldDescriptorProto == nil || o.peek() != "." {
+			return fieldDescriptorProto
+		}
+		o.index++
+		messageFullName = strings.TrimPrefix(fieldDescriptorProto.GetTypeName(), ".")
+	}
+}
+
+// addValueEdits adds the edits for the value of the field, and advances past
+// the value.
+//
+// The field can be nil, in which case the value is skipped.
+func (o *optionEditor) addValueEdits(fieldDescriptorProto *descriptorpb.FieldDescriptorProto) {
+	switch token := o.peek(); token {
+	case "{", "<":
+		o.index++
+		o.addMessageLiteralEdits(strings.TrimPrefix(fieldDescriptorProto.GetTypeName(), "."))
+	case "[":
+		o.index++
+		for o.index < len(o.tokens) && o.peek() != "]" {
+			o.addValueEdits(fieldDescriptorProto)
+			if o.peek() == "," {
+				o.index++
+			}
+		}
+		o.index++
+	case "-":
+		o.index++
+		o.addValueEdits(fieldDescriptorProto)
+	default:
+		if isOptionStringToken(token) {
+			// adjacent strings are concatenated
+			for o.index < len(o.tokens) && isOptionStringToken(o.peek()) {
+				o.index++
+			}
+			return
+		}
+		if fieldDescriptorProto.GetType() == descriptorpb.FieldDescriptorProto_TYPE_ENUM {
+			// enum values are scoped to the parent of their enum
+			enumFullName := strings.TrimPrefix(fieldDescriptorProto.GetTypeName(), ".")
+			o.index++
+			o.addNameEdit(joinFullName(getParentFullName(enumFullName), token))
+			return
+		}
+		o.index++
+	}
+}
+
+// addMessageLiteralEdits adds the edits for the fields of a message literal
+// of the message, and advances past the closing brace.
+func (o *optionEditor) addMessageLiteralEdits(messageFullName string) {
+	for o.index < len(o.tokens) {
+		switch o.peek() {
+		case "}", ">":
+			o.index++
+			return
+		case ",", ";":
+			o.index++
+			continue
+		}
+		var fieldDescriptorProto *descriptorpb.FieldDescriptorProto
+		if o.peek() == "[" {
+			o.index++
+			fieldDescriptorProto = o.addExtensionNameEdit(messageFullName, "]")
+		} else {
+			fieldDescriptorProto = o.addFieldNameEdit(messageFullName)
+		}
+		if o.peek() == ":" {
+			o.index++
+		}
+		o.addValueEdits(fieldDescriptorProto)
+	}
+}
+
+// addFieldNameEdit adds the edit for the name of a field of the message, and
+// returns the field, or nil if there is no such field.
+func (o *optionEditor) addFieldNameEdit(messageFullName string) *descriptorpb.FieldDescriptorProto {
+	name := o.peek()
+	o.index++
+	for _, fieldDescriptorProto := range o.fixer.fullNameToMessageProto[messageFullName].GetField() {
+		if fieldDescriptorProto.GetName() == name {
+			o.addNameEdit(joinFullName(messageFullName, name))
+			return fieldDescriptorProto
+		}
+	}
+	return nil
+}
+
+// addExtensionNameEdit adds the edit for the name of an extension of the
+// message, and returns the extension, or nil if there is no such extension.
+//
+// The name is made of the tokens until the closing token. Names are resolved
+// by their suffix within the extensions of the message, as the extendee
+// restricts the extensions that can be referenced.
+func (o *optionEditor) addExtensionNameEdit(messageFullName string, closingToken string) *descriptorpb.FieldDescriptorProto {
+	startIndex := o.index
+	for o.index < len(o.tokens) && o.peek() != closingToken {
+		o.index++
+	}
+	if o.index == startIndex || o.index >= len(o.tokens) {
+		o.index++
+		return nil
+	}
+	first := o.tokens[startIndex]
+	last := o.tokens[o.index-1]
+	o.index++
+	text := string(o.data[first.start:last.end])
+	name := strings.TrimPrefix(text, ".")
+	for _, extension := range o.fixer.extendeeToExtensions[messageFullName] {
+		if extension.fullName == name || strings.HasSuffix(extension.fullName, "."+name) {
+			// names with whitespace or comments within are left as is
+			if !strings.ContainsAny(text, " \t\r\n/") {
+				if err := o.addReferenceEdit(first.start, last.end, "."+extension.fullName); err != nil {
+					return extension.fieldDescriptorProto
+				}
+			}
+			return extension.fieldDescriptorProto
+		}
+	}
+	return nil
+}
+
+// addNameEdit adds the edit for the previous token if the descriptor with the
+// fully-qualified name was renamed.
+func (o *optionEditor) addNameEdit(fullName string) {
+	if newName, ok := o.fixer.fullNameToNewName[fullName]; ok {
+		token := o.tokens[o.index-1]
+		if fullName == token.text || strings.HasSuffix(fullName, "."+token.text) {
+			o.fixer.addEdit(o.path, token.start, token.end, newName)
+		}
+	}
+}
+
+// peek returns the text of the current token, or the empty string if there
+// are no more tokens.
+func (o *optionEditor) peek() string {
+	if o.index >= len(o.tokens) {
+		return ""
+	}
+	return o.tokens[o.index].text
+}
+
+func isOptionStringToken(token string) bool {
+	return strings.HasPrefix(token, `"`) || strings.HasPrefix(token, `'`)
+}
+
+// tokenizeOption returns the tokens of data from start to end, skipping
+// whitespace and comments.
+//
+// Identifiers and numbers are single tokens, as are strings including their
+// quotes. All other characters are single-character tokens.
+func tokenizeOption(data []byte, start int, end int) []optionToken {
+	var tokens []optionToken
+	offset := start
+	for offset < end {
+		c := data[offset]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			offset++
+		case c == '/' && offset+1 < end && data[offset+1] == '/':
+			for offset < end && data[offset] != '\n' {
+				offset++
+			}
+		case c == '/' && offset+1 < end && data[offset+1] == '*':
+			offset += 2
+			for offset < end && !(data[offset] == '*' && offset+1 < end && data[offset+1] == '/') {
+				offset++
+			}
+			offset += 2
+		case c == '"' || c == '\'':
+			tokenStart := offset
+			offset++
+			for offset < end && data[offset] != c {
+				if data[offset] == '\\' {
+					offset++
+				}
+				offset++
+			}
+			offset++
+			if offset > end {
+				offset = end
+			}
+			tokens = append(tokens, optionToken{start: tokenStart, end: offset, text: string(data[tokenStart:offset])})
+		case isOptionIdentifierByte(c):
+			tokenStart := offset
+			for offset < end && isOptionIdentifierByte(data[offset]) {
+				offset++
+			}
+			tokens = append(tokens, optionToken{start: tokenStart, end: offset, text: string(data[tokenStart:offset])})
+		default:
+			tokens = append(tokens, optionToken{start: offset, end: offset + 1, text: string(c)})
+			offset++
+		}
+	}
+	return tokens
+}
+
+func isOptionIdentifierByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// getOptionsPathPrefixLength returns the length of the prefix of the source
+// code info path that is the path of an options message, and the full name
+// of the options message.
+//
+// Returns false if the path is not within an options message.
+func getOptionsPathPrefixLength(path []int32) (int, string, bool) {
+	if len(path) < 1 {
+		return 0, "", false
+	}
+	switch path[0] {
+	case fileOptionsTag:
+		return 1, "google.protobuf.FileOptions", true
+	case fileMessageTypeTag:
+		return getMessageOptionsPathPrefixLength(path, 2)
+	case fileEnumTypeTag:
+		return getEnumOptionsPathPrefixLength(path, 2)
+	case fileExtensionTag:
+		return getElementOptionsPathPrefixLength(path, 2, fieldOptionsTag, "google.protobuf.FieldOptions")
+	case fileServiceTag:
+		if len(path) > 3 && path[2] == serviceMethodTag {
+			return getElementOptionsPathPrefixLength(path, 4, methodOptionsTag, "google.protobuf.MethodOptions")
+		}
+		return getElementOptionsPathPrefixLength(path, 2, serviceOptionsTag, "google.protobuf.ServiceOptions")
+	default:
+		return 0, "", false
+	}
+}
+
+// getMessageOptionsPathPrefixLength returns the options path prefix length
+// for the message with the elements of its path before the index.
+func getMessageOptionsPathPrefixLength(path []int32, index int) (int, string, bool) {
+	if len(path) <= index+1 {
+		return getElementOptionsPathPrefixLength(path, index, messageOptionsTag, "google.protobuf.MessageOptions")
+	}
+	switch path[index] {
+	case messageFieldTag, messageExtensionTag:
+		return getElementOptionsPathPrefixLength(path, index+2, fieldOptionsTag, "google.protobuf.FieldOptions")
+	case messageOneofDeclTag:
+		return getElementOptionsPathPrefixLength(path, index+2, oneofOptionsTag, "google.protobuf.OneofOptions")
+	case messageExtensionRangeTag:
+		return getElementOptionsPathPrefixLength(path, index+2, extensionRangeOptionsTag, "google.protobuf.ExtensionRangeOptions")
+	case messageNestedTypeTag:
+		return getMessageOptionsPathPrefixLength(path, index+2)
+	case messageEnumTypeTag:
+		return getEnumOptionsPathPrefixLength(path, index+2)
+	default:
+		return getElementOptionsPathPrefixLength(path, index, messageOptionsTag, "google.protobuf.MessageOptions")
+	}
+}
+
+// getEnumOptionsPathPrefixLength returns the options path prefix length for
+// the enum with the elements of its path before the index.
+func getEnumOptionsPathPrefixLength(path []int32, index int) (int, string, bool) {
+	if len(path) > index+1 && path[index] == enumValueTag {
+		return getElementOptionsPathPrefixLength(path, index+2, enumValueOptionsTag, "google.protobuf.EnumValueOptions")
+	}
+	return getElementOptionsPathPrefixLength(path, index, enumOptionsTag, "google.protobuf.EnumOptions")
+}
+
+// getElementOptionsPathPrefixLength returns the options path prefix length
+// for the element with the elements of its path before the index, if the
+// element at the index is the options tag.
+func getElementOptionsPathPrefixLength(path []int32, index int, optionsTag int32, optionsFullName string) (int, string, bool) {
+	if len(path) <= index || path[index] != optionsTag {
+		return 0, "", false
+	}
+	return index + 1, optionsFullName, true
+}
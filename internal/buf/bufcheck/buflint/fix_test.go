@@ -0,0 +1,167 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buflint_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufanalysis/bufanalysistesting"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestFix(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dirPath := filepath.Join("testdata", "fix")
+	config, image := testBuild(ctx, t, dirPath)
+	fileAnnotations, err := buflint.NewHandler(zap.NewNop()).Check(
+		ctx,
+		config.Lint,
+		bufimage.ImageWithoutImports(image),
+	)
+	require.NoError(t, err)
+	pathToData := make(map[string][]byte)
+	for _, imageFile := range image.Files() {
+		data, err := os.ReadFile(filepath.Join(dirPath, imageFile.Path()))
+		require.NoError(t, err)
+		pathToData[imageFile.Path()] = data
+	}
+	pathToFixedData, unfixedFileAnnotations, err := buflint.Fix(
+		ctx,
+		config.Lint,
+		image,
+		fileAnnotations,
+		pathToData,
+	)
+	require.NoError(t, err)
+	bufanalysistesting.AssertFileAnnotationsEqual(
+		t,
+		[]bufanalysis.FileAnnotation{
+			bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 16, 3, 16, 10, "ENUM_ZERO_VALUE_SUFFIX"),
+			bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 30, 12, 30, 17, "RPC_REQUEST_STANDARD_NAME"),
+			bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 30, 28, 30, 46, "RPC_RESPONSE_STANDARD_NAME"),
+			bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 31, 14, 31, 19, "RPC_REQUEST_STANDARD_NAME"),
+			bufanalysistesting.NewFileAnnotation(t, "a/v1/a.proto", 31, 30, 31, 48, "RPC_RESPONSE_STANDARD_NAME"),
+		},
+		unfixedFileAnnotations,
+	)
+	require.Len(t, pathToFixedData, 3)
+	for path, fixedData := range pathToFixedData {
+		golden, err := os.ReadFile(filepath.Join(dirPath, path+".golden"))
+		require.NoError(t, err)
+		assert.Equal(t, string(golden), string(fixedData), path)
+	}
+}
+
+func TestFixImageWithoutAllFiles(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	dirPath := filepath.Join("testdata", "fix")
+	config, image := testBuild(ctx, t, dirPath)
+	pathToData := make(map[string][]byte)
+	for _, imageFile := range image.Files() {
+		data, err := os.ReadFile(filepath.Join(dirPath, imageFile.Path()))
+		require.NoError(t, err)
+		pathToData[imageFile.Path()] = data
+	}
+	// c.proto is not in the image, so references within it could not be updated
+	image, err := bufimage.ImageWithOnlyPaths(image, []string{"a/v1/a.proto"})
+	require.NoError(t, err)
+	fileAnnotations, err := buflint.NewHandler(zap.NewNop()).Check(
+		ctx,
+		config.Lint,
+		bufimage.ImageWithoutImports(image),
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, fileAnnotations)
+	_, unfixedFileAnnotations, err := buflint.Fix(
+		ctx,
+		config.Lint,
+		image,
+		fileAnnotations,
+		pathToData,
+	)
+	require.NoError(t, err)
+	var expectedUnfixedFileAnnotations []bufanalysis.FileAnnotation
+	for _, fileAnnotation := range fileAnnotations {
+		if fileAnnotation.Type() != "PACKAGE_SAME_GO_PACKAGE" {
+			expectedUnfixedFileAnnotations = append(expectedUnfixedFileAnnotations, fileAnnotation)
+		}
+	}
+	assert.Equal(t, expectedUnfixedFileAnnotations, unfixedFileAnnotations)
+}
+
+func TestFixOptions(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "fix_options")
+	pathToFixedData, unfixedFileAnnotations, err := testFix(t, dirPath)
+	require.NoError(t, err)
+	assert.Empty(t, unfixedFileAnnotations)
+	require.Len(t, pathToFixedData, 2)
+	for path, fixedData := range pathToFixedData {
+		golden, err := os.ReadFile(filepath.Join(dirPath, path+".golden"))
+		require.NoError(t, err)
+		assert.Equal(t, string(golden), string(fixedData), path)
+	}
+}
+
+func TestFixOptionsDoNotCompile(t *testing.T) {
+	t.Parallel()
+	// the extension name in the option name contains a comment, so the
+	// renamed field after it is not updated
+	_, _, err := testFix(t, filepath.Join("testdata", "fix_options_do_not_compile"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "do not compile")
+}
+
+func testFix(t *testing.T, dirPath string) (map[string][]byte, []bufanalysis.FileAnnotation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	config, image := testBuild(ctx, t, dirPath)
+	fileAnnotations, err := buflint.NewHandler(zap.NewNop()).Check(
+		ctx,
+		config.Lint,
+		bufimage.ImageWithoutImports(image),
+	)
+	require.NoError(t, err)
+	require.NotEmpty(t, fileAnnotations)
+	pathToData := make(map[string][]byte)
+	for _, imageFile := range image.Files() {
+		if imageFile.IsImport() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dirPath, imageFile.Path()))
+		require.NoError(t, err)
+		pathToData[imageFile.Path()] = data
+	}
+	return buflint.Fix(
+		ctx,
+		config.Lint,
+		image,
+		fileAnnotations,
+		pathToData,
+	)
+}
@@ -20,8 +20,6 @@ import (
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
-	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
-	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"go.uber.org/zap"
 )
 
@@ -48,9 +46,9 @@ func (h *handler) Check(
 	config *Config,
 	image bufimage.Image,
 ) ([]bufanalysis.FileAnnotation, error) {
-	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	config, err := LoadPlugins(ctx, config)
 	if err != nil {
 		return nil, err
 	}
-	return h.runner.Check(ctx, configToInternalConfig(config), nil, files)
+	return h.runner.Check(ctx, configToInternalConfig(config), nil, image)
 }
@@ -17,6 +17,7 @@ package buflintbuild
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint/internal/buflintcheck"
@@ -25,47 +26,89 @@ import (
 )
 
 var (
+	// AIPHTTPAnnotationRuleBuilder is a rule builder.
+	AIPHTTPAnnotationRuleBuilder = internal.NewNopRuleBuilder(
+		"AIP_HTTP_ANNOTATION",
+		"all RPCs have a google.api.http annotation",
+		newAdapter(buflintcheck.CheckAIPHTTPAnnotation),
+	)
+	// AIPListPaginationRuleBuilder is a rule builder.
+	AIPListPaginationRuleBuilder = internal.NewNopRuleBuilder(
+		"AIP_LIST_PAGINATION",
+		"List RPCs have page_size and page_token request fields and a next_page_token response field",
+		newAdapter(buflintcheck.CheckAIPListPagination),
+	)
+	// AIPStandardMethodNamesRuleBuilder is a rule builder.
+	AIPStandardMethodNamesRuleBuilder = internal.NewNopRuleBuilder(
+		"AIP_STANDARD_METHOD_NAMES",
+		"Get, List, Create, Update, and Delete RPCs are named after their resource and have standard request and response types",
+		newAdapter(buflintcheck.CheckAIPStandardMethodNames),
+	)
+	// AIPUpdateMaskRuleBuilder is a rule builder.
+	AIPUpdateMaskRuleBuilder = internal.NewNopRuleBuilder(
+		"AIP_UPDATE_MASK",
+		"Update RPCs have an update_mask request field",
+		newAdapter(buflintcheck.CheckAIPUpdateMask),
+	)
 	// CommentEnumRuleBuilder is a rule builder.
-	CommentEnumRuleBuilder = internal.NewNopRuleBuilder(
+	CommentEnumRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_ENUM",
-		"enums have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentEnum),
+		"enums",
+		buflintcheck.CheckCommentEnum,
 	)
 	// CommentEnumValueRuleBuilder is a rule builder.
-	CommentEnumValueRuleBuilder = internal.NewNopRuleBuilder(
+	CommentEnumValueRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_ENUM_VALUE",
-		"enum values have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentEnumValue),
+		"enum values",
+		buflintcheck.CheckCommentEnumValue,
 	)
 	// CommentFieldRuleBuilder is a rule builder.
-	CommentFieldRuleBuilder = internal.NewNopRuleBuilder(
+	CommentFieldRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_FIELD",
-		"fields have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentField),
+		"fields",
+		buflintcheck.CheckCommentField,
 	)
 	// CommentMessageRuleBuilder is a rule builder.
-	CommentMessageRuleBuilder = internal.NewNopRuleBuilder(
+	CommentMessageRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_MESSAGE",
-		"messages have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentMessage),
+		"messages",
+		buflintcheck.CheckCommentMessage,
 	)
 	// CommentOneofRuleBuilder is a rule builder.
-	CommentOneofRuleBuilder = internal.NewNopRuleBuilder(
+	CommentOneofRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_ONEOF",
-		"oneof have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentOneof),
+		"oneof",
+		buflintcheck.CheckCommentOneof,
 	)
 	// CommentRPCRuleBuilder is a rule builder.
-	CommentRPCRuleBuilder = internal.NewNopRuleBuilder(
+	CommentRPCRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_RPC",
-		"RPCs have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentRPC),
+		"RPCs",
+		buflintcheck.CheckCommentRPC,
 	)
 	// CommentServiceRuleBuilder is a rule builder.
-	CommentServiceRuleBuilder = internal.NewNopRuleBuilder(
+	CommentServiceRuleBuilder = newCommentRuleBuilder(
 		"COMMENT_SERVICE",
-		"services have non-empty comments",
-		newAdapter(buflintcheck.CheckCommentService),
+		"services",
+		buflintcheck.CheckCommentService,
+	)
+	// CommentStartsWithNameRuleBuilder is a rule builder.
+	CommentStartsWithNameRuleBuilder = internal.NewNopRuleBuilder(
+		"COMMENT_STARTS_WITH_NAME",
+		"non-empty comments start with the name of the element they document",
+		newAdapter(buflintcheck.CheckCommentStartsWithName),
+	)
+	// DeprecatedCommentRuleBuilder is a rule builder.
+	DeprecatedCommentRuleBuilder = internal.NewNopRuleBuilder(
+		"DEPRECATED_COMMENT",
+		"deprecated elements have comments explaining what to use instead",
+		newAdapter(buflintcheck.CheckDeprecatedComment),
+	)
+	// DeprecatedTypeNoUseRuleBuilder is a rule builder.
+	DeprecatedTypeNoUseRuleBuilder = internal.NewNopRuleBuilder(
+		"DEPRECATED_TYPE_NO_USE",
+		"fields and RPCs that use deprecated messages or enums are deprecated as well",
+		newAdapter(buflintcheck.CheckDeprecatedTypeNoUse),
 	)
 	// DirectorySamePackageRuleBuilder is a rule builder.
 	DirectorySamePackageRuleBuilder = internal.NewNopRuleBuilder(
@@ -91,6 +134,33 @@ var (
 		"enums are PascalCase",
 		newAdapter(buflintcheck.CheckEnumPascalCase),
 	)
+	// EnumValueNotNegativeRuleBuilder is a rule builder.
+	EnumValueNotNegativeRuleBuilder = internal.NewNopRuleBuilder(
+		"ENUM_VALUE_NOT_NEGATIVE",
+		"enum values are not negative",
+		newAdapter(buflintcheck.CheckEnumValueNotNegative),
+	)
+	// EnumValueNumberRangeRuleBuilder is a rule builder.
+	EnumValueNumberRangeRuleBuilder = internal.NewRuleBuilder(
+		"ENUM_VALUE_NUMBER_RANGE",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return "enum value numbers are within the ranges configured by enum_value_number_ranges (configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			numberRangePolicy := configBuilder.EnumValueNumberRanges
+			allow, err := internal.ParseNumberRanges(numberRangePolicy.Allow)
+			if err != nil {
+				return nil, err
+			}
+			forbid, err := internal.ParseNumberRanges(numberRangePolicy.Forbid)
+			if err != nil {
+				return nil, err
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckEnumValueNumberRange(id, ignoreFunc, files, allow, forbid, numberRangePolicy.Max)
+			}), nil
+		},
+	)
 	// EnumValuePrefixRuleBuilder is a rule builder.
 	EnumValuePrefixRuleBuilder = internal.NewNopRuleBuilder(
 		"ENUM_VALUE_PREFIX",
@@ -127,12 +197,63 @@ var (
 		"field names are lower_snake_case",
 		newAdapter(buflintcheck.CheckFieldLowerSnakeCase),
 	)
+	// FieldNoDefaultValueRuleBuilder is a rule builder.
+	FieldNoDefaultValueRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_NO_DEFAULT_VALUE",
+		"fields do not have default values",
+		newAdapter(buflintcheck.CheckFieldNoDefaultValue),
+	)
 	// FieldNoDescriptorRuleBuilder is a rule builder.
 	FieldNoDescriptorRuleBuilder = internal.NewNopRuleBuilder(
 		"FIELD_NO_DESCRIPTOR",
 		`field names are not name capitalization of "descriptor" with any number of prefix or suffix underscores`,
 		newAdapter(buflintcheck.CheckFieldNoDescriptor),
 	)
+	// FieldNotRequiredRuleBuilder is a rule builder.
+	FieldNotRequiredRuleBuilder = internal.NewNopRuleBuilder(
+		"FIELD_NOT_REQUIRED",
+		"fields are not required",
+		newAdapter(buflintcheck.CheckFieldNotRequired),
+	)
+	// FieldNumberMaxGapRuleBuilder is a rule builder.
+	FieldNumberMaxGapRuleBuilder = internal.NewRuleBuilder(
+		"FIELD_NUMBER_MAX_GAP",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if configBuilder.FieldNumberMaxGap <= 0 {
+				return "", errors.New("field_number_max_gap is not positive")
+			}
+			return fmt.Sprintf("there are no more than %d unused field numbers between used field numbers (gap is configurable)", configBuilder.FieldNumberMaxGap), nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if configBuilder.FieldNumberMaxGap <= 0 {
+				return nil, errors.New("field_number_max_gap is not positive")
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckFieldNumberMaxGap(id, ignoreFunc, files, configBuilder.FieldNumberMaxGap)
+			}), nil
+		},
+	)
+	// FieldNumberRangeRuleBuilder is a rule builder.
+	FieldNumberRangeRuleBuilder = internal.NewRuleBuilder(
+		"FIELD_NUMBER_RANGE",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return "field and extension range numbers are within the ranges configured by field_number_ranges (configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			numberRangePolicy := configBuilder.FieldNumberRanges
+			allow, err := internal.ParseNumberRanges(numberRangePolicy.Allow)
+			if err != nil {
+				return nil, err
+			}
+			forbid, err := internal.ParseNumberRanges(numberRangePolicy.Forbid)
+			if err != nil {
+				return nil, err
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckFieldNumberRange(id, ignoreFunc, files, allow, forbid, numberRangePolicy.Max)
+			}), nil
+		},
+	)
 	// FileLowerSnakeCaseRuleBuilder is a rule builder.
 	FileLowerSnakeCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"FILE_LOWER_SNAKE_CASE",
@@ -151,6 +272,18 @@ var (
 		"imports are not weak",
 		newAdapter(buflintcheck.CheckImportNoWeak),
 	)
+	// ImportNoTransitiveRuleBuilder is a rule builder.
+	ImportNoTransitiveRuleBuilder = internal.NewNopRuleBuilder(
+		"IMPORT_NO_TRANSITIVE",
+		"referenced files are imported directly instead of through public imports",
+		newAdapter(buflintcheck.CheckImportNoTransitive),
+	)
+	// ImportUsedRuleBuilder is a rule builder.
+	ImportUsedRuleBuilder = internal.NewNopRuleBuilder(
+		"IMPORT_USED",
+		"all imports are used",
+		newAdapter(buflintcheck.CheckImportUsed),
+	)
 	// MessagePascalCaseRuleBuilder is a rule builder.
 	MessagePascalCaseRuleBuilder = internal.NewNopRuleBuilder(
 		"MESSAGE_PASCAL_CASE",
@@ -181,6 +314,24 @@ var (
 		"packages are lower_snake.case",
 		newAdapter(buflintcheck.CheckPackageLowerSnakeCase),
 	)
+	// PackageNoImportCycleRuleBuilder is a rule builder.
+	PackageNoImportCycleRuleBuilder = internal.NewNopRuleBuilder(
+		"PACKAGE_NO_IMPORT_CYCLE",
+		"packages do not have import cycles",
+		newAdapter(buflintcheck.CheckPackageNoImportCycle),
+	)
+	// PackageNoImportRestrictedRuleBuilder is a rule builder.
+	PackageNoImportRestrictedRuleBuilder = internal.NewRuleBuilder(
+		"PACKAGE_NO_IMPORT_RESTRICTED",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return "packages do not import packages restricted by package_import_restrictions (configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckPackageNoImportRestricted(id, ignoreFunc, files, configBuilder.PackageImportRestrictions)
+			}), nil
+		},
+	)
 	// PackageSameCsharpNamespaceRuleBuilder is a rule builder.
 	PackageSameCsharpNamespaceRuleBuilder = internal.NewNopRuleBuilder(
 		"PACKAGE_SAME_CSHARP_NAMESPACE",
@@ -235,6 +386,18 @@ var (
 		`the last component of all packages is a version of the form v\d+, v\d+test.*, v\d+(alpha|beta)\d+, or v\d+p\d+(alpha|beta)\d+, where numbers are >=1`,
 		newAdapter(buflintcheck.CheckPackageVersionSuffix),
 	)
+	// RequireOptionsRuleBuilder is a rule builder.
+	RequireOptionsRuleBuilder = internal.NewRuleBuilder(
+		"REQUIRE_OPTIONS",
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			return "custom options required by require_options are set (configurable)", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return buflintcheck.CheckRequireOptions(id, ignoreFunc, files, configBuilder.RequiredOptions)
+			}), nil
+		},
+	)
 	// RPCNoClientStreamingRuleBuilder is a rule builder.
 	RPCNoClientStreamingRuleBuilder = internal.NewNopRuleBuilder(
 		"RPC_NO_CLIENT_STREAMING",
@@ -330,6 +493,18 @@ var (
 			}), nil
 		},
 	)
+	// SyntaxSpecifiedRuleBuilder is a rule builder.
+	SyntaxSpecifiedRuleBuilder = internal.NewNopRuleBuilder(
+		"SYNTAX_SPECIFIED",
+		"all files have a syntax specified",
+		newAdapter(buflintcheck.CheckSyntaxSpecified),
+	)
+	// StablePackageNoImportUnstableRuleBuilder is a rule builder.
+	StablePackageNoImportUnstableRuleBuilder = internal.NewNopRuleBuilder(
+		"STABLE_PACKAGE_NO_IMPORT_UNSTABLE",
+		"stable packages do not import alpha, beta, or test packages",
+		newAdapter(buflintcheck.CheckStablePackageNoImportUnstable),
+	)
 )
 
 func newAdapter(
@@ -339,3 +514,33 @@ func newAdapter(
 		return f(id, ignoreFunc, files)
 	}
 }
+
+// newCommentRuleBuilder returns a rule builder for a COMMENT_* rule, which
+// checks that the elements of the plural type name have comments that are not
+// placeholders.
+func newCommentRuleBuilder(
+	id string,
+	pluralTypeName string,
+	f func(string, internal.IgnoreFunc, []protosource.File, int) ([]bufanalysis.FileAnnotation, error),
+) *internal.RuleBuilder {
+	return internal.NewRuleBuilder(
+		id,
+		func(configBuilder internal.ConfigBuilder) (string, error) {
+			if configBuilder.CommentMinLength < 0 {
+				return "", errors.New("comment_min_length is negative")
+			}
+			if configBuilder.CommentMinLength > 0 {
+				return fmt.Sprintf("%s have non-empty comments that are not placeholders and have at least %d characters (length is configurable)", pluralTypeName, configBuilder.CommentMinLength), nil
+			}
+			return pluralTypeName + " have non-empty comments that are not placeholders", nil
+		},
+		func(configBuilder internal.ConfigBuilder) (internal.CheckFunc, error) {
+			if configBuilder.CommentMinLength < 0 {
+				return nil, errors.New("comment_min_length is negative")
+			}
+			return internal.CheckFunc(func(id string, ignoreFunc internal.IgnoreFunc, _ []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+				return f(id, ignoreFunc, files, configBuilder.CommentMinLength)
+			}), nil
+		},
+	)
+}
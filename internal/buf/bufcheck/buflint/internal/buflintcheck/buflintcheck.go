@@ -19,8 +19,13 @@ package buflintcheck
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/internal"
@@ -28,69 +33,395 @@ import (
 	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"github.com/bufbuild/buf/internal/pkg/protoversion"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 )
 
+const (
+	// googleAPIHTTPFieldNumber is the field number of the google.api.http
+	// extension of MethodOptions.
+	googleAPIHTTPFieldNumber = 72295728
+	// aipLongRunningOperationTypeName is the type name standard methods may
+	// return instead of the resource if they are long-running.
+	aipLongRunningOperationTypeName = ".google.longrunning.Operation"
+)
+
+// aipStandardMethodVerbs are the verbs of the AIP standard methods.
+var aipStandardMethodVerbs = []string{
+	"Get",
+	"List",
+	"Create",
+	"Update",
+	"Delete",
+}
+
+// CheckAIPHTTPAnnotation is a check function.
+var CheckAIPHTTPAnnotation = newMethodCheckFunc(checkAIPHTTPAnnotation)
+
+func checkAIPHTTPAnnotation(add addFunc, method protosource.Method) error {
+	if !method.HasOptionExtension(googleAPIHTTPFieldNumber) {
+		add(method, method.NameLocation(), []protosource.Location{method.Location()}, "RPC %q should have a google.api.http annotation.", method.Name())
+	}
+	return nil
+}
+
+// CheckAIPListPagination is a check function.
+var CheckAIPListPagination = newMethodWithMessagesCheckFunc(checkAIPListPagination)
+
+func checkAIPListPagination(add addFunc, method protosource.Method, fullNameToMessage map[string]protosource.Message) error {
+	verb, _ := getAIPStandardMethodVerbAndNoun(method.Name())
+	if verb != "List" {
+		return nil
+	}
+	if request, ok := fullNameToMessage[strings.TrimPrefix(method.InputTypeName(), ".")]; ok {
+		for _, fieldName := range []string{"page_size", "page_token"} {
+			checkAIPMessageHasField(add, method, method.InputTypeLocation(), request, fieldName)
+		}
+	}
+	if response, ok := fullNameToMessage[strings.TrimPrefix(method.OutputTypeName(), ".")]; ok {
+		checkAIPMessageHasField(add, method, method.OutputTypeLocation(), response, "next_page_token")
+	}
+	return nil
+}
+
+// CheckAIPStandardMethodNames is a check function.
+var CheckAIPStandardMethodNames = newMethodCheckFunc(checkAIPStandardMethodNames)
+
+func checkAIPStandardMethodNames(add addFunc, method protosource.Method) error {
+	verb, noun := getAIPStandardMethodVerbAndNoun(method.Name())
+	if verb == "" {
+		return nil
+	}
+	extraIgnoreLocations := []protosource.Location{method.Location()}
+	if noun == "" {
+		add(method, method.NameLocation(), extraIgnoreLocations, "RPC %q should have the name of the resource after %q.", method.Name(), verb)
+		return nil
+	}
+	expectedRequestName := method.Name() + "Request"
+	if requestName := getSimpleTypeName(method.InputTypeName()); requestName != expectedRequestName {
+		add(method, method.InputTypeLocation(), extraIgnoreLocations, "RPC %q request type %q should be named %q.", method.Name(), requestName, expectedRequestName)
+	}
+	outputTypeName := method.OutputTypeName()
+	responseName := getSimpleTypeName(outputTypeName)
+	var expectedResponseNames []string
+	switch verb {
+	case "Get":
+		expectedResponseNames = []string{noun}
+	case "List":
+		expectedResponseNames = []string{method.Name() + "Response"}
+	case "Create", "Update":
+		if outputTypeName == aipLongRunningOperationTypeName {
+			return nil
+		}
+		expectedResponseNames = []string{noun}
+	case "Delete":
+		if outputTypeName == aipLongRunningOperationTypeName || outputTypeName == ".google.protobuf.Empty" {
+			return nil
+		}
+		expectedResponseNames = []string{noun, "google.protobuf.Empty"}
+	}
+	for _, expectedResponseName := range expectedResponseNames {
+		if responseName == expectedResponseName {
+			return nil
+		}
+	}
+	add(method, method.OutputTypeLocation(), extraIgnoreLocations, "RPC %q response type %q should be %s.", method.Name(), responseName, quoteAndJoin(expectedResponseNames, " or "))
+	return nil
+}
+
+// CheckAIPUpdateMask is a check function.
+var CheckAIPUpdateMask = newMethodWithMessagesCheckFunc(checkAIPUpdateMask)
+
+func checkAIPUpdateMask(add addFunc, method protosource.Method, fullNameToMessage map[string]protosource.Message) error {
+	verb, _ := getAIPStandardMethodVerbAndNoun(method.Name())
+	if verb != "Update" {
+		return nil
+	}
+	request, ok := fullNameToMessage[strings.TrimPrefix(method.InputTypeName(), ".")]
+	if !ok {
+		return nil
+	}
+	for _, field := range request.Fields() {
+		if field.Name() == "update_mask" && field.TypeName() == ".google.protobuf.FieldMask" {
+			return nil
+		}
+	}
+	add(method, method.InputTypeLocation(), []protosource.Location{method.Location()}, "RPC %q request type %q should have a google.protobuf.FieldMask field named \"update_mask\".", method.Name(), request.Name())
+	return nil
+}
+
+func checkAIPMessageHasField(
+	add addFunc,
+	method protosource.Method,
+	location protosource.Location,
+	message protosource.Message,
+	fieldName string,
+) {
+	for _, field := range message.Fields() {
+		if field.Name() == fieldName {
+			return
+		}
+	}
+	add(method, location, []protosource.Location{method.Location()}, "RPC %q message %q should have a field named %q.", method.Name(), message.Name(), fieldName)
+}
+
+// getAIPStandardMethodVerbAndNoun returns the verb and the resource noun of the
+// standard method name, or empty strings if the name is not a standard method.
+//
+// The noun is empty if the name is only the verb.
+func getAIPStandardMethodVerbAndNoun(name string) (string, string) {
+	for _, verb := range aipStandardMethodVerbs {
+		if !strings.HasPrefix(name, verb) {
+			continue
+		}
+		noun := name[len(verb):]
+		if noun == "" {
+			return verb, ""
+		}
+		// Getaway is not a standard method
+		if noun[0] >= 'A' && noun[0] <= 'Z' {
+			return verb, noun
+		}
+	}
+	return "", ""
+}
+
+func getSimpleTypeName(typeName string) string {
+	if i := strings.LastIndex(typeName, "."); i >= 0 {
+		return typeName[i+1:]
+	}
+	return typeName
+}
+
+func quoteAndJoin(values []string, sep string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, sep)
+}
+
 var (
 	// CheckCommentEnum is a check function.
-	CheckCommentEnum = newEnumCheckFunc(checkCommentEnum)
+	CheckCommentEnum = newCommentCheckFunc("Enum")
 	// CheckCommentEnumValue is a check function.
-	CheckCommentEnumValue = newEnumValueCheckFunc(checkCommentEnumValue)
+	CheckCommentEnumValue = newCommentCheckFunc("Enum value")
 	// CheckCommentField is a check function.
-	CheckCommentField = newFieldCheckFunc(checkCommentField)
+	CheckCommentField = newCommentCheckFunc("Field")
 	// CheckCommentMessage is a check function.
-	CheckCommentMessage = newMessageCheckFunc(checkCommentMessage)
+	CheckCommentMessage = newCommentCheckFunc("Message")
 	// CheckCommentOneof is a check function.
-	CheckCommentOneof = newOneofCheckFunc(checkCommentOneof)
+	CheckCommentOneof = newCommentCheckFunc("Oneof")
 	// CheckCommentService is a check function.
-	CheckCommentService = newServiceCheckFunc(checkCommentService)
+	CheckCommentService = newCommentCheckFunc("Service")
 	// CheckCommentRPC is a check function.
-	CheckCommentRPC = newMethodCheckFunc(checkCommentRPC)
+	CheckCommentRPC = newCommentCheckFunc("RPC")
 )
 
-func checkCommentEnum(add addFunc, value protosource.Enum) error {
-	return checkCommentNamedDescriptor(add, value, "Enum")
-}
+// commentPlaceholderRegexp matches comments that are only a placeholder for documentation.
+var commentPlaceholderRegexp = regexp.MustCompile(`^(TODO|FIXME)\b`)
 
-func checkCommentEnumValue(add addFunc, value protosource.EnumValue) error {
-	return checkCommentNamedDescriptor(add, value, "Enum value")
+// checkCommentNamedDescriptor checks that the element has a comment that is
+// not a placeholder, that is a TODO or FIXME, a comment that only repeats the
+// name of the element, or a comment with less than minLength characters.
+func checkCommentNamedDescriptor(
+	add addFunc,
+	namedDescriptor protosource.NamedDescriptor,
+	typeName string,
+	minLength int,
+) error {
+	location := namedDescriptor.Location()
+	if location == nil {
+		// this will magically skip map entry fields as well as a side-effect, although originally unintended
+		return nil
+	}
+	comment := getCommentText(location.LeadingComments())
+	switch {
+	case comment == "":
+		add(namedDescriptor, location, nil, "%s %q should have a non-empty comment for documentation.", typeName, namedDescriptor.Name())
+	case commentPlaceholderRegexp.MatchString(comment):
+		add(namedDescriptor, location, nil, "%s %q should have a comment for documentation that is not a TODO or FIXME.", typeName, namedDescriptor.Name())
+	case getCommentComparableText(comment) == getCommentComparableText(namedDescriptor.Name()):
+		add(namedDescriptor, location, nil, "%s %q should have a comment for documentation that does not only repeat its name.", typeName, namedDescriptor.Name())
+	case utf8.RuneCountInString(comment) < minLength:
+		add(namedDescriptor, location, nil, "%s %q should have a comment for documentation of at least %d characters.", typeName, namedDescriptor.Name(), minLength)
+	}
+	return nil
 }
 
-func checkCommentField(add addFunc, value protosource.Field) error {
-	return checkCommentNamedDescriptor(add, value, "Field")
-}
+// CheckCommentStartsWithName is a check function.
+var CheckCommentStartsWithName = newCommentNamedDescriptorCheckFunc(checkCommentStartsWithName)
 
-func checkCommentMessage(add addFunc, value protosource.Message) error {
-	return checkCommentNamedDescriptor(add, value, "Message")
+func checkCommentStartsWithName(
+	add addFunc,
+	namedDescriptor protosource.NamedDescriptor,
+	typeName string,
+) error {
+	location := namedDescriptor.Location()
+	if location == nil {
+		return nil
+	}
+	words := strings.Fields(getCommentText(location.LeadingComments()))
+	if len(words) == 0 {
+		// missing comments are handled by the COMMENT_* rules
+		return nil
+	}
+	// like Go doc comments, allow the comment to start with an article
+	if len(words) > 1 {
+		switch words[0] {
+		case "A", "An", "The":
+			words = words[1:]
+		}
+	}
+	if strings.TrimRight(words[0], ".,:;") != namedDescriptor.Name() {
+		add(namedDescriptor, location, nil, "%s %q should have a comment that starts with %q.", typeName, namedDescriptor.Name(), namedDescriptor.Name())
+	}
+	return nil
 }
 
-func checkCommentOneof(add addFunc, value protosource.Oneof) error {
-	return checkCommentNamedDescriptor(add, value, "Oneof")
+// getCommentText returns the comment with surrounding whitespace removed and
+// all inner whitespace, including newlines, collapsed to single spaces.
+func getCommentText(comment string) string {
+	return strings.Join(strings.Fields(comment), " ")
 }
 
-func checkCommentRPC(add addFunc, value protosource.Method) error {
-	return checkCommentNamedDescriptor(add, value, "RPC")
+// getCommentComparableText returns the lowercase letters and digits of s, so
+// that "foo_bar", "FooBar", and "Foo bar." compare equal.
+func getCommentComparableText(s string) string {
+	return strings.Map(
+		func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		},
+		s,
+	)
 }
 
-func checkCommentService(add addFunc, value protosource.Service) error {
-	return checkCommentNamedDescriptor(add, value, "Service")
-}
+// deprecatedCommentReplacementRegexp matches comments that explain what to use
+// instead of a deprecated element.
+var deprecatedCommentReplacementRegexp = regexp.MustCompile(`(?i)\b(use|instead|replaced|superseded)\b`)
 
-func checkCommentNamedDescriptor(
+// CheckDeprecatedComment is a check function.
+var CheckDeprecatedComment = newCommentNamedDescriptorCheckFunc(checkDeprecatedComment)
+
+func checkDeprecatedComment(
 	add addFunc,
 	namedDescriptor protosource.NamedDescriptor,
 	typeName string,
 ) error {
+	// oneofs cannot be deprecated
+	deprecatedDescriptor, ok := namedDescriptor.(interface{ Deprecated() bool })
+	if !ok || !deprecatedDescriptor.Deprecated() {
+		return nil
+	}
 	location := namedDescriptor.Location()
 	if location == nil {
-		// this will magically skip map entry fields as well as a side-effect, although originally unintended
 		return nil
 	}
-	if strings.TrimSpace(location.LeadingComments()) == "" {
-		add(namedDescriptor, location, nil, "%s %q should have a non-empty comment for documentation.", typeName, namedDescriptor.Name())
+	if !deprecatedCommentReplacementRegexp.MatchString(location.LeadingComments()) {
+		add(namedDescriptor, location, nil, "%s %q is deprecated and should have a comment explaining what to use instead.", typeName, namedDescriptor.Name())
+	}
+	return nil
+}
+
+// CheckDeprecatedTypeNoUse is a check function.
+var CheckDeprecatedTypeNoUse = newFilesWithImportsCheckFunc(checkDeprecatedTypeNoUse)
+
+func checkDeprecatedTypeNoUse(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+	fullNameToMessage, err := protosource.FullNameToMessage(allFiles...)
+	if err != nil {
+		return err
+	}
+	fullNameToEnum, err := protosource.FullNameToEnum(allFiles...)
+	if err != nil {
+		return err
+	}
+	// getDeprecatedTypeName returns the type name if it refers to a deprecated
+	// message or enum, and the empty string otherwise.
+	var getDeprecatedTypeName func(string) string
+	getDeprecatedTypeName = func(typeName string) string {
+		typeName = strings.TrimPrefix(typeName, ".")
+		if message, ok := fullNameToMessage[typeName]; ok {
+			if message.IsMapEntry() {
+				// check the value type of map fields, as the map entry itself is generated
+				if fields := message.Fields(); len(fields) == 2 {
+					return getDeprecatedTypeName(fields[1].TypeName())
+				}
+				return ""
+			}
+			if message.Deprecated() {
+				return typeName
+			}
+		}
+		if enum, ok := fullNameToEnum[typeName]; ok && enum.Deprecated() {
+			return typeName
+		}
+		return ""
+	}
+	checkField := func(field protosource.Field) {
+		if field.Deprecated() {
+			return
+		}
+		if deprecatedTypeName := getDeprecatedTypeName(field.TypeName()); deprecatedTypeName != "" {
+			add(field, field.TypeNameLocation(), []protosource.Location{field.Location()}, "Field %q uses deprecated type %q but is not deprecated.", field.Name(), deprecatedTypeName)
+		}
+	}
+	for _, file := range files {
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				if message.IsMapEntry() || messageIsDeprecated(message) {
+					// map entries are checked via their map fields, and everything
+					// within a deprecated message is deprecated as well
+					return nil
+				}
+				for _, field := range message.Fields() {
+					checkField(field)
+				}
+				for _, field := range message.Extensions() {
+					checkField(field)
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return err
+		}
+		for _, field := range file.Extensions() {
+			checkField(field)
+		}
+		for _, service := range file.Services() {
+			if service.Deprecated() {
+				continue
+			}
+			for _, method := range service.Methods() {
+				if method.Deprecated() {
+					continue
+				}
+				if deprecatedTypeName := getDeprecatedTypeName(method.InputTypeName()); deprecatedTypeName != "" {
+					add(method, method.InputTypeLocation(), []protosource.Location{method.Location()}, "RPC %q uses deprecated request type %q but is not deprecated.", method.Name(), deprecatedTypeName)
+				}
+				if deprecatedTypeName := getDeprecatedTypeName(method.OutputTypeName()); deprecatedTypeName != "" {
+					add(method, method.OutputTypeLocation(), []protosource.Location{method.Location()}, "RPC %q uses deprecated response type %q but is not deprecated.", method.Name(), deprecatedTypeName)
+				}
+			}
+		}
 	}
 	return nil
 }
 
+// messageIsDeprecated returns true if the message or any of its parents
+// is deprecated.
+func messageIsDeprecated(message protosource.Message) bool {
+	for ; message != nil; message = message.Parent() {
+		if message.Deprecated() {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckDirectorySamePackage is a check function.
 var CheckDirectorySamePackage = newDirToFilesCheckFunc(checkDirectorySamePackage)
 
@@ -168,6 +499,46 @@ func checkEnumValuePrefix(add addFunc, enumValue protosource.EnumValue) error {
 	return nil
 }
 
+// CheckEnumValueNotNegative is a check function.
+var CheckEnumValueNotNegative = newEnumValueCheckFunc(checkEnumValueNotNegative)
+
+func checkEnumValueNotNegative(add addFunc, enumValue protosource.EnumValue) error {
+	if enumValue.Number() < 0 {
+		add(enumValue, enumValue.NumberLocation(), []protosource.Location{enumValue.Location()}, "Enum value %q number %d must not be negative.", enumValue.Name(), enumValue.Number())
+	}
+	return nil
+}
+
+// CheckEnumValueNumberRange is a check function.
+var CheckEnumValueNumberRange = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	allow []internal.NumberRange,
+	forbid []internal.NumberRange,
+	max int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newEnumValueCheckFunc(
+		func(add addFunc, enumValue protosource.EnumValue) error {
+			return checkEnumValueNumberRange(add, enumValue, allow, forbid, max)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkEnumValueNumberRange(
+	add addFunc,
+	enumValue protosource.EnumValue,
+	allow []internal.NumberRange,
+	forbid []internal.NumberRange,
+	max int,
+) error {
+	number := enumValue.Number()
+	if violation := getNumberRangeViolation(number, number, allow, forbid, max); violation != "" {
+		add(enumValue, enumValue.NumberLocation(), []protosource.Location{enumValue.Location()}, "Enum value %q number %d %s.", enumValue.Name(), number, violation)
+	}
+	return nil
+}
+
 // CheckEnumValueUpperSnakeCase is a check function.
 var CheckEnumValueUpperSnakeCase = newEnumValueCheckFunc(checkEnumValueUpperSnakeCase)
 
@@ -248,6 +619,24 @@ func checkFieldLowerSnakeCase(add addFunc, field protosource.Field) error {
 	return nil
 }
 
+// CheckFieldNoDefaultValue is a check function.
+var CheckFieldNoDefaultValue = newFieldCheckFunc(checkFieldNoDefaultValue)
+
+func checkFieldNoDefaultValue(add addFunc, field protosource.Field) error {
+	if field.DefaultValue() != nil {
+		add(
+			field,
+			field.DefaultValueLocation(),
+			[]protosource.Location{
+				field.Location(),
+			},
+			`Field %q must not have a default value.`,
+			field.Name(),
+		)
+	}
+	return nil
+}
+
 // CheckFieldNoDescriptor is a check function.
 var CheckFieldNoDescriptor = newFieldCheckFunc(checkFieldNoDescriptor)
 
@@ -269,6 +658,162 @@ func checkFieldNoDescriptor(add addFunc, field protosource.Field) error {
 	return nil
 }
 
+// CheckFieldNotRequired is a check function.
+var CheckFieldNotRequired = newFieldCheckFunc(checkFieldNotRequired)
+
+func checkFieldNotRequired(add addFunc, field protosource.Field) error {
+	if field.Label() == protosource.FieldDescriptorProtoLabelRequired {
+		add(
+			field,
+			field.LabelLocation(),
+			[]protosource.Location{
+				field.Location(),
+			},
+			`Field %q must not be required.`,
+			field.Name(),
+		)
+	}
+	return nil
+}
+
+// CheckFieldNumberMaxGap is a check function.
+var CheckFieldNumberMaxGap = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	maxGap int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFileCheckFunc(
+		func(add addFunc, file protosource.File) error {
+			for _, message := range file.Messages() {
+				// this does not return groups, as their fields are part of their parent message
+				for _, freeRangeMessage := range protosource.FreeMessageRangeMessages(message) {
+					if err := checkFieldNumberMaxGap(add, freeRangeMessage, maxGap); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkFieldNumberMaxGap(add addFunc, message protosource.Message, maxGap int) error {
+	for _, freeRange := range protosource.FreeMessageRanges(message) {
+		// we only care about gaps between used numbers, not before the first or after the last
+		if freeRange.Start() == 1 || freeRange.Max() {
+			continue
+		}
+		if gap := freeRange.End() - freeRange.Start() + 1; gap > maxGap {
+			add(message, message.NameLocation(), []protosource.Location{message.Location()}, "Message %q has %d unused field numbers from %d to %d, which is more than the maximum gap of %d.", message.Name(), gap, freeRange.Start(), freeRange.End(), maxGap)
+		}
+	}
+	return nil
+}
+
+// CheckFieldNumberRange is a check function.
+var CheckFieldNumberRange = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	allow []internal.NumberRange,
+	forbid []internal.NumberRange,
+	max int,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFileCheckFunc(
+		func(add addFunc, file protosource.File) error {
+			// extensions declared at the top level of the file are not within a message
+			checkFieldNumbersInRange(add, file.Extensions(), allow, forbid, max)
+			return protosource.ForEachMessage(
+				func(message protosource.Message) error {
+					return checkFieldNumberRange(add, message, allow, forbid, max)
+				},
+				file,
+			)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkFieldNumberRange(
+	add addFunc,
+	message protosource.Message,
+	allow []internal.NumberRange,
+	forbid []internal.NumberRange,
+	max int,
+) error {
+	checkFieldNumbersInRange(add, message.Fields(), allow, forbid, max)
+	checkFieldNumbersInRange(add, message.Extensions(), allow, forbid, max)
+	for _, extensionRange := range message.ExtensionMessageRanges() {
+		if violation := getNumberRangeViolation(extensionRange.Start(), extensionRange.End(), allow, forbid, max); violation != "" {
+			add(extensionRange, extensionRange.Location(), []protosource.Location{message.Location()}, "Extension range %s of message %q %s.", getTagRangeString(extensionRange), message.Name(), violation)
+		}
+	}
+	return nil
+}
+
+func checkFieldNumbersInRange(
+	add addFunc,
+	fields []protosource.Field,
+	allow []internal.NumberRange,
+	forbid []internal.NumberRange,
+	max int,
+) {
+	for _, field := range fields {
+		number := field.Number()
+		if violation := getNumberRangeViolation(number, number, allow, forbid, max); violation != "" {
+			add(field, field.NumberLocation(), []protosource.Location{field.Location()}, "Field %q number %d %s.", field.Name(), number, violation)
+		}
+	}
+}
+
+// getNumberRangeViolation returns how the numbers from start to end violate
+// the allowed and forbidden ranges and the maximum, or an empty string if they
+// do not.
+func getNumberRangeViolation(
+	start int,
+	end int,
+	allow []internal.NumberRange,
+	forbid []internal.NumberRange,
+	max int,
+) string {
+	if len(allow) > 0 {
+		allowed := false
+		for _, numberRange := range allow {
+			if numberRange.Contains(start) && numberRange.Contains(end) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			allowStrings := make([]string, len(allow))
+			for i, numberRange := range allow {
+				allowStrings[i] = numberRange.String()
+			}
+			return "is not within the allowed ranges " + strings.Join(allowStrings, ", ")
+		}
+	}
+	for _, numberRange := range forbid {
+		if start <= numberRange.End && end >= numberRange.Start {
+			return "is within the forbidden range " + numberRange.String()
+		}
+	}
+	if max != 0 && end > max {
+		return fmt.Sprintf("exceeds the maximum %d", max)
+	}
+	return ""
+}
+
+// getTagRangeString returns the range using the syntax of ranges in Protobuf files.
+func getTagRangeString(tagRange protosource.TagRange) string {
+	if tagRange.Start() == tagRange.End() {
+		return strconv.Itoa(tagRange.Start())
+	}
+	if tagRange.Max() {
+		return fmt.Sprintf("%d to max", tagRange.Start())
+	}
+	return fmt.Sprintf("%d to %d", tagRange.Start(), tagRange.End())
+}
+
 // CheckFileLowerSnakeCase is a check function.
 var CheckFileLowerSnakeCase = newFileCheckFunc(checkFileLowerSnakeCase)
 
@@ -306,6 +851,91 @@ func checkImportNoPublicWeak(add addFunc, fileImport protosource.FileImport, val
 	return nil
 }
 
+// CheckImportNoTransitive is a check function.
+var CheckImportNoTransitive = newFilesWithImportsCheckFunc(checkImportNoTransitive)
+
+func checkImportNoTransitive(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+	filePathToFile, err := protosource.FilePathToFile(allFiles...)
+	if err != nil {
+		return err
+	}
+	referenceResolver, err := protosource.NewReferenceResolver(allFiles...)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		referencedFilePaths, err := referenceResolver.ReferencedFilePaths(file)
+		if err != nil {
+			return err
+		}
+		importPaths := make(map[string]struct{}, len(file.FileImports()))
+		for _, fileImport := range file.FileImports() {
+			importPaths[fileImport.Import()] = struct{}{}
+		}
+		reportedFilePaths := make(map[string]struct{})
+		for _, fileImport := range file.FileImports() {
+			// the first path is the import itself
+			for _, filePath := range getPublicImportClosure(filePathToFile, fileImport.Import())[1:] {
+				if _, ok := referencedFilePaths[filePath]; !ok {
+					continue
+				}
+				if _, ok := importPaths[filePath]; ok {
+					continue
+				}
+				if _, ok := reportedFilePaths[filePath]; ok {
+					continue
+				}
+				reportedFilePaths[filePath] = struct{}{}
+				add(fileImport, fileImport.Location(), nil, `%q is only imported through the public imports of %q, import it directly.`, filePath, fileImport.Import())
+			}
+		}
+	}
+	return nil
+}
+
+// CheckImportUsed is a check function.
+var CheckImportUsed = newFilesWithImportsCheckFunc(checkImportUsed)
+
+func checkImportUsed(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+	filePathToFile, err := protosource.FilePathToFile(allFiles...)
+	if err != nil {
+		return err
+	}
+	referenceResolver, err := protosource.NewReferenceResolver(allFiles...)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		referencedFilePaths, err := referenceResolver.ReferencedFilePaths(file)
+		if err != nil {
+			return err
+		}
+		for _, fileImport := range file.FileImports() {
+			// public imports are used by the files that import this file
+			if fileImport.IsPublic() {
+				continue
+			}
+			// without the imported file we cannot tell what it defines
+			if _, ok := filePathToFile[fileImport.Import()]; !ok {
+				continue
+			}
+			if !importIsUsed(filePathToFile, referencedFilePaths, fileImport.Import()) {
+				add(fileImport, fileImport.Location(), nil, `Import %q is unused.`, fileImport.Import())
+			}
+		}
+	}
+	return nil
+}
+
+func importIsUsed(filePathToFile map[string]protosource.File, referencedFilePaths map[string]struct{}, importPath string) bool {
+	for _, filePath := range getPublicImportClosure(filePathToFile, importPath) {
+		if _, ok := referencedFilePaths[filePath]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // CheckMessagePascalCase is a check function.
 var CheckMessagePascalCase = newMessageCheckFunc(checkMessagePascalCase)
 
@@ -399,6 +1029,147 @@ func checkPackageLowerSnakeCase(add addFunc, file protosource.File) error {
 	return nil
 }
 
+// CheckPackageNoImportCycle is a check function.
+var CheckPackageNoImportCycle = newFilesWithImportsCheckFunc(checkPackageNoImportCycle)
+
+func checkPackageNoImportCycle(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+	filePathToFile, err := protosource.FilePathToFile(allFiles...)
+	if err != nil {
+		return err
+	}
+	// the package import graph is computed over all files, as cycles
+	// can go through packages that are only imported
+	packageToImportedPackages := make(map[string]map[string]struct{})
+	for _, file := range allFiles {
+		pkg := file.Package()
+		for _, fileImport := range file.FileImports() {
+			importFile, ok := filePathToFile[fileImport.Import()]
+			if !ok {
+				continue
+			}
+			importPkg := importFile.Package()
+			if pkg == "" || importPkg == "" || pkg == importPkg {
+				continue
+			}
+			importedPackages, ok := packageToImportedPackages[pkg]
+			if !ok {
+				importedPackages = make(map[string]struct{})
+				packageToImportedPackages[pkg] = importedPackages
+			}
+			importedPackages[importPkg] = struct{}{}
+		}
+	}
+	for _, file := range files {
+		pkg := file.Package()
+		for _, fileImport := range file.FileImports() {
+			importFile, ok := filePathToFile[fileImport.Import()]
+			if !ok {
+				continue
+			}
+			importPkg := importFile.Package()
+			if pkg == "" || importPkg == "" || pkg == importPkg {
+				continue
+			}
+			if packagePath := getPackagePath(packageToImportedPackages, importPkg, pkg); len(packagePath) > 0 {
+				add(
+					fileImport,
+					fileImport.Location(),
+					nil,
+					`Package import cycle: %s.`,
+					strings.Join(append([]string{pkg}, packagePath...), " -> "),
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// getPackagePath returns the shortest path of imported packages from the package
+// from to the package to, including both, or nil if to is not reachable from from.
+func getPackagePath(packageToImportedPackages map[string]map[string]struct{}, from string, to string) []string {
+	packageToPreviousPackage := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		pkg := queue[0]
+		queue = queue[1:]
+		if pkg == to {
+			var packagePath []string
+			for ; pkg != ""; pkg = packageToPreviousPackage[pkg] {
+				packagePath = append([]string{pkg}, packagePath...)
+			}
+			return packagePath
+		}
+		importedPackages := make([]string, 0, len(packageToImportedPackages[pkg]))
+		for importedPackage := range packageToImportedPackages[pkg] {
+			importedPackages = append(importedPackages, importedPackage)
+		}
+		// sort so that the reported path is deterministic
+		sort.Strings(importedPackages)
+		for _, importedPackage := range importedPackages {
+			if _, ok := packageToPreviousPackage[importedPackage]; !ok {
+				packageToPreviousPackage[importedPackage] = pkg
+				queue = append(queue, importedPackage)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckPackageNoImportRestricted is a check function.
+var CheckPackageNoImportRestricted = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	packageImportRestrictions []internal.PackageImportRestriction,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesWithImportsCheckFunc(
+		func(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+			return checkPackageNoImportRestricted(add, files, allFiles, packageImportRestrictions)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkPackageNoImportRestricted(
+	add addFunc,
+	files []protosource.File,
+	allFiles []protosource.File,
+	packageImportRestrictions []internal.PackageImportRestriction,
+) error {
+	if len(packageImportRestrictions) == 0 {
+		return nil
+	}
+	filePathToFile, err := protosource.FilePathToFile(allFiles...)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		pkg := file.Package()
+		for _, fileImport := range file.FileImports() {
+			importFile, ok := filePathToFile[fileImport.Import()]
+			if !ok {
+				continue
+			}
+			importPkg := importFile.Package()
+			for _, packageImportRestriction := range packageImportRestrictions {
+				if packageImportRestriction.Matches(pkg, importPkg) {
+					add(
+						fileImport,
+						fileImport.Location(),
+						nil,
+						`Package %q may not import package %q, packages matching %q may not import packages matching %q.`,
+						pkg,
+						importPkg,
+						packageImportRestriction.From,
+						packageImportRestriction.Disallow,
+					)
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // CheckPackageSameDirectory is a check function.
 var CheckPackageSameDirectory = newPackageToFilesCheckFunc(checkPackageSameDirectory)
 
@@ -511,6 +1282,174 @@ func checkPackageVersionSuffix(add addFunc, file protosource.File) error {
 	return nil
 }
 
+// requiredOptionOnToOptionsFullName maps the kinds of elements options
+// can be required on to the full name of their options message.
+var requiredOptionOnToOptionsFullName = map[string]string{
+	internal.RequiredOptionOnFile:      "google.protobuf.FileOptions",
+	internal.RequiredOptionOnMessage:   "google.protobuf.MessageOptions",
+	internal.RequiredOptionOnField:     "google.protobuf.FieldOptions",
+	internal.RequiredOptionOnOneof:     "google.protobuf.OneofOptions",
+	internal.RequiredOptionOnEnum:      "google.protobuf.EnumOptions",
+	internal.RequiredOptionOnEnumValue: "google.protobuf.EnumValueOptions",
+	internal.RequiredOptionOnService:   "google.protobuf.ServiceOptions",
+	internal.RequiredOptionOnRPC:       "google.protobuf.MethodOptions",
+}
+
+// namedOptionExtensionDescriptor is a named descriptor that can have custom options set.
+type namedOptionExtensionDescriptor interface {
+	protosource.NamedDescriptor
+	protosource.OptionExtensionDescriptor
+}
+
+// CheckRequireOptions is a check function.
+var CheckRequireOptions = func(
+	id string,
+	ignoreFunc internal.IgnoreFunc,
+	files []protosource.File,
+	requiredOptions []internal.RequiredOption,
+) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesWithImportsCheckFunc(
+		func(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+			return checkRequireOptions(add, files, allFiles, requiredOptions)
+		},
+	)(id, ignoreFunc, files)
+}
+
+func checkRequireOptions(
+	add addFunc,
+	files []protosource.File,
+	allFiles []protosource.File,
+	requiredOptions []internal.RequiredOption,
+) error {
+	if len(requiredOptions) == 0 {
+		return nil
+	}
+	extensionTypeResolver, err := protosource.NewExtensionTypeResolver(allFiles...)
+	if err != nil {
+		return err
+	}
+	for _, requiredOption := range requiredOptions {
+		// if the extension is not defined in any file, it cannot be set,
+		// so all matching elements are missing the option
+		extensionType, err := extensionTypeResolver.FindExtensionByName(protoreflect.FullName(requiredOption.Option))
+		if err != nil && !errors.Is(err, protoregistry.NotFound) {
+			return err
+		}
+		if extensionType != nil {
+			if extendee := string(extensionType.TypeDescriptor().ContainingMessage().FullName()); extendee != requiredOptionOnToOptionsFullName[requiredOption.On] {
+				return fmt.Errorf("required option %q extends %q and cannot be required on %s", requiredOption.Option, extendee, requiredOption.On)
+			}
+		}
+		isSet := func(optionExtensionDescriptor protosource.OptionExtensionDescriptor) bool {
+			if extensionType == nil {
+				return false
+			}
+			_, ok := optionExtensionDescriptor.OptionExtension(extensionType)
+			return ok
+		}
+		for _, file := range files {
+			if !requiredOption.MatchesPackage(file.Package()) {
+				continue
+			}
+			if requiredOption.On == internal.RequiredOptionOnFile {
+				if !isSet(file) {
+					add(file, file.PackageLocation(), nil, "File must set option \"(%s)\".", requiredOption.Option)
+				}
+				continue
+			}
+			descriptors, typeName, err := getRequiredOptionDescriptors(file, requiredOption.On)
+			if err != nil {
+				return err
+			}
+			for _, descriptor := range descriptors {
+				if !isSet(descriptor) {
+					add(descriptor, descriptor.NameLocation(), []protosource.Location{descriptor.Location()}, "%s %q must set option \"(%s)\".", typeName, descriptor.Name(), requiredOption.Option)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getRequiredOptionDescriptors returns the descriptors of the file of the given kind
+// and the name of the kind to use in messages.
+//
+// Map entries and their fields are not returned, as they cannot have options set.
+func getRequiredOptionDescriptors(file protosource.File, on string) ([]namedOptionExtensionDescriptor, string, error) {
+	var descriptors []namedOptionExtensionDescriptor
+	switch on {
+	case internal.RequiredOptionOnMessage, internal.RequiredOptionOnField, internal.RequiredOptionOnOneof:
+		if err := protosource.ForEachMessage(
+			func(message protosource.Message) error {
+				if message.IsMapEntry() {
+					return nil
+				}
+				switch on {
+				case internal.RequiredOptionOnMessage:
+					descriptors = append(descriptors, message)
+				case internal.RequiredOptionOnField:
+					for _, field := range message.Fields() {
+						descriptors = append(descriptors, field)
+					}
+					for _, field := range message.Extensions() {
+						descriptors = append(descriptors, field)
+					}
+				case internal.RequiredOptionOnOneof:
+					for _, oneof := range message.Oneofs() {
+						descriptors = append(descriptors, oneof)
+					}
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, "", err
+		}
+		switch on {
+		case internal.RequiredOptionOnMessage:
+			return descriptors, "Message", nil
+		case internal.RequiredOptionOnField:
+			return descriptors, "Field", nil
+		default:
+			return descriptors, "Oneof", nil
+		}
+	case internal.RequiredOptionOnEnum, internal.RequiredOptionOnEnumValue:
+		if err := protosource.ForEachEnum(
+			func(enum protosource.Enum) error {
+				if on == internal.RequiredOptionOnEnum {
+					descriptors = append(descriptors, enum)
+					return nil
+				}
+				for _, enumValue := range enum.Values() {
+					descriptors = append(descriptors, enumValue)
+				}
+				return nil
+			},
+			file,
+		); err != nil {
+			return nil, "", err
+		}
+		if on == internal.RequiredOptionOnEnum {
+			return descriptors, "Enum", nil
+		}
+		return descriptors, "Enum value", nil
+	case internal.RequiredOptionOnService:
+		for _, service := range file.Services() {
+			descriptors = append(descriptors, service)
+		}
+		return descriptors, "Service", nil
+	case internal.RequiredOptionOnRPC:
+		for _, service := range file.Services() {
+			for _, method := range service.Methods() {
+				descriptors = append(descriptors, method)
+			}
+		}
+		return descriptors, "RPC", nil
+	default:
+		return nil, "", fmt.Errorf("unknown required option on: %q", on)
+	}
+}
+
 // CheckRPCNoClientStreaming is a check function.
 var CheckRPCNoClientStreaming = newMethodCheckFunc(checkRPCNoClientStreaming)
 
@@ -846,3 +1785,51 @@ func checkServiceSuffix(add addFunc, service protosource.Service, suffix string)
 	}
 	return nil
 }
+
+// CheckSyntaxSpecified is a check function.
+var CheckSyntaxSpecified = newFileCheckFunc(checkSyntaxSpecified)
+
+func checkSyntaxSpecified(add addFunc, file protosource.File) error {
+	// files without a syntax statement are proto2, and only an explicit
+	// syntax statement has a location
+	if file.Syntax() == protosource.SyntaxProto2 && file.SyntaxLocation() == nil {
+		add(file, nil, nil, `Files must have a syntax explicitly specified. If no syntax is specified, the file defaults to "proto2".`)
+	}
+	return nil
+}
+
+// CheckStablePackageNoImportUnstable is a check function.
+var CheckStablePackageNoImportUnstable = newFilesWithImportsCheckFunc(checkStablePackageNoImportUnstable)
+
+func checkStablePackageNoImportUnstable(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+	filePathToFile, err := protosource.FilePathToFile(allFiles...)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		packageVersion, ok := protoversion.NewPackageVersionForPackage(file.Package())
+		if !ok || packageVersion.StabilityLevel() != protoversion.StabilityLevelStable {
+			continue
+		}
+		for _, fileImport := range file.FileImports() {
+			importFile, ok := filePathToFile[fileImport.Import()]
+			if !ok {
+				continue
+			}
+			importPackageVersion, ok := protoversion.NewPackageVersionForPackage(importFile.Package())
+			if !ok || importPackageVersion.StabilityLevel() == protoversion.StabilityLevelStable {
+				continue
+			}
+			add(
+				fileImport,
+				fileImport.Location(),
+				nil,
+				`Stable package %q must not import %q from unstable package %q.`,
+				file.Package(),
+				fileImport.Import(),
+				importFile.Package(),
+			)
+		}
+	}
+	return nil
+}
@@ -43,9 +43,27 @@ func fieldToUpperSnakeCase(s string) string {
 func newFilesCheckFunc(
 	f func(addFunc, []protosource.File) error,
 ) func(string, internal.IgnoreFunc, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
-	return func(id string, ignoreFunc internal.IgnoreFunc, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesWithImportsCheckFunc(
+		func(add addFunc, files []protosource.File, _ []protosource.File) error {
+			return f(add, files)
+		},
+	)
+}
+
+// newFilesWithImportsCheckFunc calls f with the files to check and all
+// files including imports, which can be used to resolve references.
+func newFilesWithImportsCheckFunc(
+	f func(add addFunc, files []protosource.File, allFiles []protosource.File) error,
+) func(string, internal.IgnoreFunc, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return func(id string, ignoreFunc internal.IgnoreFunc, allFiles []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+		files := make([]protosource.File, 0, len(allFiles))
+		for _, file := range allFiles {
+			if !file.IsImport() {
+				files = append(files, file)
+			}
+		}
 		helper := internal.NewHelper(id, ignoreFunc)
-		if err := f(helper.AddFileAnnotationWithExtraIgnoreLocationsf, files); err != nil {
+		if err := f(helper.AddFileAnnotationWithExtraIgnoreLocationsf, files, allFiles); err != nil {
 			return nil, err
 		}
 		return helper.FileAnnotations(), nil
@@ -230,3 +248,136 @@ func newMethodCheckFunc(
 		},
 	)
 }
+
+// newCommentNamedDescriptorCheckFunc calls f for each element that the
+// COMMENT_* rules require a comment for, along with its type name.
+func newCommentNamedDescriptorCheckFunc(
+	f func(addFunc, protosource.NamedDescriptor, string) error,
+) func(string, internal.IgnoreFunc, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return newFileCheckFunc(
+		func(add addFunc, file protosource.File) error {
+			if err := protosource.ForEachEnum(
+				func(enum protosource.Enum) error {
+					if err := f(add, enum, "Enum"); err != nil {
+						return err
+					}
+					for _, enumValue := range enum.Values() {
+						if err := f(add, enumValue, "Enum value"); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+				file,
+			); err != nil {
+				return err
+			}
+			if err := protosource.ForEachMessage(
+				func(message protosource.Message) error {
+					if err := f(add, message, "Message"); err != nil {
+						return err
+					}
+					for _, field := range message.Fields() {
+						if err := f(add, field, "Field"); err != nil {
+							return err
+						}
+					}
+					for _, field := range message.Extensions() {
+						if err := f(add, field, "Field"); err != nil {
+							return err
+						}
+					}
+					for _, oneof := range message.Oneofs() {
+						if err := f(add, oneof, "Oneof"); err != nil {
+							return err
+						}
+					}
+					return nil
+				},
+				file,
+			); err != nil {
+				return err
+			}
+			for _, service := range file.Services() {
+				if err := f(add, service, "Service"); err != nil {
+					return err
+				}
+				for _, method := range service.Methods() {
+					if err := f(add, method, "RPC"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// newCommentCheckFunc returns the check function of the COMMENT_* rule for
+// the elements with the type name, as given by newCommentNamedDescriptorCheckFunc.
+func newCommentCheckFunc(
+	typeName string,
+) func(string, internal.IgnoreFunc, []protosource.File, int) ([]bufanalysis.FileAnnotation, error) {
+	return func(id string, ignoreFunc internal.IgnoreFunc, files []protosource.File, minLength int) ([]bufanalysis.FileAnnotation, error) {
+		return newCommentNamedDescriptorCheckFunc(
+			func(add addFunc, namedDescriptor protosource.NamedDescriptor, namedDescriptorTypeName string) error {
+				if namedDescriptorTypeName != typeName {
+					return nil
+				}
+				return checkCommentNamedDescriptor(add, namedDescriptor, typeName, minLength)
+			},
+		)(id, ignoreFunc, files)
+	}
+}
+
+// newMethodWithMessagesCheckFunc calls f for each method with a map from
+// full name to Message for all files including imports, so that the
+// request and response messages can be inspected.
+func newMethodWithMessagesCheckFunc(
+	f func(addFunc, protosource.Method, map[string]protosource.Message) error,
+) func(string, internal.IgnoreFunc, []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+	return newFilesWithImportsCheckFunc(
+		func(add addFunc, files []protosource.File, allFiles []protosource.File) error {
+			fullNameToMessage, err := protosource.FullNameToMessage(allFiles...)
+			if err != nil {
+				return err
+			}
+			for _, file := range files {
+				for _, service := range file.Services() {
+					for _, method := range service.Methods() {
+						if err := f(add, method, fullNameToMessage); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return nil
+		},
+	)
+}
+
+// getPublicImportClosure returns the import path followed by the paths of all
+// files reachable from it through public imports.
+//
+// Files that are not in filePathToFile are not traversed.
+func getPublicImportClosure(filePathToFile map[string]protosource.File, importPath string) []string {
+	filePaths := []string{importPath}
+	seen := map[string]struct{}{importPath: {}}
+	for i := 0; i < len(filePaths); i++ {
+		file, ok := filePathToFile[filePaths[i]]
+		if !ok {
+			continue
+		}
+		for _, fileImport := range file.FileImports() {
+			if !fileImport.IsPublic() {
+				continue
+			}
+			if _, ok := seen[fileImport.Import()]; ok {
+				continue
+			}
+			seen[fileImport.Import()] = struct{}{}
+			filePaths = append(filePaths, fileImport.Import())
+		}
+	}
+	return filePaths
+}
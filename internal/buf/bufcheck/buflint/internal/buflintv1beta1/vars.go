@@ -22,6 +22,10 @@ import (
 var (
 	// v1beta1RuleBuilders are the rule builders.
 	v1beta1RuleBuilders = []*internal.RuleBuilder{
+		buflintbuild.AIPHTTPAnnotationRuleBuilder,
+		buflintbuild.AIPListPaginationRuleBuilder,
+		buflintbuild.AIPStandardMethodNamesRuleBuilder,
+		buflintbuild.AIPUpdateMaskRuleBuilder,
 		buflintbuild.CommentEnumRuleBuilder,
 		buflintbuild.CommentEnumValueRuleBuilder,
 		buflintbuild.CommentFieldRuleBuilder,
@@ -29,23 +33,36 @@ var (
 		buflintbuild.CommentOneofRuleBuilder,
 		buflintbuild.CommentRPCRuleBuilder,
 		buflintbuild.CommentServiceRuleBuilder,
+		buflintbuild.CommentStartsWithNameRuleBuilder,
+		buflintbuild.DeprecatedCommentRuleBuilder,
+		buflintbuild.DeprecatedTypeNoUseRuleBuilder,
 		buflintbuild.DirectorySamePackageRuleBuilder,
 		buflintbuild.EnumFirstValueZeroRuleBuilder,
 		buflintbuild.EnumNoAllowAliasRuleBuilder,
 		buflintbuild.EnumPascalCaseRuleBuilder,
+		buflintbuild.EnumValueNotNegativeRuleBuilder,
+		buflintbuild.EnumValueNumberRangeRuleBuilder,
 		buflintbuild.EnumValuePrefixRuleBuilder,
 		buflintbuild.EnumValueUpperSnakeCaseRuleBuilder,
 		buflintbuild.EnumZeroValueSuffixRuleBuilder,
 		buflintbuild.FieldLowerSnakeCaseRuleBuilder,
+		buflintbuild.FieldNoDefaultValueRuleBuilder,
 		buflintbuild.FieldNoDescriptorRuleBuilder,
+		buflintbuild.FieldNotRequiredRuleBuilder,
+		buflintbuild.FieldNumberMaxGapRuleBuilder,
+		buflintbuild.FieldNumberRangeRuleBuilder,
 		buflintbuild.FileLowerSnakeCaseRuleBuilder,
 		buflintbuild.ImportNoPublicRuleBuilder,
 		buflintbuild.ImportNoWeakRuleBuilder,
+		buflintbuild.ImportNoTransitiveRuleBuilder,
+		buflintbuild.ImportUsedRuleBuilder,
 		buflintbuild.MessagePascalCaseRuleBuilder,
 		buflintbuild.OneofLowerSnakeCaseRuleBuilder,
 		buflintbuild.PackageDefinedRuleBuilder,
 		buflintbuild.PackageDirectoryMatchRuleBuilder,
 		buflintbuild.PackageLowerSnakeCaseRuleBuilder,
+		buflintbuild.PackageNoImportCycleRuleBuilder,
+		buflintbuild.PackageNoImportRestrictedRuleBuilder,
 		buflintbuild.PackageSameCsharpNamespaceRuleBuilder,
 		buflintbuild.PackageSameDirectoryRuleBuilder,
 		buflintbuild.PackageSameGoPackageRuleBuilder,
@@ -55,6 +72,7 @@ var (
 		buflintbuild.PackageSameRubyPackageRuleBuilder,
 		buflintbuild.PackageSameSwiftPrefixRuleBuilder,
 		buflintbuild.PackageVersionSuffixRuleBuilder,
+		buflintbuild.RequireOptionsRuleBuilder,
 		buflintbuild.RPCNoClientStreamingRuleBuilder,
 		buflintbuild.RPCNoServerStreamingRuleBuilder,
 		buflintbuild.RPCPascalCaseRuleBuilder,
@@ -63,6 +81,8 @@ var (
 		buflintbuild.RPCResponseStandardNameRuleBuilder,
 		buflintbuild.ServicePascalCaseRuleBuilder,
 		buflintbuild.ServiceSuffixRuleBuilder,
+		buflintbuild.StablePackageNoImportUnstableRuleBuilder,
+		buflintbuild.SyntaxSpecifiedRuleBuilder,
 	}
 
 	// v1beta1DefaultCategories are the default categories.
@@ -76,8 +96,10 @@ var (
 		"DEFAULT",
 		"COMMENTS",
 		"UNARY_RPC",
+		"AIP",
 		"FILE_LAYOUT",
 		"PACKAGE_AFFINITY",
+		"PROTO_HYGIENE",
 		"SENSIBLE",
 		"STYLE_BASIC",
 		"STYLE_DEFAULT",
@@ -85,6 +107,18 @@ var (
 	}
 	// v1beta1IDToCategories are the ID to categories.
 	v1beta1IDToCategories = map[string][]string{
+		"AIP_HTTP_ANNOTATION": {
+			"AIP",
+		},
+		"AIP_LIST_PAGINATION": {
+			"AIP",
+		},
+		"AIP_STANDARD_METHOD_NAMES": {
+			"AIP",
+		},
+		"AIP_UPDATE_MASK": {
+			"AIP",
+		},
 		"COMMENT_ENUM": {
 			"COMMENTS",
 		},
@@ -106,6 +140,15 @@ var (
 		"COMMENT_SERVICE": {
 			"COMMENTS",
 		},
+		"COMMENT_STARTS_WITH_NAME": {
+			"OTHER",
+		},
+		"DEPRECATED_COMMENT": {
+			"OTHER",
+		},
+		"DEPRECATED_TYPE_NO_USE": {
+			"OTHER",
+		},
 		"DIRECTORY_SAME_PACKAGE": {
 			"MINIMAL",
 			"BASIC",
@@ -127,6 +170,12 @@ var (
 			"STYLE_BASIC",
 			"STYLE_DEFAULT",
 		},
+		"ENUM_VALUE_NOT_NEGATIVE": {
+			"PROTO_HYGIENE",
+		},
+		"ENUM_VALUE_NUMBER_RANGE": {
+			"OTHER",
+		},
 		"ENUM_VALUE_PREFIX": {
 			"DEFAULT",
 			"STYLE_DEFAULT",
@@ -147,12 +196,24 @@ var (
 			"STYLE_BASIC",
 			"STYLE_DEFAULT",
 		},
+		"FIELD_NO_DEFAULT_VALUE": {
+			"PROTO_HYGIENE",
+		},
 		"FIELD_NO_DESCRIPTOR": {
 			"MINIMAL",
 			"BASIC",
 			"DEFAULT",
 			"SENSIBLE",
 		},
+		"FIELD_NOT_REQUIRED": {
+			"PROTO_HYGIENE",
+		},
+		"FIELD_NUMBER_MAX_GAP": {
+			"OTHER",
+		},
+		"FIELD_NUMBER_RANGE": {
+			"OTHER",
+		},
 		"FILE_LOWER_SNAKE_CASE": {
 			"DEFAULT",
 			"STYLE_DEFAULT",
@@ -169,6 +230,12 @@ var (
 			"DEFAULT",
 			"SENSIBLE",
 		},
+		"IMPORT_NO_TRANSITIVE": {
+			"OTHER",
+		},
+		"IMPORT_USED": {
+			"OTHER",
+		},
 		"MESSAGE_PASCAL_CASE": {
 			"BASIC",
 			"DEFAULT",
@@ -199,6 +266,12 @@ var (
 			"STYLE_BASIC",
 			"STYLE_DEFAULT",
 		},
+		"PACKAGE_NO_IMPORT_CYCLE": {
+			"OTHER",
+		},
+		"PACKAGE_NO_IMPORT_RESTRICTED": {
+			"OTHER",
+		},
 		"PACKAGE_SAME_CSHARP_NAMESPACE": {
 			"MINIMAL",
 			"BASIC",
@@ -251,6 +324,9 @@ var (
 			"DEFAULT",
 			"STYLE_DEFAULT",
 		},
+		"REQUIRE_OPTIONS": {
+			"OTHER",
+		},
 		"RPC_NO_CLIENT_STREAMING": {
 			"UNARY_RPC",
 		},
@@ -285,5 +361,11 @@ var (
 			"DEFAULT",
 			"STYLE_DEFAULT",
 		},
+		"STABLE_PACKAGE_NO_IMPORT_UNSTABLE": {
+			"OTHER",
+		},
+		"SYNTAX_SPECIFIED": {
+			"PROTO_HYGIENE",
+		},
 	}
 )
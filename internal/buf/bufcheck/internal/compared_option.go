@@ -0,0 +1,38 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func validateComparedOptions(comparedOptions []string) error {
+	seen := make(map[string]struct{}, len(comparedOptions))
+	for _, comparedOption := range comparedOptions {
+		if comparedOption == "" {
+			return errors.New("compared option is empty")
+		}
+		if strings.HasPrefix(comparedOption, "(") || strings.HasPrefix(comparedOption, ".") {
+			return fmt.Errorf("compared option %q must be a full name without parentheses or a leading dot", comparedOption)
+		}
+		if _, ok := seen[comparedOption]; ok {
+			return fmt.Errorf("duplicate compared option %q", comparedOption)
+		}
+		seen[comparedOption] = struct{}{}
+	}
+	return nil
+}
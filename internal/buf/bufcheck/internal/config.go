@@ -15,10 +15,12 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
 )
@@ -26,6 +28,7 @@ import (
 const (
 	defaultEnumZeroValueSuffix = "_UNSPECIFIED"
 	defaultServiceSuffix       = "Service"
+	defaultFieldNumberMaxGap   = 10
 )
 
 // Config is the check config.
@@ -39,8 +42,22 @@ type Config struct {
 	IgnoreRootPaths     map[string]struct{}
 	IgnoreIDToRootPaths map[string]map[string]struct{}
 
+	// IDToSeverity is the map from rule id to the severity of its FileAnnotations.
+	//
+	// Rules that are not in this map have bufanalysis.SeverityError.
+	IDToSeverity map[string]bufanalysis.Severity
+
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
+
+	// EnumZeroValueSuffix and ServiceSuffix are the suffixes used by the rules,
+	// with the defaults applied.
+	EnumZeroValueSuffix string
+	ServiceSuffix       string
+
+	// ComparedOptions are the full names of the custom options compared by
+	// the rules.
+	ComparedOptions []string
 }
 
 // ConfigBuilder is a config builder.
@@ -51,39 +68,173 @@ type ConfigBuilder struct {
 	IgnoreRootPaths               []string
 	IgnoreIDOrCategoryToRootPaths map[string][]string
 
+	IDOrCategoryToSeverity map[string]string
+
 	AllowCommentIgnores    bool
 	IgnoreUnstablePackages bool
 
+	CommentMinLength                     int
+	EnumValueNumberRanges                NumberRangePolicy
 	EnumZeroValueSuffix                  string
+	FieldNumberMaxGap                    int
+	FieldNumberRanges                    NumberRangePolicy
 	RPCAllowSameRequestResponse          bool
 	RPCAllowGoogleProtobufEmptyRequests  bool
 	RPCAllowGoogleProtobufEmptyResponses bool
 	ServiceSuffix                        string
+	PackageImportRestrictions            []PackageImportRestriction
+	RequiredOptions                      []RequiredOption
+	ComparedOptions                      []string
+
+	// Plugins are the external plugins that provide additional rules.
+	//
+	// The rules of the plugins are only listed by NewConfigWithPlugins.
+	Plugins []Plugin
 }
 
 // NewConfig returns a new Config.
+//
+// The rules of the Plugins are not listed, as this runs the plugins. If there
+// are Plugins, ids and categories that are not built-in are ignored, as they
+// may be provided by the plugins.
 func (b ConfigBuilder) NewConfig(versionSpec *VersionSpec) (*Config, error) {
 	return newConfig(b, versionSpec)
 }
 
+// NewConfigWithPlugins returns a new Config with the rules of the Plugins.
+//
+// The plugins are run to list their rules.
+func (b ConfigBuilder) NewConfigWithPlugins(ctx context.Context, versionSpec *VersionSpec) (*Config, error) {
+	return newConfigWithPlugins(ctx, b, versionSpec)
+}
+
 func newConfig(configBuilder ConfigBuilder, versionSpec *VersionSpec) (*Config, error) {
+	configBuilder, err := normalizeAndValidateConfigBuilder(configBuilder, versionSpec)
+	if err != nil {
+		return nil, err
+	}
+	if len(configBuilder.Plugins) > 0 {
+		configBuilder = configBuilderWithOnlyKnownIDsOrCategories(configBuilder, versionSpec.IDToCategories)
+	}
+	return newConfigForRuleBuilders(
+		configBuilder,
+		versionSpec.RuleBuilders,
+		versionSpec.IDToCategories,
+	)
+}
+
+func newConfigWithPlugins(ctx context.Context, configBuilder ConfigBuilder, versionSpec *VersionSpec) (*Config, error) {
+	configBuilder, err := normalizeAndValidateConfigBuilder(configBuilder, versionSpec)
+	if err != nil {
+		return nil, err
+	}
+	pluginRuleBuilders, pluginIDToCategories, err := newPluginRuleBuilders(
+		ctx,
+		configBuilder.Plugins,
+		versionSpec.RuleType,
+		versionSpec.IDToCategories,
+	)
+	if err != nil {
+		return nil, err
+	}
+	ruleBuilders := append(append([]*RuleBuilder{}, versionSpec.RuleBuilders...), pluginRuleBuilders...)
+	idToCategories := make(map[string][]string, len(versionSpec.IDToCategories)+len(pluginIDToCategories))
+	for id, categories := range versionSpec.IDToCategories {
+		idToCategories[id] = categories
+	}
+	for id, categories := range pluginIDToCategories {
+		idToCategories[id] = categories
+	}
+	return newConfigForRuleBuilders(
+		configBuilder,
+		ruleBuilders,
+		idToCategories,
+	)
+}
+
+func normalizeAndValidateConfigBuilder(configBuilder ConfigBuilder, versionSpec *VersionSpec) (ConfigBuilder, error) {
 	configBuilder.Use = stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(configBuilder.Use)
 	configBuilder.Except = stringutil.SliceToUniqueSortedSliceFilterEmptyStrings(configBuilder.Except)
 	if len(configBuilder.Use) == 0 {
 		// default behavior
 		configBuilder.Use = versionSpec.DefaultCategories
 	}
+	if configBuilder.CommentMinLength < 0 {
+		return ConfigBuilder{}, fmt.Errorf("comment_min_length must not be negative: %d", configBuilder.CommentMinLength)
+	}
 	if configBuilder.EnumZeroValueSuffix == "" {
 		configBuilder.EnumZeroValueSuffix = defaultEnumZeroValueSuffix
 	}
+	if configBuilder.FieldNumberMaxGap < 0 {
+		return ConfigBuilder{}, fmt.Errorf("field_number_max_gap must not be negative: %d", configBuilder.FieldNumberMaxGap)
+	}
+	if configBuilder.FieldNumberMaxGap == 0 {
+		configBuilder.FieldNumberMaxGap = defaultFieldNumberMaxGap
+	}
+	if err := validateNumberRangePolicy("field_number_ranges", configBuilder.FieldNumberRanges); err != nil {
+		return ConfigBuilder{}, err
+	}
+	if err := validateNumberRangePolicy("enum_value_number_ranges", configBuilder.EnumValueNumberRanges); err != nil {
+		return ConfigBuilder{}, err
+	}
 	if configBuilder.ServiceSuffix == "" {
 		configBuilder.ServiceSuffix = defaultServiceSuffix
 	}
-	return newConfigForRuleBuilders(
-		configBuilder,
-		versionSpec.RuleBuilders,
-		versionSpec.IDToCategories,
-	)
+	if err := validatePackageImportRestrictions(configBuilder.PackageImportRestrictions); err != nil {
+		return ConfigBuilder{}, err
+	}
+	if err := validateRequiredOptions(configBuilder.RequiredOptions); err != nil {
+		return ConfigBuilder{}, err
+	}
+	if err := validateComparedOptions(configBuilder.ComparedOptions); err != nil {
+		return ConfigBuilder{}, err
+	}
+	if err := validatePlugins(configBuilder.Plugins); err != nil {
+		return ConfigBuilder{}, err
+	}
+	return configBuilder, nil
+}
+
+// configBuilderWithOnlyKnownIDsOrCategories returns a copy of the ConfigBuilder
+// without the ids and categories that are not in idToCategories.
+func configBuilderWithOnlyKnownIDsOrCategories(configBuilder ConfigBuilder, idToCategories map[string][]string) ConfigBuilder {
+	categoryToIDs := getCategoryToIDs(idToCategories)
+	isKnown := func(idOrCategory string) bool {
+		if _, ok := idToCategories[idOrCategory]; ok {
+			return true
+		}
+		_, ok := categoryToIDs[idOrCategory]
+		return ok
+	}
+	var use []string
+	for _, idOrCategory := range configBuilder.Use {
+		if isKnown(idOrCategory) {
+			use = append(use, idOrCategory)
+		}
+	}
+	var except []string
+	for _, idOrCategory := range configBuilder.Except {
+		if isKnown(idOrCategory) {
+			except = append(except, idOrCategory)
+		}
+	}
+	ignoreIDOrCategoryToRootPaths := make(map[string][]string)
+	for idOrCategory, rootPaths := range configBuilder.IgnoreIDOrCategoryToRootPaths {
+		if isKnown(idOrCategory) {
+			ignoreIDOrCategoryToRootPaths[idOrCategory] = rootPaths
+		}
+	}
+	idOrCategoryToSeverity := make(map[string]string)
+	for idOrCategory, severity := range configBuilder.IDOrCategoryToSeverity {
+		if isKnown(idOrCategory) {
+			idOrCategoryToSeverity[idOrCategory] = severity
+		}
+	}
+	configBuilder.Use = use
+	configBuilder.Except = except
+	configBuilder.IgnoreIDOrCategoryToRootPaths = ignoreIDOrCategoryToRootPaths
+	configBuilder.IDOrCategoryToSeverity = idOrCategoryToSeverity
+	return configBuilder
 }
 
 func newConfigForRuleBuilders(
@@ -169,6 +320,11 @@ func newConfigForRuleBuilders(
 		}
 	}
 
+	idToSeverity, err := transformToIDToSeverity(configBuilder.IDOrCategoryToSeverity, idToCategories, categoryToIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	ignoreRootPaths := make(map[string]struct{}, len(configBuilder.IgnoreRootPaths))
 	for _, rootPath := range configBuilder.IgnoreRootPaths {
 		if rootPath == "" {
@@ -188,8 +344,12 @@ func newConfigForRuleBuilders(
 		Rules:                  resultRules,
 		IgnoreIDToRootPaths:    ignoreIDToRootPaths,
 		IgnoreRootPaths:        ignoreRootPaths,
+		IDToSeverity:           idToSeverity,
 		AllowCommentIgnores:    configBuilder.AllowCommentIgnores,
 		IgnoreUnstablePackages: configBuilder.IgnoreUnstablePackages,
+		EnumZeroValueSuffix:    configBuilder.EnumZeroValueSuffix,
+		ServiceSuffix:          configBuilder.ServiceSuffix,
+		ComparedOptions:        configBuilder.ComparedOptions,
 	}, nil
 }
 
@@ -249,6 +409,48 @@ func transformToIDToListMap(idOrCategoryToList map[string][]string, idToCategori
 	return idToListMap, nil
 }
 
+// transformToIDToSeverity resolves the severities for ids and categories to
+// severities for ids.
+//
+// A severity for an id takes precedence over a severity for a category. If
+// an id is in multiple categories with different severities, the most severe
+// is used.
+func transformToIDToSeverity(idOrCategoryToSeverity map[string]string, idToCategories map[string][]string, categoryToIDs map[string][]string) (map[string]bufanalysis.Severity, error) {
+	if len(idOrCategoryToSeverity) == 0 {
+		return nil, nil
+	}
+	idToSeverity := make(map[string]bufanalysis.Severity)
+	idToCategorySeverity := make(map[string]bufanalysis.Severity)
+	for idOrCategory, severityString := range idOrCategoryToSeverity {
+		if idOrCategory == "" {
+			continue
+		}
+		severity, err := bufanalysis.ParseSeverity(severityString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity for %q: %v", idOrCategory, err)
+		}
+		if _, ok := idToCategories[idOrCategory]; ok {
+			id := idOrCategory
+			idToSeverity[id] = severity
+		} else if ids, ok := categoryToIDs[idOrCategory]; ok {
+			for _, id := range ids {
+				// lower values are more severe
+				if existingSeverity, ok := idToCategorySeverity[id]; !ok || severity < existingSeverity {
+					idToCategorySeverity[id] = severity
+				}
+			}
+		} else {
+			return nil, fmt.Errorf("%q is not a known id or category", idOrCategory)
+		}
+	}
+	for id, severity := range idToCategorySeverity {
+		if _, ok := idToSeverity[id]; !ok {
+			idToSeverity[id] = severity
+		}
+	}
+	return idToSeverity, nil
+}
+
 func getCategoryToIDs(idToCategories map[string][]string) map[string][]string {
 	categoryToIDs := make(map[string][]string)
 	for id, categories := range idToCategories {
@@ -0,0 +1,117 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// NumberRangePolicy restricts the numbers of fields or enum values.
+type NumberRangePolicy struct {
+	// Allow are the ranges that numbers must be within.
+	//
+	// If empty, all numbers are allowed.
+	Allow []string
+	// Forbid are the ranges that numbers must not be within.
+	Forbid []string
+	// Max is the maximum number.
+	//
+	// If zero, there is no maximum.
+	Max int
+}
+
+// NumberRange is an inclusive range of numbers.
+//
+// Ranges use the syntax of reserved ranges in Protobuf files, that is either
+// a single number such as "5", or a range such as "1 to 15" or "20000 to max".
+type NumberRange struct {
+	Start int
+	End   int
+}
+
+// ParseNumberRanges parses the number ranges.
+func ParseNumberRanges(values []string) ([]NumberRange, error) {
+	numberRanges := make([]NumberRange, len(values))
+	for i, value := range values {
+		numberRange, err := parseNumberRange(value)
+		if err != nil {
+			return nil, err
+		}
+		numberRanges[i] = numberRange
+	}
+	return numberRanges, nil
+}
+
+// Contains returns true if the number is within the range.
+func (r NumberRange) Contains(number int) bool {
+	return number >= r.Start && number <= r.End
+}
+
+// String returns the range using the syntax it was parsed from.
+func (r NumberRange) String() string {
+	if r.Start == r.End {
+		return strconv.Itoa(r.Start)
+	}
+	if r.End == math.MaxInt32 {
+		return fmt.Sprintf("%d to max", r.Start)
+	}
+	return fmt.Sprintf("%d to %d", r.Start, r.End)
+}
+
+func parseNumberRange(value string) (NumberRange, error) {
+	fields := strings.Fields(value)
+	switch {
+	case len(fields) == 1:
+		number, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return NumberRange{}, fmt.Errorf("invalid number range %q: %v", value, err)
+		}
+		return NumberRange{Start: number, End: number}, nil
+	case len(fields) == 3 && fields[1] == "to":
+		start, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return NumberRange{}, fmt.Errorf("invalid number range %q: %v", value, err)
+		}
+		end := math.MaxInt32
+		if fields[2] != "max" {
+			end, err = strconv.Atoi(fields[2])
+			if err != nil {
+				return NumberRange{}, fmt.Errorf("invalid number range %q: %v", value, err)
+			}
+		}
+		if start > end {
+			return NumberRange{}, fmt.Errorf("invalid number range %q: start is greater than end", value)
+		}
+		return NumberRange{Start: start, End: end}, nil
+	default:
+		return NumberRange{}, fmt.Errorf(`invalid number range %q: must be a number or "start to end"`, value)
+	}
+}
+
+func validateNumberRangePolicy(name string, numberRangePolicy NumberRangePolicy) error {
+	if _, err := ParseNumberRanges(numberRangePolicy.Allow); err != nil {
+		return fmt.Errorf("invalid %s allow: %w", name, err)
+	}
+	if _, err := ParseNumberRanges(numberRangePolicy.Forbid); err != nil {
+		return fmt.Errorf("invalid %s forbid: %w", name, err)
+	}
+	if numberRangePolicy.Max < 0 {
+		return fmt.Errorf("invalid %s max %d: must not be negative", name, numberRangePolicy.Max)
+	}
+	return nil
+}
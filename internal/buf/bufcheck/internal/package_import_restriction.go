@@ -0,0 +1,79 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PackageImportRestriction restricts the packages that a set of packages may import.
+//
+// Both From and Disallow are package patterns. A pattern is either a package
+// name, which only matches that package, or a package name followed by ".*",
+// which matches all packages nested within that package but not the package itself.
+// The pattern "*" matches all packages.
+type PackageImportRestriction struct {
+	// From is the pattern of the importing packages.
+	From string
+	// Disallow is the pattern of the packages that packages matching From may not import.
+	Disallow string
+}
+
+// Matches returns true if the importing package pkg may not import the package importPkg.
+func (p PackageImportRestriction) Matches(pkg string, importPkg string) bool {
+	return packageMatchesPattern(pkg, p.From) && packageMatchesPattern(importPkg, p.Disallow)
+}
+
+func validatePackageImportRestrictions(packageImportRestrictions []PackageImportRestriction) error {
+	for _, packageImportRestriction := range packageImportRestrictions {
+		if err := validatePackagePattern(packageImportRestriction.From); err != nil {
+			return fmt.Errorf("invalid package import restriction from %q: %w", packageImportRestriction.From, err)
+		}
+		if err := validatePackagePattern(packageImportRestriction.Disallow); err != nil {
+			return fmt.Errorf("invalid package import restriction disallow %q: %w", packageImportRestriction.Disallow, err)
+		}
+	}
+	return nil
+}
+
+func validatePackagePattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("package pattern is empty")
+	}
+	if pattern == "*" {
+		return nil
+	}
+	for _, component := range strings.Split(strings.TrimSuffix(pattern, ".*"), ".") {
+		if component == "" {
+			return errors.New("package pattern has an empty component")
+		}
+		if strings.Contains(component, "*") {
+			return errors.New(`"*" is only allowed as the last component of a package pattern`)
+		}
+	}
+	return nil
+}
+
+func packageMatchesPattern(pkg string, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+	if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+		return strings.HasPrefix(pkg, prefix)
+	}
+	return pkg == pattern
+}
@@ -0,0 +1,209 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	checkv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/check/v1alpha1"
+	"github.com/bufbuild/buf/internal/pkg/protoencoding"
+	"go.opencensus.io/trace"
+)
+
+const pluginBinaryPrefix = "buf-plugin-"
+
+var pluginRuleIDOrCategoryRegexp = regexp.MustCompile("^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$")
+
+// Plugin is an external check plugin.
+//
+// Plugins are executables that read a CheckRequest from stdin and write a
+// CheckResponse to stdout, see buf/alpha/check/v1alpha1/check.proto.
+type Plugin struct {
+	// Name is the name of the plugin.
+	//
+	// Required.
+	Name string
+	// Path is the path to the plugin executable.
+	//
+	// If empty, buf-plugin-Name is looked up on the PATH. Relative paths are
+	// resolved against the directory of the configuration file when the
+	// configuration is read.
+	Path string
+	// Options are the options passed to the plugin.
+	Options map[string]string
+}
+
+// ExternalPluginV1Beta1 is an external plugin.
+//
+// Plugins are executables that provide additional rules. If Path is empty,
+// buf-plugin-Name is looked up on the PATH. Options are passed to the plugin.
+type ExternalPluginV1Beta1 struct {
+	Name    string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Path    string            `json:"path,omitempty" yaml:"path,omitempty"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// ExternalPluginsV1Beta1ToPlugins returns the Plugins for the external plugins.
+func ExternalPluginsV1Beta1ToPlugins(externalPlugins []ExternalPluginV1Beta1) []Plugin {
+	if externalPlugins == nil {
+		return nil
+	}
+	plugins := make([]Plugin, len(externalPlugins))
+	for i, externalPlugin := range externalPlugins {
+		plugins[i] = Plugin{
+			Name:    externalPlugin.Name,
+			Path:    externalPlugin.Path,
+			Options: externalPlugin.Options,
+		}
+	}
+	return plugins
+}
+
+// pluginRunner runs a Plugin for a given rule type.
+type pluginRunner struct {
+	plugin   Plugin
+	ruleType checkv1alpha1.RuleType
+}
+
+func newPluginRunner(plugin Plugin, ruleType checkv1alpha1.RuleType) *pluginRunner {
+	return &pluginRunner{
+		plugin:   plugin,
+		ruleType: ruleType,
+	}
+}
+
+// Run runs the plugin with the request.
+//
+// The RuleType and Options of the request are set by Run.
+func (p *pluginRunner) Run(ctx context.Context, request *checkv1alpha1.CheckRequest) (*checkv1alpha1.CheckResponse, error) {
+	ctx, span := trace.StartSpan(ctx, "check_plugin")
+	span.AddAttributes(trace.StringAttribute("plugin", p.plugin.Name))
+	defer span.End()
+	pluginPath := p.plugin.Path
+	if pluginPath == "" {
+		var err error
+		pluginPath, err = exec.LookPath(pluginBinaryPrefix + p.plugin.Name)
+		if err != nil {
+			return nil, fmt.Errorf("could not find plugin %q: %w", p.plugin.Name, err)
+		}
+	}
+	request.RuleType = p.ruleType
+	request.Options = p.plugin.Options
+	requestData, err := protoencoding.NewWireMarshaler().Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	responseBuffer := bytes.NewBuffer(nil)
+	stderrBuffer := bytes.NewBuffer(nil)
+	cmd := exec.CommandContext(ctx, pluginPath)
+	cmd.Stdin = bytes.NewReader(requestData)
+	cmd.Stdout = responseBuffer
+	cmd.Stderr = stderrBuffer
+	if err := cmd.Run(); err != nil {
+		if stderr := strings.TrimSpace(stderrBuffer.String()); stderr != "" {
+			return nil, fmt.Errorf("plugin %q failed: %v: %s", p.plugin.Name, err, stderr)
+		}
+		return nil, fmt.Errorf("plugin %q failed: %v", p.plugin.Name, err)
+	}
+	response := &checkv1alpha1.CheckResponse{}
+	if err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(responseBuffer.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("plugin %q returned an invalid response: %v", p.plugin.Name, err)
+	}
+	if response.GetError() != "" {
+		return nil, fmt.Errorf("plugin %q: %s", p.plugin.Name, response.GetError())
+	}
+	return response, nil
+}
+
+// newPluginRuleBuilders lists the rules of the plugins, and returns RuleBuilders
+// for them along with the categories of each rule.
+//
+// The ids and categories must not collide with the ids and categories in
+// idToCategories.
+func newPluginRuleBuilders(
+	ctx context.Context,
+	plugins []Plugin,
+	ruleType checkv1alpha1.RuleType,
+	idToCategories map[string][]string,
+) ([]*RuleBuilder, map[string][]string, error) {
+	categoryToIDs := getCategoryToIDs(idToCategories)
+	var ruleBuilders []*RuleBuilder
+	pluginIDToCategories := make(map[string][]string)
+	idToPluginName := make(map[string]string)
+	for _, plugin := range plugins {
+		pluginRunner := newPluginRunner(plugin, ruleType)
+		response, err := pluginRunner.Run(ctx, &checkv1alpha1.CheckRequest{})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, rule := range response.GetRules() {
+			id := rule.GetId()
+			if !pluginRuleIDOrCategoryRegexp.MatchString(id) {
+				return nil, nil, fmt.Errorf("plugin %q returned invalid rule id %q, ids must be UPPER_SNAKE_CASE", plugin.Name, id)
+			}
+			if _, ok := idToCategories[id]; ok {
+				return nil, nil, fmt.Errorf("plugin %q returned rule id %q which is a built-in rule", plugin.Name, id)
+			}
+			if _, ok := categoryToIDs[id]; ok {
+				return nil, nil, fmt.Errorf("plugin %q returned rule id %q which is a built-in category", plugin.Name, id)
+			}
+			if otherPluginName, ok := idToPluginName[id]; ok {
+				return nil, nil, fmt.Errorf("plugin %q returned rule id %q which is also returned by plugin %q", plugin.Name, id, otherPluginName)
+			}
+			for _, category := range rule.GetCategories() {
+				if !pluginRuleIDOrCategoryRegexp.MatchString(category) {
+					return nil, nil, fmt.Errorf("plugin %q returned invalid category %q for rule %q, categories must be UPPER_SNAKE_CASE", plugin.Name, category, id)
+				}
+				if _, ok := idToCategories[category]; ok {
+					return nil, nil, fmt.Errorf("plugin %q returned category %q for rule %q which is a built-in rule id", plugin.Name, category, id)
+				}
+				if _, ok := categoryToIDs[category]; ok {
+					return nil, nil, fmt.Errorf("plugin %q returned category %q for rule %q which is a built-in category", plugin.Name, category, id)
+				}
+			}
+			purpose := strings.TrimSuffix(strings.TrimSpace(rule.GetPurpose()), ".")
+			if purpose == "" {
+				return nil, nil, fmt.Errorf("plugin %q returned no purpose for rule %q", plugin.Name, id)
+			}
+			idToPluginName[id] = plugin.Name
+			pluginIDToCategories[id] = rule.GetCategories()
+			ruleBuilders = append(ruleBuilders, newPluginRuleBuilder(id, purpose, pluginRunner))
+		}
+	}
+	return ruleBuilders, pluginIDToCategories, nil
+}
+
+func validatePlugins(plugins []Plugin) error {
+	names := make(map[string]struct{}, len(plugins))
+	for _, plugin := range plugins {
+		if plugin.Name == "" {
+			return errors.New("plugin name must be set")
+		}
+		if strings.ContainsRune(plugin.Name, '/') {
+			return fmt.Errorf("plugin name %q must not contain a path separator, use path instead", plugin.Name)
+		}
+		if _, ok := names[plugin.Name]; ok {
+			return fmt.Errorf("duplicate plugin name: %q", plugin.Name)
+		}
+		names[plugin.Name] = struct{}{}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// RequiredOptionOnFile requires the option on files.
+	RequiredOptionOnFile = "file"
+	// RequiredOptionOnMessage requires the option on messages.
+	RequiredOptionOnMessage = "message"
+	// RequiredOptionOnField requires the option on fields.
+	RequiredOptionOnField = "field"
+	// RequiredOptionOnOneof requires the option on oneofs.
+	RequiredOptionOnOneof = "oneof"
+	// RequiredOptionOnEnum requires the option on enums.
+	RequiredOptionOnEnum = "enum"
+	// RequiredOptionOnEnumValue requires the option on enum values.
+	RequiredOptionOnEnumValue = "enum_value"
+	// RequiredOptionOnService requires the option on services.
+	RequiredOptionOnService = "service"
+	// RequiredOptionOnRPC requires the option on RPCs.
+	RequiredOptionOnRPC = "rpc"
+)
+
+var requiredOptionOnValues = []string{
+	RequiredOptionOnFile,
+	RequiredOptionOnMessage,
+	RequiredOptionOnField,
+	RequiredOptionOnOneof,
+	RequiredOptionOnEnum,
+	RequiredOptionOnEnumValue,
+	RequiredOptionOnService,
+	RequiredOptionOnRPC,
+}
+
+// RequiredOption requires that a custom option is set on all elements of a
+// kind in a set of packages.
+type RequiredOption struct {
+	// Option is the full name of the extension, such as "google.api.http".
+	Option string
+	// On is the kind of element the option is required on, such as "field".
+	On string
+	// Packages are the package patterns the option is required in, as described
+	// on PackageImportRestriction.
+	//
+	// If empty, the option is required in all packages.
+	Packages []string
+}
+
+// MatchesPackage returns true if the option is required in the package pkg.
+func (r RequiredOption) MatchesPackage(pkg string) bool {
+	if len(r.Packages) == 0 {
+		return true
+	}
+	for _, pattern := range r.Packages {
+		if packageMatchesPattern(pkg, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateRequiredOptions(requiredOptions []RequiredOption) error {
+	for _, requiredOption := range requiredOptions {
+		if requiredOption.Option == "" {
+			return errors.New("required option has no option")
+		}
+		if strings.HasPrefix(requiredOption.Option, "(") || strings.HasPrefix(requiredOption.Option, ".") {
+			return fmt.Errorf("required option %q must be a full name without parentheses or a leading dot", requiredOption.Option)
+		}
+		if !isRequiredOptionOnValue(requiredOption.On) {
+			return fmt.Errorf("required option %q has invalid on %q, must be one of %s", requiredOption.Option, requiredOption.On, strings.Join(requiredOptionOnValues, ", "))
+		}
+		for _, pattern := range requiredOption.Packages {
+			if err := validatePackagePattern(pattern); err != nil {
+				return fmt.Errorf("invalid required option %q package %q: %w", requiredOption.Option, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func isRequiredOptionOnValue(on string) bool {
+	for _, requiredOptionOnValue := range requiredOptionOnValues {
+		if on == requiredOptionOnValue {
+			return true
+		}
+	}
+	return false
+}
@@ -38,6 +38,9 @@ type Rule struct {
 	categories []string
 	purpose    string
 	checkFunc  CheckFunc
+	// pluginRunner is set if the rule is provided by a Plugin, in which
+	// case checkFunc is nil and the rule is run by the Runner.
+	pluginRunner *pluginRunner
 }
 
 // newRule returns a new Rule.
@@ -24,6 +24,8 @@ type RuleBuilder struct {
 	id         string
 	newPurpose func(ConfigBuilder) (string, error)
 	newCheck   func(ConfigBuilder) (CheckFunc, error)
+	// pluginRunner is set if the rule is provided by a Plugin.
+	pluginRunner *pluginRunner
 }
 
 // NewRuleBuilder returns a new RuleBuilder.
@@ -53,6 +55,20 @@ func NewNopRuleBuilder(
 	)
 }
 
+// newPluginRuleBuilder returns a new RuleBuilder for a rule provided by a Plugin.
+func newPluginRuleBuilder(
+	id string,
+	purpose string,
+	pluginRunner *pluginRunner,
+) *RuleBuilder {
+	return &RuleBuilder{
+		id:           id,
+		newPurpose:   newNopPurpose(purpose),
+		newCheck:     newNopCheckFunc(nil),
+		pluginRunner: pluginRunner,
+	}
+}
+
 // NewRule returns a new Rule.
 //
 // Categories will be sorted and Purpose will be prepended with "Checks that "
@@ -68,12 +84,14 @@ func (c *RuleBuilder) NewRule(configBuilder ConfigBuilder, categories []string)
 	if err != nil {
 		return nil, err
 	}
-	return newRule(
+	rule := newRule(
 		c.id,
 		categories,
 		purpose,
 		check,
-	), nil
+	)
+	rule.pluginRunner = c.pluginRunner
+	return rule, nil
 }
 
 // ID returns the id.
@@ -16,9 +16,13 @@ package internal
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
+	checkv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/check/v1alpha1"
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/protosource"
 	"github.com/bufbuild/buf/internal/pkg/protoversion"
@@ -61,11 +65,25 @@ func RunnerWithIgnorePrefix(ignorePrefix string) RunnerOption {
 }
 
 // Check runs the Rules.
-func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []protosource.File, files []protosource.File) ([]bufanalysis.FileAnnotation, error) {
+//
+// previousImage is only used for breaking change rules, and may be nil.
+func (r *Runner) Check(ctx context.Context, config *Config, previousImage bufimage.Image, image bufimage.Image) ([]bufanalysis.FileAnnotation, error) {
 	rules := config.Rules
 	if len(rules) == 0 {
 		return nil, nil
 	}
+	var previousFiles []protosource.File
+	if previousImage != nil {
+		var err error
+		previousFiles, err = protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(previousImage.Files())...)
+		if err != nil {
+			return nil, err
+		}
+	}
+	files, err := protosource.NewFilesUnstable(ctx, bufimageutil.NewInputFiles(image.Files())...)
+	if err != nil {
+		return nil, err
+	}
 	ctx, span := trace.StartSpan(ctx, "check")
 	span.AddAttributes(
 		trace.Int64Attribute("num_files", int64(len(files))),
@@ -73,18 +91,38 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 	)
 	defer span.End()
 
+	var builtinRules []*Rule
+	var pluginRunners []*pluginRunner
+	pluginRunnerToIDs := make(map[*pluginRunner][]string)
+	for _, rule := range rules {
+		if rule.pluginRunner == nil {
+			builtinRules = append(builtinRules, rule)
+			continue
+		}
+		if _, ok := pluginRunnerToIDs[rule.pluginRunner]; !ok {
+			pluginRunners = append(pluginRunners, rule.pluginRunner)
+		}
+		pluginRunnerToIDs[rule.pluginRunner] = append(pluginRunnerToIDs[rule.pluginRunner], rule.ID())
+	}
+
 	ignoreFunc := r.newIgnoreFunc(config)
 	var fileAnnotations []bufanalysis.FileAnnotation
-	resultC := make(chan *result, len(rules))
-	for _, rule := range rules {
+	resultC := make(chan *result, len(builtinRules)+len(pluginRunners))
+	for _, rule := range builtinRules {
 		rule := rule
 		go func() {
 			iFileAnnotations, iErr := rule.check(ignoreFunc, previousFiles, files)
 			resultC <- newResult(iFileAnnotations, iErr)
 		}()
 	}
-	var err error
-	for i := 0; i < len(rules); i++ {
+	for _, pluginRunner := range pluginRunners {
+		pluginRunner := pluginRunner
+		go func() {
+			iFileAnnotations, iErr := checkPlugin(ctx, config, pluginRunner, pluginRunnerToIDs[pluginRunner], previousImage, image)
+			resultC <- newResult(iFileAnnotations, iErr)
+		}()
+	}
+	for i := 0; i < len(builtinRules)+len(pluginRunners); i++ {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
@@ -96,10 +134,87 @@ func (r *Runner) Check(ctx context.Context, config *Config, previousFiles []prot
 	if err != nil {
 		return nil, err
 	}
+	for i, fileAnnotation := range fileAnnotations {
+		if severity, ok := config.IDToSeverity[fileAnnotation.Type()]; ok && severity != bufanalysis.SeverityError {
+			fileAnnotations[i] = bufanalysis.NewFileAnnotationWithSeverity(fileAnnotation, severity)
+		}
+	}
 	bufanalysis.SortFileAnnotations(fileAnnotations)
 	return fileAnnotations, nil
 }
 
+// checkPlugin runs the rules with the given ids provided by the plugin.
+//
+// Comment ignores are not supported for plugin rules, as plugins only return
+// the location of a failure and not the descriptor it is for.
+func checkPlugin(
+	ctx context.Context,
+	config *Config,
+	pluginRunner *pluginRunner,
+	ids []string,
+	previousImage bufimage.Image,
+	image bufimage.Image,
+) ([]bufanalysis.FileAnnotation, error) {
+	request := &checkv1alpha1.CheckRequest{
+		RuleIds: ids,
+		Image:   bufimage.ImageToProtoImage(image),
+	}
+	if previousImage != nil {
+		request.AgainstImage = bufimage.ImageToProtoImage(previousImage)
+	}
+	response, err := pluginRunner.Run(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	idMap := stringutil.SliceToMap(ids)
+	var fileAnnotations []bufanalysis.FileAnnotation
+	for _, pluginFileAnnotation := range response.GetFileAnnotations() {
+		id := pluginFileAnnotation.GetRuleId()
+		if _, ok := idMap[id]; !ok {
+			return nil, fmt.Errorf("plugin %q returned a failure for rule %q which was not requested", pluginRunner.plugin.Name, id)
+		}
+		var fileInfo bufanalysis.FileInfo
+		if path := pluginFileAnnotation.GetPath(); path != "" {
+			imageFile := image.GetFile(path)
+			if imageFile == nil {
+				return nil, fmt.Errorf("plugin %q returned a failure for rule %q for unknown file %q", pluginRunner.plugin.Name, id, path)
+			}
+			// we only check the non-import files
+			if imageFile.IsImport() {
+				continue
+			}
+			if pathIsIgnored(id, path, config) {
+				continue
+			}
+			if config.IgnoreUnstablePackages && packageIsUnstable(imageFile.Proto().GetPackage()) {
+				continue
+			}
+			fileInfo = imageFile
+		}
+		fileAnnotations = append(
+			fileAnnotations,
+			bufanalysis.NewFileAnnotation(
+				fileInfo,
+				int(pluginFileAnnotation.GetStartLine()),
+				int(pluginFileAnnotation.GetStartColumn()),
+				int(pluginFileAnnotation.GetEndLine()),
+				int(pluginFileAnnotation.GetEndColumn()),
+				id,
+				pluginFileAnnotation.GetMessage(),
+			),
+		)
+	}
+	return fileAnnotations, nil
+}
+
+// IsIgnored returns true if FileAnnotations of the rule with the given id for
+// the descriptor are ignored by the config.
+//
+// Comment ignores are not considered, as there are no locations.
+func (r *Runner) IsIgnored(config *Config, id string, descriptor protosource.Descriptor) bool {
+	return r.newIgnoreFunc(config)(id, descriptor, nil)
+}
+
 func (r *Runner) newIgnoreFunc(config *Config) IgnoreFunc {
 	return func(id string, descriptor protosource.Descriptor, locations []protosource.Location) bool {
 		if idIsIgnored(id, descriptor, config) {
@@ -115,11 +230,7 @@ func (r *Runner) newIgnoreFunc(config *Config) IgnoreFunc {
 			if descriptor == nil {
 				return false
 			}
-			packageVersion, ok := protoversion.NewPackageVersionForPackage(descriptor.File().Package())
-			if !ok {
-				return false
-			}
-			return packageVersion.StabilityLevel() != protoversion.StabilityLevelStable
+			return packageIsUnstable(descriptor.File().Package())
 		}
 		return false
 	}
@@ -129,7 +240,10 @@ func idIsIgnored(id string, descriptor protosource.Descriptor, config *Config) b
 	if descriptor == nil {
 		return false
 	}
-	path := descriptor.File().Path()
+	return pathIsIgnored(id, descriptor.File().Path(), config)
+}
+
+func pathIsIgnored(id string, path string, config *Config) bool {
 	if normalpath.MapHasEqualOrContainingPath(config.IgnoreRootPaths, path, normalpath.Relative) {
 		return true
 	}
@@ -143,6 +257,14 @@ func idIsIgnored(id string, descriptor protosource.Descriptor, config *Config) b
 	return normalpath.MapHasEqualOrContainingPath(ignoreRootPaths, path, normalpath.Relative)
 }
 
+func packageIsUnstable(pkg string) bool {
+	packageVersion, ok := protoversion.NewPackageVersionForPackage(pkg)
+	if !ok {
+		return false
+	}
+	return packageVersion.StabilityLevel() != protoversion.StabilityLevelStable
+}
+
 func locationsAreIgnored(id string, ignorePrefix string, locations []protosource.Location, config *Config) bool {
 	// we already check that ignorePrefix is non-empty, but just doing here for safety
 	if id == "" || ignorePrefix == "" {
@@ -21,7 +21,8 @@ var topLevelCategoryToPriority = map[string]int{
 	"DEFAULT":   3,
 	"COMMENTS":  4,
 	"UNARY_RPC": 5,
-	"OTHER":     6,
+	"AIP":       6,
+	"OTHER":     7,
 	"FILE":      1,
 	"PACKAGE":   2,
 	"WIRE_JSON": 3,
@@ -14,10 +14,16 @@
 
 package internal
 
+import (
+	checkv1alpha1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/check/v1alpha1"
+)
+
 // VersionSpec specifies the rules, ids, and categories for a given version.
 type VersionSpec struct {
 	RuleBuilders      []*RuleBuilder
 	DefaultCategories []string
 	AllCategories     []string
 	IDToCategories    map[string][]string
+	// RuleType is the rule type sent to Plugins.
+	RuleType checkv1alpha1.RuleType
 }
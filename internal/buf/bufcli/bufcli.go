@@ -275,6 +275,22 @@ func GetStringSliceFlagOrDeprecatedFlag(
 	return deprecatedFlag, nil
 }
 
+// CheckPluginsAllowed returns true if the check plugins of the configs read
+// for the Ref can be run.
+//
+// Plugins are executables, so they are only run if the config is local, that
+// is if configOverride is set, if the Ref is a local directory, or if the Ref
+// is an image, for which the config is read from the current directory.
+func CheckPluginsAllowed(ref buffetch.Ref, configOverride string) bool {
+	if configOverride != "" {
+		return true
+	}
+	if _, ok := ref.(buffetch.ImageRef); ok {
+		return true
+	}
+	return buffetch.IsLocalDirRef(ref)
+}
+
 // NewFetchReader creates a new buffetch.Reader with the default HTTP client
 // and git cloner.
 func NewFetchReader(
@@ -42,10 +42,17 @@ type Provider interface {
 	// GetConfig gets the Config for the YAML data at ConfigFilePath.
 	//
 	// If the data is of length 0, returns the default config.
+	//
+	// Relative plugin paths are resolved against the directory of ConfigFilePath.
 	GetConfig(ctx context.Context, readBucket storage.ReadBucket) (*Config, error)
+	// GetConfigForFile gets the Config for the JSON or YAML file at the path.
+	//
+	// Relative plugin paths are resolved against the directory of the file.
+	GetConfigForFile(ctx context.Context, filePath string) (*Config, error)
 	// GetConfig gets the Config for the given JSON or YAML data.
 	//
-	// If the data is of length 0, returns the default config.
+	// If the data is of length 0, returns the default config. Relative plugin
+	// paths are resolved against the current directory.
 	GetConfigForData(ctx context.Context, data []byte) (*Config, error)
 }
 
@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 
 	"github.com/bufbuild/buf/internal/buf/bufcheck/bufbreaking"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
@@ -66,6 +68,24 @@ func (p *provider) GetConfig(ctx context.Context, readBucket storage.ReadBucket)
 		encoding.UnmarshalYAMLStrict,
 		data,
 		`File "`+readObjectCloser.ExternalPath()+`"`,
+		filepath.Dir(readObjectCloser.ExternalPath()),
+	)
+}
+
+func (p *provider) GetConfigForFile(ctx context.Context, filePath string) (*Config, error) {
+	_, span := trace.StartSpan(ctx, "get_config_for_file")
+	defer span.End()
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file: %v", err)
+	}
+	return p.getConfigForData(
+		ctx,
+		encoding.UnmarshalJSONOrYAMLNonStrict,
+		encoding.UnmarshalJSONOrYAMLStrict,
+		data,
+		`File "`+filePath+`"`,
+		filepath.Dir(filePath),
 	)
 }
 
@@ -78,6 +98,7 @@ func (p *provider) GetConfigForData(ctx context.Context, data []byte) (*Config,
 		encoding.UnmarshalJSONOrYAMLStrict,
 		data,
 		"Configuration data",
+		".",
 	)
 }
 
@@ -87,6 +108,7 @@ func (p *provider) getConfigForData(
 	unmarshalStrict func([]byte, interface{}) error,
 	data []byte,
 	id string,
+	dirPath string,
 ) (*Config, error) {
 	var externalConfigVersion externalConfigVersion
 	if err := unmarshalNonStrict(data, &externalConfigVersion); err != nil {
@@ -99,6 +121,12 @@ func (p *provider) getConfigForData(
 	if err := unmarshalStrict(data, &externalConfigV1Beta1); err != nil {
 		return nil, err
 	}
+	if err := resolvePluginPaths(dirPath, externalConfigV1Beta1.Lint.Plugins); err != nil {
+		return nil, err
+	}
+	if err := resolvePluginPaths(dirPath, externalConfigV1Beta1.Breaking.Plugins); err != nil {
+		return nil, err
+	}
 	return p.newConfigV1Beta1(externalConfigV1Beta1)
 }
 
@@ -141,3 +169,20 @@ func (p *provider) validateExternalConfigVersion(externalConfigVersion externalC
 		return fmt.Errorf("%s has unknown configuration version: %s", id, externalConfigVersion.Version)
 	}
 }
+
+// resolvePluginPaths resolves the relative paths of the plugins against the
+// directory of the configuration file, so that plugins do not depend on the
+// directory buf is run from.
+func resolvePluginPaths(dirPath string, plugins []buflint.ExternalPluginV1Beta1) error {
+	for i, plugin := range plugins {
+		if plugin.Path == "" || filepath.IsAbs(plugin.Path) {
+			continue
+		}
+		path, err := filepath.Abs(filepath.Join(dirPath, plugin.Path))
+		if err != nil {
+			return err
+		}
+		plugins[i].Path = path
+	}
+	return nil
+}
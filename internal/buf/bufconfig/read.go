@@ -16,8 +16,6 @@ package bufconfig
 
 import (
 	"context"
-	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/bufbuild/buf/internal/pkg/storage"
@@ -34,18 +32,12 @@ func readConfig(
 		option(readConfigOptions)
 	}
 	if readConfigOptions.override != "" {
-		var data []byte
-		var err error
 		switch filepath.Ext(readConfigOptions.override) {
 		case ".json", ".yaml", ".yml":
-			data, err = os.ReadFile(readConfigOptions.override)
-			if err != nil {
-				return nil, fmt.Errorf("could not read file: %v", err)
-			}
+			return provider.GetConfigForFile(ctx, readConfigOptions.override)
 		default:
-			data = []byte(readConfigOptions.override)
+			return provider.GetConfigForData(ctx, []byte(readConfigOptions.override))
 		}
-		return provider.GetConfigForData(ctx, data)
 	}
 	return provider.GetConfig(ctx, readBucket)
 }
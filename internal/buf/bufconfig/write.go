@@ -157,6 +157,50 @@ lint:
   {{if not .Uncomment}}#{{end}}  PACKAGE_AFFINITY:
   {{if not .Uncomment}}#{{end}}    - foo
 
+  # severity is the map from rule id or category to severity.
+  #
+  # The severity is one of "error", "warning", or "info", and defaults to
+  # "error". Only errors result in a non-zero exit code, warnings and infos are
+  # printed but do not fail buf lint.
+  #
+  # If a rule id and a category that contains it are both specified, the rule
+  # id takes precedence. If a rule is in multiple specified categories, the
+  # most severe severity is used.
+  {{if not .Uncomment}}#{{end}}severity:
+  {{if not .Uncomment}}#{{end}}  COMMENTS: warning
+  {{if not .Uncomment}}#{{end}}  COMMENT_FIELD: info
+
+  # comment_min_length affects the behavior of the COMMENT_* rules.
+  #
+  # These rules treat placeholder comments as missing: comments that start with
+  # TODO or FIXME, comments that only repeat the name of the element, and
+  # comments shorter than this number of characters, not counting surrounding
+  # whitespace. By default, there is no minimum length.
+  {{if not .Uncomment}}#{{end}}comment_min_length: 10
+
+  # enum_value_number_ranges affects the behavior of the ENUM_VALUE_NUMBER_RANGE
+  # rule, and field_number_ranges affects the behavior of the
+  # FIELD_NUMBER_RANGE rule, which checks both field numbers and extension
+  # ranges.
+  #
+  # Numbers must be within one of the allow ranges if any are given, must not
+  # be within any of the forbid ranges, and must not be greater than max if max
+  # is set. Ranges use the same syntax as reserved ranges in Protobuf files.
+  {{if not .Uncomment}}#{{end}}enum_value_number_ranges:
+  {{if not .Uncomment}}#{{end}}  allow:
+  {{if not .Uncomment}}#{{end}}    - 0 to 1000
+  {{if not .Uncomment}}#{{end}}field_number_ranges:
+  {{if not .Uncomment}}#{{end}}  forbid:
+  {{if not .Uncomment}}#{{end}}    - 19000 to 19999
+  {{if not .Uncomment}}#{{end}}  max: 100000
+
+  # field_number_max_gap affects the behavior of the FIELD_NUMBER_MAX_GAP rule.
+  #
+  # Messages may not have more than this number of unused field numbers between
+  # used field numbers. Reserved numbers and extension ranges are considered
+  # used. The default is 10.
+  {{if not .Uncomment}}#{{end}}field_number_max_gap: 10
+
   # enum_zero_value_suffix affects the behavior of the ENUM_ZERO_VALUE_SUFFIX
   # rule.
   #
@@ -193,6 +237,45 @@ lint:
   # suffix.
   {{if not .Uncomment}}#{{end}}service_suffix: Service
 
+  # package_import_restrictions affects the behavior of the
+  # PACKAGE_NO_IMPORT_RESTRICTED rule.
+  #
+  # Packages matching from may not import packages matching disallow. A
+  # pattern is either a package name, or a package name followed by ".*" to
+  # match all packages nested within it, not including the package itself.
+  {{if not .Uncomment}}#{{end}}package_import_restrictions:
+  {{if not .Uncomment}}#{{end}}  - from: acme.public.*
+  {{if not .Uncomment}}#{{end}}    disallow: acme.internal.*
+
+  # require_options affects the behavior of the REQUIRE_OPTIONS rule.
+  #
+  # The custom option, given by the full name of its extension, must be set on
+  # all elements of the given kind in the packages matching the package
+  # patterns, or in all packages if no packages are given. The kind is one of
+  # file, message, field, oneof, enum, enum_value, service, or rpc.
+  {{if not .Uncomment}}#{{end}}require_options:
+  {{if not .Uncomment}}#{{end}}  - option: acme.pii
+  {{if not .Uncomment}}#{{end}}    on: field
+  {{if not .Uncomment}}#{{end}}    packages:
+  {{if not .Uncomment}}#{{end}}      - acme.billing.*
+  {{if not .Uncomment}}#{{end}}  - option: google.api.http
+  {{if not .Uncomment}}#{{end}}    on: rpc
+
+  # plugins are external executables that provide additional lint rules.
+  #
+  # Each plugin is run with a CheckRequest on stdin and writes a CheckResponse
+  # to stdout, as defined in buf/alpha/check/v1alpha1/check.proto. If path is
+  # not set, buf-plugin-NAME is looked up on your PATH. A relative path is
+  # relative to the directory of this file. The rules of the plugins are
+  # listed by buf config ls-lint-rules, and can be used with use, except,
+  # ignore, ignore_only, and severity like any other rule.
+  # Comment ignores are not supported for plugin rules.
+  {{if not .Uncomment}}#{{end}}plugins:
+  {{if not .Uncomment}}#{{end}}  - name: acme
+  {{if not .Uncomment}}#{{end}}    path: bin/buf-plugin-acme
+  {{if not .Uncomment}}#{{end}}    options:
+  {{if not .Uncomment}}#{{end}}      max_depth: "3"
+
   # allow_comment_ignores allows comment-driven ignores.
   #
   # If this option is set, leading comments can be added within Protobuf files
@@ -277,6 +360,14 @@ breaking:
   {{if not .Uncomment}}#{{end}}  WIRE_JSON:
   {{if not .Uncomment}}#{{end}}    - foo
 
+  # severity is the map from rule id or category to severity.
+  #
+  # The severity is one of "error", "warning", or "info", and defaults to
+  # "error". Only errors result in a non-zero exit code, warnings and infos are
+  # printed but do not fail buf breaking.
+  {{if not .Uncomment}}#{{end}}severity:
+  {{if not .Uncomment}}#{{end}}  FIELD_SAME_JSON_NAME: warning
+
   # ignore_unstable_packages results in ignoring packages with a last component
   # that is one of the unstable forms recognized by the "PACKAGE_VERSION_SUFFIX"
   # lint rule. The following forms will be ignored:
@@ -290,7 +381,34 @@ breaking:
   # - foo.bar.v1alpha1
   # - foo.bar.v1beta1
   # - foo.bar.v1test
-  {{if not .Uncomment}}#{{end}}ignore_unstable_packages: false`
+  {{if not .Uncomment}}#{{end}}ignore_unstable_packages: false
+
+  # compared_options affects the behavior of the OPTION_SAME_VALUE rule.
+  #
+  # The values of the listed custom options, given by the full names of their
+  # extensions, must not change on the files, messages, fields, oneofs, enums,
+  # enum values, services, and RPCs they can be set on. Setting or unsetting
+  # a listed option is also a change. Use this for options that are part of
+  # the contract of your API, such as HTTP routes or validation rules. Each
+  # listed option must be defined in either the previous or current image.
+  {{if not .Uncomment}}#{{end}}compared_options:
+  {{if not .Uncomment}}#{{end}}  - google.api.http
+  {{if not .Uncomment}}#{{end}}  - validate.rules
+
+  # plugins are external executables that provide additional breaking rules.
+  #
+  # Each plugin is run with a CheckRequest on stdin and writes a CheckResponse
+  # to stdout, as defined in buf/alpha/check/v1alpha1/check.proto. If path is
+  # not set, buf-plugin-NAME is looked up on your PATH. A relative path is
+  # relative to the directory of this file. The rules of the plugins are
+  # listed by buf config ls-breaking-rules, and can be used with use, except,
+  # ignore, ignore_only, and severity like any other rule.
+  # Comment ignores are not supported for plugin rules.
+  {{if not .Uncomment}}#{{end}}plugins:
+  {{if not .Uncomment}}#{{end}}  - name: acme
+  {{if not .Uncomment}}#{{end}}    path: bin/buf-plugin-acme
+  {{if not .Uncomment}}#{{end}}    options:
+  {{if not .Uncomment}}#{{end}}      strict: "true"`
 )
 
 var (
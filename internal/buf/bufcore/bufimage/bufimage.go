@@ -114,31 +114,50 @@ func NewMultiImage(images ...Image) (Image, error) {
 // Reorders the ImageFiles to be in DAG order.
 // Duplicates can exist across the Images, but only if duplicates are non-imports.
 func MergeImages(images ...Image) (Image, error) {
+	return mergeImages(images, false)
+}
+
+// MergeImagesWithEqualDuplicates returns a new Image for the given Images
+// in the same manner as MergeImages, but duplicates must also have equal
+// FileDescriptorProtos, not considering SourceCodeInfo.
+//
+// This should be used when the Images come from separate sources, such as
+// multiple FileDescriptorSets, where the same path may refer to different files.
+func MergeImagesWithEqualDuplicates(images ...Image) (Image, error) {
+	return mergeImages(images, true)
+}
+
+func mergeImages(images []Image, checkEqualDuplicates bool) (Image, error) {
 	switch len(images) {
 	case 0:
 		return nil, nil
 	case 1:
 		return images[0], nil
 	default:
+		var paths []string
 		imageFileSet := make(map[string]ImageFile)
 		for _, image := range images {
 			for _, currentImageFile := range image.Files() {
 				storedImageFile, ok := imageFileSet[currentImageFile.Path()]
 				if !ok {
+					paths = append(paths, currentImageFile.Path())
 					imageFileSet[currentImageFile.Path()] = currentImageFile
 					continue
 				}
 				if !storedImageFile.IsImport() && !currentImageFile.IsImport() {
 					return nil, fmt.Errorf("%s is a non-import in multiple images", currentImageFile.Path())
 				}
+				if checkEqualDuplicates && !fileDescriptorProtosEqualWithoutSourceCodeInfo(storedImageFile.Proto(), currentImageFile.Proto()) {
+					return nil, fmt.Errorf("%s is contained in multiple images with different content", currentImageFile.Path())
+				}
 				if storedImageFile.IsImport() && !currentImageFile.IsImport() {
 					imageFileSet[currentImageFile.Path()] = currentImageFile
 				}
 			}
 		}
 		imageFiles := make([]ImageFile, 0, len(imageFileSet))
-		for _, imageFile := range imageFileSet {
-			imageFiles = append(imageFiles, imageFile)
+		for _, path := range paths {
+			imageFiles = append(imageFiles, imageFileSet[path])
 		}
 		return newImage(imageFiles, true)
 	}
@@ -215,6 +234,18 @@ func ImageWithoutImports(image Image) Image {
 	return newImageNoValidate(newImageFiles)
 }
 
+// ImageAsImports returns a copy of the Image with all files marked as imports.
+//
+// The backing Files are not copied.
+func ImageAsImports(image Image) Image {
+	imageFiles := image.Files()
+	newImageFiles := make([]ImageFile, len(imageFiles))
+	for i, imageFile := range imageFiles {
+		newImageFiles[i] = imageFile.withIsImport(true)
+	}
+	return newImageNoValidate(newImageFiles)
+}
+
 // ImageWithOnlyPaths returns a copy of the Image that only includes the files
 // with the given root relative file paths or directories.
 //
@@ -55,3 +55,13 @@ func WithExcludeSourceCodeInfo() BuildOption {
 		buildOptions.excludeSourceCodeInfo = true
 	}
 }
+
+// WithImportImage returns a BuildOption that resolves imports that are not
+// contained in the ModuleFileSet from the files of the given Image.
+//
+// Files resolved this way are always imports in the resulting Image.
+func WithImportImage(image bufimage.Image) BuildOption {
+	return func(buildOptions *buildOptions) {
+		buildOptions.importImage = image
+	}
+}
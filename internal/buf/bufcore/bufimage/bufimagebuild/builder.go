@@ -32,6 +32,7 @@ import (
 	"go.opencensus.io/trace"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 type builder struct {
@@ -57,6 +58,7 @@ func (b *builder) Build(
 		ctx,
 		moduleFileSet,
 		buildOptions.excludeSourceCodeInfo,
+		buildOptions.importImage,
 	)
 }
 
@@ -64,6 +66,7 @@ func (b *builder) build(
 	ctx context.Context,
 	moduleFileSet bufmodule.ModuleFileSet,
 	excludeSourceCodeInfo bool,
+	importImage bufimage.Image,
 ) (bufimage.Image, []bufanalysis.FileAnnotation, error) {
 	ctx, span := trace.StartSpan(ctx, "build")
 	defer span.End()
@@ -86,6 +89,7 @@ func (b *builder) build(
 		parserAccessorHandler,
 		paths,
 		excludeSourceCodeInfo,
+		importImage,
 	)
 	var buildResultErr error
 	for _, buildResult := range buildResults {
@@ -124,6 +128,7 @@ func getBuildResults(
 	parserAccessorHandler bufmoduleprotoparse.ParserAccessorHandler,
 	paths []string,
 	excludeSourceCodeInfo bool,
+	importImage bufimage.Image,
 ) []*buildResult {
 	ctx, span := trace.StartSpan(ctx, "parse")
 	defer span.End()
@@ -161,6 +166,7 @@ func getBuildResults(
 				parserAccessorHandler,
 				iPaths,
 				excludeSourceCodeInfo,
+				importImage,
 			)
 		}()
 	}
@@ -180,6 +186,7 @@ func getBuildResult(
 	parserAccessorHandler bufmoduleprotoparse.ParserAccessorHandler,
 	paths []string,
 	excludeSourceCodeInfo bool,
+	importImage bufimage.Image,
 ) *buildResult {
 	var errorsWithPos []protoparse.ErrorWithPos
 	var lock sync.Mutex
@@ -196,6 +203,16 @@ func getBuildResult(
 			return nil
 		},
 	}
+	if importImage != nil {
+		// the parser only calls this if the Accessor could not find the file
+		parser.LookupImportProto = func(path string) (*descriptorpb.FileDescriptorProto, error) {
+			imageFile := importImage.GetFile(path)
+			if imageFile == nil {
+				return nil, fmt.Errorf("%s does not exist in import image", path)
+			}
+			return imageFile.Proto(), nil
+		}
+	}
 	// fileDescriptors are in the same order as paths per the documentation
 	descFileDescriptors, err := parser.ParseFiles(paths...)
 	if err != nil {
@@ -424,6 +441,7 @@ func newBuildResult(
 
 type buildOptions struct {
 	excludeSourceCodeInfo bool
+	importImage           bufimage.Image
 }
 
 func newBuildOptions() *buildOptions {
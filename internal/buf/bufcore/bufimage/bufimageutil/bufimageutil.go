@@ -33,13 +33,33 @@ func NewInputFiles(imageFiles []bufimage.ImageFile) []protosource.InputFile {
 
 // FreeMessageRangeStrings gets the free MessageRange strings for the target files.
 //
-// Recursive.
+// Recursive. The output matches protoc --print_free_field_numbers.
 func FreeMessageRangeStrings(
 	ctx context.Context,
 	filePaths []string,
 	image bufimage.Image,
 ) ([]string, error) {
-	var s []string
+	messages, err := FreeMessageRangeMessages(ctx, filePaths, image)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]string, len(messages))
+	for i, message := range messages {
+		s[i] = protosource.FreeMessageRangeString(message)
+	}
+	return s, nil
+}
+
+// FreeMessageRangeMessages gets the messages to compute free MessageRanges for
+// within the target files.
+//
+// Recursive. The messages are in the order printed by protoc --print_free_field_numbers.
+func FreeMessageRangeMessages(
+	ctx context.Context,
+	filePaths []string,
+	image bufimage.Image,
+) ([]protosource.Message, error) {
+	var messages []protosource.Message
 	for _, filePath := range filePaths {
 		imageFile := image.GetFile(filePath)
 		if imageFile == nil {
@@ -50,21 +70,8 @@ func FreeMessageRangeStrings(
 			return nil, err
 		}
 		for _, message := range file.Messages() {
-			s = freeMessageRangeStringsRec(s, message)
+			messages = append(messages, protosource.FreeMessageRangeMessages(message)...)
 		}
 	}
-	return s, nil
-}
-
-func freeMessageRangeStringsRec(
-	s []string,
-	message protosource.Message,
-) []string {
-	for _, nestedMessage := range message.Messages() {
-		s = freeMessageRangeStringsRec(s, nestedMessage)
-	}
-	if e := protosource.FreeMessageRangeString(message); e != "" {
-		return append(s, e)
-	}
-	return s
+	return messages, nil
 }
@@ -104,4 +104,99 @@ func TestMergeImages(t *testing.T) {
 		_, err = MergeImages(firstImage, secondImage)
 		require.Error(t, err)
 	})
+
+	t.Run("mismatched duplicate import", func(t *testing.T) {
+		t.Parallel()
+		firstProtoImage := &imagev1.Image{
+			File: []*descriptorpb.FileDescriptorProto{
+				{
+					Syntax:  proto.String("proto3"),
+					Name:    proto.String("a.proto"),
+					Package: proto.String("a"),
+				},
+			},
+		}
+		secondProtoImage := &imagev1.Image{
+			File: []*descriptorpb.FileDescriptorProto{
+				{
+					Syntax:  proto.String("proto3"),
+					Name:    proto.String("a.proto"),
+					Package: proto.String("b"),
+				},
+			},
+		}
+
+		firstImage, err := NewImageForProto(firstProtoImage)
+		require.NoError(t, err)
+		secondImage, err := NewImageForProto(secondProtoImage)
+		require.NoError(t, err)
+		_, err = MergeImagesWithEqualDuplicates(ImageAsImports(firstImage), ImageAsImports(secondImage))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "a.proto is contained in multiple images with different content")
+		// content is only compared by MergeImagesWithEqualDuplicates, as with
+		// workspaces the same import can be built by multiple modules
+		mergedImage, err := MergeImages(ImageAsImports(firstImage), ImageAsImports(secondImage))
+		require.NoError(t, err)
+		require.Len(t, mergedImage.Files(), 1)
+		assert.Equal(t, "a", mergedImage.GetFile("a.proto").Proto().GetPackage())
+	})
+
+	t.Run("duplicate import with different source code info", func(t *testing.T) {
+		t.Parallel()
+		firstProtoImage := &imagev1.Image{
+			File: []*descriptorpb.FileDescriptorProto{
+				{
+					Syntax: proto.String("proto3"),
+					Name:   proto.String("a.proto"),
+					SourceCodeInfo: &descriptorpb.SourceCodeInfo{
+						Location: []*descriptorpb.SourceCodeInfo_Location{
+							{
+								Path: []int32{12},
+								Span: []int32{0, 0, 18},
+							},
+						},
+					},
+				},
+			},
+		}
+		secondProtoImage := &imagev1.Image{
+			File: []*descriptorpb.FileDescriptorProto{
+				{
+					Syntax: proto.String("proto3"),
+					Name:   proto.String("a.proto"),
+				},
+			},
+		}
+
+		firstImage, err := NewImageForProto(firstProtoImage)
+		require.NoError(t, err)
+		secondImage, err := NewImageForProto(secondProtoImage)
+		require.NoError(t, err)
+		mergedImage, err := MergeImagesWithEqualDuplicates(firstImage, ImageAsImports(secondImage))
+		require.NoError(t, err)
+		require.Len(t, mergedImage.Files(), 1)
+		assert.False(t, mergedImage.GetFile("a.proto").IsImport())
+		assert.NotNil(t, mergedImage.GetFile("a.proto").Proto().GetSourceCodeInfo())
+	})
+
+	t.Run("equal duplicate import", func(t *testing.T) {
+		t.Parallel()
+		protoImage := &imagev1.Image{
+			File: []*descriptorpb.FileDescriptorProto{
+				{
+					Syntax: proto.String("proto3"),
+					Name:   proto.String("a.proto"),
+				},
+			},
+		}
+
+		firstImage, err := NewImageForProto(protoImage)
+		require.NoError(t, err)
+		secondImage, err := NewImageForProto(protoImage)
+		require.NoError(t, err)
+		mergedImage, err := MergeImagesWithEqualDuplicates(ImageAsImports(firstImage), ImageAsImports(secondImage))
+		require.NoError(t, err)
+		require.Len(t, mergedImage.Files(), 1)
+		assert.True(t, mergedImage.GetFile("a.proto").IsImport())
+	})
 }
@@ -23,6 +23,8 @@ import (
 	imagev1 "github.com/bufbuild/buf/internal/gen/proto/go/buf/alpha/image/v1"
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 func getImportFileIndexes(protoImage *imagev1.Image) (map[int]struct{}, error) {
@@ -210,3 +212,13 @@ func addFileWithImports(
 	)
 	return accumulator
 }
+
+func fileDescriptorProtosEqualWithoutSourceCodeInfo(one *descriptorpb.FileDescriptorProto, two *descriptorpb.FileDescriptorProto) bool {
+	if one.SourceCodeInfo != nil || two.SourceCodeInfo != nil {
+		one = proto.Clone(one).(*descriptorpb.FileDescriptorProto)
+		one.SourceCodeInfo = nil
+		two = proto.Clone(two).(*descriptorpb.FileDescriptorProto)
+		two.SourceCodeInfo = nil
+	}
+	return proto.Equal(one, two)
+}
@@ -187,6 +187,18 @@ func NewSourceOrModuleRefParser(logger *zap.Logger) SourceOrModuleRefParser {
 	return newSourceOrModuleRefParser(logger)
 }
 
+// IsLocalDirRef returns true if the Ref is a reference to a local directory.
+func IsLocalDirRef(ref Ref) bool {
+	_, ok := ref.internalRef().(internal.DirRef)
+	return ok
+}
+
+// IsGitRef returns true if the Ref is a reference to a git repository.
+func IsGitRef(ref Ref) bool {
+	_, ok := ref.internalRef().(internal.GitRef)
+	return ok
+}
+
 // ReadBucketCloser is a bucket returned from GetBucket.
 // We need to surface the internal.ReadBucketCloser
 // interface to other packages, so we use a type
@@ -0,0 +1,52 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bufformat formats Protobuf source files.
+package bufformat
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/desc/protoparse/ast"
+)
+
+// Format formats the Protobuf source file with the given path and data.
+//
+// The path is only used for error messages.
+//
+// The declarations of the file are printed in their original order, with
+// canonical indentation and spacing. All comments are preserved, as are single
+// blank lines between declarations.
+func Format(path string, data []byte) ([]byte, error) {
+	fileNode, err := parse(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return newPrinter(fileNode).print(), nil
+}
+
+func parse(path string, data []byte) (*ast.FileNode, error) {
+	parser := protoparse.Parser{
+		Accessor: func(string) (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	}
+	fileNodes, err := parser.ParseToAST(path)
+	if err != nil {
+		return nil, err
+	}
+	return fileNodes[0], nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufformat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAll(t *testing.T) {
+	t.Parallel()
+	testFormat(t, "all")
+}
+
+func TestFormatComments(t *testing.T) {
+	t.Parallel()
+	testFormat(t, "comments")
+}
+
+func TestFormatEmptyStatements(t *testing.T) {
+	t.Parallel()
+	testFormat(t, "empty_statements")
+}
+
+func TestFormatParseError(t *testing.T) {
+	t.Parallel()
+	_, err := Format("a.proto", []byte(`syntax = "proto3"; message Foo {`))
+	require.Error(t, err)
+}
+
+func testFormat(t *testing.T, dirPath string) {
+	input, err := os.ReadFile(filepath.Join("testdata", dirPath, "input.proto"))
+	require.NoError(t, err)
+	golden, err := os.ReadFile(filepath.Join("testdata", dirPath, "output.golden"))
+	require.NoError(t, err)
+	output, err := Format("input.proto", input)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(output))
+	// formatting must be idempotent
+	output, err = Format("input.proto", output)
+	require.NoError(t, err)
+	assert.Equal(t, string(golden), string(output))
+}
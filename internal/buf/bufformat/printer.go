@@ -0,0 +1,452 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bufformat
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/jhump/protoreflect/desc/protoparse/ast"
+)
+
+const indentString = "  "
+
+// printer prints a FileNode.
+//
+// The printer works on the flattened list of terminal nodes (tokens) of the
+// file. The structure of the file is only used to classify the tokens, for
+// example to determine which tokens start a new line, which tokens open or
+// close a block, and which tokens should not be separated by a space.
+type printer struct {
+	fileNode *ast.FileNode
+	tokens   []ast.TerminalNode
+
+	lineStarts    map[ast.Node]struct{}
+	openBlocks    map[ast.Node]struct{}
+	closeBlocks   map[ast.Node]struct{}
+	omitted       map[ast.Node]struct{}
+	noSpaceBefore map[ast.Node]struct{}
+	noSpaceAfter  map[ast.Node]struct{}
+	continuations map[ast.Node]struct{}
+
+	buffer bytes.Buffer
+	indent int
+	// noSpace is true if the next token should not be preceded by a space.
+	noSpace bool
+	// afterLineComment is true if the last element printed was a // comment.
+	//
+	// The newline that terminates a // comment is part of the comment, so
+	// whitespace that follows a // comment contains one less newline.
+	afterLineComment bool
+	// afterOpenBlock is true if the last element printed was the opening
+	// brace of a block.
+	afterOpenBlock bool
+}
+
+func newPrinter(fileNode *ast.FileNode) *printer {
+	printer := &printer{
+		fileNode:      fileNode,
+		lineStarts:    make(map[ast.Node]struct{}),
+		openBlocks:    make(map[ast.Node]struct{}),
+		closeBlocks:   make(map[ast.Node]struct{}),
+		omitted:       make(map[ast.Node]struct{}),
+		noSpaceBefore: make(map[ast.Node]struct{}),
+		noSpaceAfter:  make(map[ast.Node]struct{}),
+		continuations: make(map[ast.Node]struct{}),
+	}
+	ast.Walk(
+		fileNode,
+		func(node ast.Node) (bool, ast.VisitFunc) {
+			printer.visit(node)
+			return true, nil
+		},
+	)
+	return printer
+}
+
+func (p *printer) visit(node ast.Node) {
+	switch node := node.(type) {
+	case ast.TerminalNode:
+		p.tokens = append(p.tokens, node)
+		if runeNode, ok := node.(*ast.RuneNode); ok {
+			switch runeNode.Rune {
+			case ';', ',', ':':
+				addRune(p.noSpaceBefore, runeNode)
+			}
+		}
+	case *ast.FileNode:
+		if node.Syntax != nil {
+			p.addLineStart(node.Syntax)
+		}
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.MessageNode:
+		p.addBlock(node.OpenBrace, node.CloseBrace)
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.GroupNode:
+		p.addBlock(node.OpenBrace, node.CloseBrace)
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.OneOfNode:
+		p.addBlock(node.OpenBrace, node.CloseBrace)
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.ExtendNode:
+		p.addBlock(node.OpenBrace, node.CloseBrace)
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.EnumNode:
+		p.addBlock(node.OpenBrace, node.CloseBrace)
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.ServiceNode:
+		p.addBlock(node.OpenBrace, node.CloseBrace)
+		for _, decl := range node.Decls {
+			p.addLineStart(decl)
+		}
+	case *ast.RPCNode:
+		addRune(p.noSpaceBefore, node.Input.OpenParen)
+		if node.OpenBrace != nil {
+			p.addBlock(node.OpenBrace, node.CloseBrace)
+			for _, decl := range node.Decls {
+				p.addLineStart(decl)
+			}
+		}
+	case *ast.RPCTypeNode:
+		addRune(p.noSpaceAfter, node.OpenParen)
+		addRune(p.noSpaceBefore, node.CloseParen)
+	case *ast.MessageLiteralNode:
+		p.addBlock(node.Open, node.Close)
+		for _, element := range node.Elements {
+			p.addLineStart(element)
+		}
+		for _, sep := range node.Seps {
+			addRune(p.omitted, sep)
+		}
+	case *ast.EmptyDeclNode:
+		addRune(p.omitted, node.Semicolon)
+	case *ast.CompoundIdentNode:
+		addRune(p.noSpaceAfter, node.LeadingDot)
+		for _, dot := range node.Dots {
+			addRune(p.noSpaceBefore, dot)
+			addRune(p.noSpaceAfter, dot)
+		}
+	case *ast.OptionNameNode:
+		for _, dot := range node.Dots {
+			addRune(p.noSpaceBefore, dot)
+			addRune(p.noSpaceAfter, dot)
+		}
+	case *ast.FieldReferenceNode:
+		addRune(p.noSpaceAfter, node.Open)
+		addRune(p.noSpaceBefore, node.Close)
+	case *ast.CompactOptionsNode:
+		addRune(p.noSpaceAfter, node.OpenBracket)
+		addRune(p.noSpaceBefore, node.CloseBracket)
+	case *ast.ArrayLiteralNode:
+		addRune(p.noSpaceAfter, node.OpenBracket)
+		addRune(p.noSpaceBefore, node.CloseBracket)
+	case *ast.MapTypeNode:
+		addRune(p.noSpaceBefore, node.OpenAngle)
+		addRune(p.noSpaceAfter, node.OpenAngle)
+		addRune(p.noSpaceBefore, node.CloseAngle)
+	case *ast.NegativeIntLiteralNode:
+		addRune(p.noSpaceAfter, node.Minus)
+	case *ast.PositiveUintLiteralNode:
+		addRune(p.noSpaceAfter, node.Plus)
+	case *ast.SignedFloatLiteralNode:
+		addRune(p.noSpaceAfter, node.Sign)
+	case *ast.CompoundStringLiteralNode:
+		children := node.Children()
+		for i := 1; i < len(children); i++ {
+			p.continuations[children[i]] = struct{}{}
+		}
+	}
+}
+
+func (p *printer) addLineStart(node ast.Node) {
+	p.lineStarts[firstToken(node)] = struct{}{}
+}
+
+func (p *printer) addBlock(openBrace *ast.RuneNode, closeBrace *ast.RuneNode) {
+	addRune(p.openBlocks, openBrace)
+	addRune(p.closeBlocks, closeBrace)
+}
+
+func (p *printer) print() []byte {
+	for i := 0; i < len(p.tokens); i++ {
+		token := p.tokens[i]
+		if closeIndex, ok := p.getEmptyBlockCloseIndex(i); ok {
+			p.printMidLine(token)
+			for _, omittedToken := range p.tokens[i+1 : closeIndex] {
+				p.consume(omittedToken)
+			}
+			p.afterOpenBlock = false
+			p.noSpace = true
+			p.printMidLine(p.tokens[closeIndex])
+			p.printTrailingComments(p.tokens[closeIndex], p.isLineEnd(closeIndex))
+			i = closeIndex
+			continue
+		}
+		_, isLineStart := p.lineStarts[token]
+		_, isOpenBlock := p.openBlocks[token]
+		_, isCloseBlock := p.closeBlocks[token]
+		switch {
+		case isCloseBlock:
+			p.indent--
+			// comments before a closing brace belong to the contents of the block
+			p.printLineStart(token, p.indent+1, false)
+		case isLineStart:
+			p.printLineStart(token, p.indent, true)
+		default:
+			p.printMidLine(token)
+		}
+		if isOpenBlock {
+			p.indent++
+			p.afterOpenBlock = true
+		}
+		p.printTrailingComments(token, p.isLineEnd(i))
+	}
+	for _, comment := range p.fileNode.FinalComments {
+		p.printOwnLineComment(comment, p.indent)
+	}
+	data := bytes.TrimRight(p.buffer.Bytes(), "\n")
+	if len(data) == 0 {
+		return nil
+	}
+	return append(data, '\n')
+}
+
+// printLineStart prints a token that starts a new line, along with its
+// leading comments.
+func (p *printer) printLineStart(token ast.TerminalNode, commentIndent int, allowBlankLine bool) {
+	for _, comment := range token.LeadingComments() {
+		p.printOwnLineComment(comment, commentIndent)
+	}
+	if _, ok := p.omitted[token]; ok {
+		p.consume(token)
+		return
+	}
+	if allowBlankLine && p.hasBlankLine(token.LeadingWhitespace()) {
+		p.blankLine()
+	} else {
+		p.newline()
+	}
+	p.writeIndent(p.indent)
+	p.writeToken(token)
+}
+
+// printMidLine prints a token that does not start a new line, along with its
+// leading comments.
+func (p *printer) printMidLine(token ast.TerminalNode) {
+	for _, comment := range token.LeadingComments() {
+		if !p.afterLineComment && !isLineComment(comment) && !strings.Contains(comment.LeadingWhitespace, "\n") {
+			p.writeSpace()
+			p.writeComment(comment)
+			continue
+		}
+		p.printOwnLineComment(comment, p.indent+1)
+	}
+	if _, ok := p.omitted[token]; ok {
+		p.consume(token)
+		return
+	}
+	if _, ok := p.continuations[token]; ok && (p.afterLineComment || strings.Contains(token.LeadingWhitespace(), "\n")) {
+		p.newline()
+	}
+	if p.atLineStart() {
+		p.writeIndent(p.indent + 1)
+	} else if _, ok := p.noSpaceBefore[token]; !ok && !p.noSpace {
+		p.writeSpace()
+	}
+	p.writeToken(token)
+}
+
+// printTrailingComments prints the trailing comments of a token.
+//
+// Trailing comments that were on the same line as the token stay on the same
+// line. All other trailing comments are printed on their own line, indented
+// one level deeper if the token does not end a line.
+func (p *printer) printTrailingComments(token ast.TerminalNode, isLineEnd bool) {
+	indent := p.indent
+	if !isLineEnd {
+		indent++
+	}
+	for _, comment := range token.TrailingComments() {
+		if p.afterLineComment || strings.Contains(comment.LeadingWhitespace, "\n") || p.atLineStart() {
+			p.printOwnLineComment(comment, indent)
+			continue
+		}
+		p.writeSpace()
+		p.writeComment(comment)
+	}
+}
+
+func (p *printer) printOwnLineComment(comment ast.Comment, indent int) {
+	if p.hasBlankLine(comment.LeadingWhitespace) {
+		p.blankLine()
+	} else {
+		p.newline()
+	}
+	p.writeIndent(indent)
+	p.writeComment(comment)
+	p.newline()
+}
+
+// getEmptyBlockCloseIndex returns the index of the closing brace and true if
+// the token at the given index is the opening brace of a block with no content
+// and no comments.
+//
+// Omitted tokens such as empty statements do not count as content.
+func (p *printer) getEmptyBlockCloseIndex(i int) (int, bool) {
+	token := p.tokens[i]
+	if _, ok := p.openBlocks[token]; !ok {
+		return 0, false
+	}
+	if len(token.TrailingComments()) > 0 {
+		return 0, false
+	}
+	for j := i + 1; j < len(p.tokens); j++ {
+		next := p.tokens[j]
+		if len(next.LeadingComments()) > 0 {
+			return 0, false
+		}
+		if _, ok := p.closeBlocks[next]; ok {
+			return j, true
+		}
+		if _, ok := p.omitted[next]; !ok || len(next.TrailingComments()) > 0 {
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// isLineEnd returns true if the token at the given index ends a line.
+func (p *printer) isLineEnd(i int) bool {
+	if _, ok := p.openBlocks[p.tokens[i]]; ok {
+		return true
+	}
+	if i+1 >= len(p.tokens) {
+		return true
+	}
+	next := p.tokens[i+1]
+	if _, ok := p.lineStarts[next]; ok {
+		return true
+	}
+	_, ok := p.closeBlocks[next]
+	return ok
+}
+
+// hasBlankLine returns true if the whitespace preceding an element contained
+// a blank line.
+func (p *printer) hasBlankLine(whitespace string) bool {
+	numNewlines := strings.Count(whitespace, "\n")
+	if p.afterLineComment {
+		numNewlines++
+	}
+	return numNewlines > 1
+}
+
+// blankLine ensures that the next element is preceded by a single blank line.
+//
+// Blank lines are never printed at the start of the file or at the start
+// of a block.
+func (p *printer) blankLine() {
+	p.newline()
+	if p.buffer.Len() == 0 || p.afterOpenBlock {
+		return
+	}
+	if !bytes.HasSuffix(p.buffer.Bytes(), []byte("\n\n")) {
+		p.buffer.WriteString("\n")
+	}
+}
+
+func (p *printer) newline() {
+	if !p.atLineStart() {
+		p.buffer.WriteString("\n")
+	}
+}
+
+func (p *printer) atLineStart() bool {
+	return p.buffer.Len() == 0 || bytes.HasSuffix(p.buffer.Bytes(), []byte("\n"))
+}
+
+func (p *printer) writeIndent(indent int) {
+	if p.atLineStart() {
+		p.buffer.WriteString(strings.Repeat(indentString, indent))
+	}
+}
+
+func (p *printer) writeSpace() {
+	if !p.atLineStart() {
+		p.buffer.WriteString(" ")
+	}
+}
+
+func (p *printer) writeToken(token ast.TerminalNode) {
+	p.buffer.WriteString(token.RawText())
+	p.consume(token)
+}
+
+func (p *printer) writeComment(comment ast.Comment) {
+	if isLineComment(comment) {
+		p.buffer.WriteString(strings.TrimRightFunc(comment.Text, isSpace))
+		p.buffer.WriteString("\n")
+		p.afterLineComment = true
+	} else {
+		p.buffer.WriteString(comment.Text)
+		p.afterLineComment = false
+	}
+	p.afterOpenBlock = false
+	p.noSpace = false
+}
+
+// consume updates the state of the printer after a token was processed.
+func (p *printer) consume(token ast.TerminalNode) {
+	_, noSpaceAfter := p.noSpaceAfter[token]
+	p.noSpace = noSpaceAfter
+	p.afterLineComment = false
+	p.afterOpenBlock = false
+}
+
+func firstToken(node ast.Node) ast.Node {
+	for {
+		compositeNode, ok := node.(ast.CompositeNode)
+		if !ok {
+			return node
+		}
+		node = compositeNode.Children()[0]
+	}
+}
+
+func addRune(set map[ast.Node]struct{}, runeNode *ast.RuneNode) {
+	if runeNode != nil {
+		set[runeNode] = struct{}{}
+	}
+}
+
+func isLineComment(comment ast.Comment) bool {
+	return strings.HasPrefix(comment.Text, "//")
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
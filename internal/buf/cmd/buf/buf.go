@@ -39,9 +39,11 @@ import (
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/config/configlsbreakingrules"
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/config/configlslintrules"
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/convert"
+	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/format"
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/generate"
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/lint"
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/lsfiles"
+	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/lsfreefieldnumbers"
 	"github.com/bufbuild/buf/internal/buf/cmd/buf/command/protoc"
 	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
 	"github.com/bufbuild/buf/internal/pkg/app/appflag"
@@ -142,9 +144,11 @@ func NewRootCommand(
 			},
 			lint.NewCommand("lint", builder, moduleResolverReaderProvider, "", false),
 			breaking.NewCommand("breaking", builder, moduleResolverReaderProvider, "", false),
+			format.NewCommand("format", builder, moduleResolverReaderProvider),
 			generate.NewCommand("generate", builder, moduleResolverReaderProvider),
 			protoc.NewCommand("protoc", builder, moduleResolverReaderProvider),
 			lsfiles.NewCommand("ls-files", builder, moduleResolverReaderProvider),
+			lsfreefieldnumbers.NewCommand("ls-free-field-numbers", builder, moduleResolverReaderProvider),
 			{
 				Use:   "config",
 				Short: "Interact with the configuration of Buf.",
@@ -15,11 +15,14 @@
 package buf
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -384,6 +387,376 @@ lint:
 	)
 }
 
+func TestLintSeverity(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`testdata/fail/buf/buf.proto:3:1:info:Files with package "other" must be within a directory "other" relative to root but were in directory "fail/buf".
+        testdata/fail/buf/buf.proto:6:9:warning:Field name "oneTwo" should be lower_snake_case, such as "one_two".`,
+		"lint",
+		"--path",
+		filepath.Join("testdata", "fail", "buf", "buf.proto"),
+		filepath.Join("testdata"),
+		"--config",
+		`{"lint":{"use":["BASIC"],"severity":{"BASIC":"warning","FILE_LAYOUT":"info","PACKAGE_DIRECTORY_MATCH":"info"}}}`,
+	)
+	// the most severe category wins if the rule id is not specified
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`testdata/fail/buf/buf.proto:3:1:Files with package "other" must be within a directory "other" relative to root but were in directory "fail/buf".
+        testdata/fail/buf/buf.proto:6:9:warning:Field name "oneTwo" should be lower_snake_case, such as "one_two".`,
+		"lint",
+		"--path",
+		filepath.Join("testdata", "fail", "buf", "buf.proto"),
+		filepath.Join("testdata"),
+		"--config",
+		`{"lint":{"use":["BASIC"],"severity":{"FIELD_LOWER_SNAKE_CASE":"warning","FILE_LAYOUT":"info","MINIMAL":"error"}}}`,
+	)
+	testRunStdout(
+		t,
+		nil,
+		1,
+		``,
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--config",
+		`{"lint":{"use":["BASIC"],"severity":{"FIELD_LOWER_SNAKE_CASE":"fatal"}}}`,
+	)
+}
+
+func TestLintBaseline(t *testing.T) {
+	t.Parallel()
+	baselineFilePath := filepath.Join(t.TempDir(), "baseline.yaml")
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--write-baseline",
+		baselineFilePath,
+	)
+	data, err := os.ReadFile(baselineFilePath)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`version: v1
+entries:
+  - id: PACKAGE_DIRECTORY_MATCH
+    path: buf/buf.proto
+    count: 1
+  - id: FIELD_LOWER_SNAKE_CASE
+    path: buf/buf.proto
+    symbol: other.Foo.oneTwo
+    count: 1
+`,
+		string(data),
+	)
+	testRunStdoutStderr(
+		t,
+		nil,
+		0,
+		``,
+		"", // stderr should be empty
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--baseline",
+		baselineFilePath,
+	)
+	staleBaselineFilePath := filepath.Join("testdata", "lintbaseline", "baseline.yaml")
+	testRunStdoutStderr(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`testdata/fail/buf/buf.proto:6:9:Field name "oneTwo" should be lower_snake_case, such as "one_two".`,
+		staleBaselineFilePath+` has 1 stale entries that no longer match a check violation, run with --write-baseline to update it:
+		  buf/buf.proto:other.Bar.threeFour:FIELD_LOWER_SNAKE_CASE`,
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--baseline",
+		staleBaselineFilePath,
+	)
+	testRunStdout(
+		t,
+		nil,
+		1,
+		``,
+		"lint",
+		filepath.Join("testdata", "fail"),
+		"--baseline",
+		staleBaselineFilePath,
+		"--write-baseline",
+		baselineFilePath,
+	)
+}
+
+func TestLintFix(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "buf.yaml"),
+		`version: v1beta1
+lint:
+  use:
+    - BASIC
+    - RPC_REQUEST_STANDARD_NAME
+`,
+	)
+	aData := `syntax = "proto3";
+
+package a;
+
+import "a/b.proto";
+
+service Foo {
+  rpc Get(Query) returns (stream Result);
+}
+
+message bar {}
+`
+	testWriteFile(t, filepath.Join(tempDirPath, "a", "a.proto"), aData)
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "b.proto"),
+		`syntax = "proto3";
+
+package a;
+
+message Query {
+  int64 pageSize = 1;
+}
+
+message Result {
+  Query query = 1;
+}
+`,
+	)
+	stdout := bytes.NewBuffer(nil)
+	testRun(t, bufcli.ExitCodeFileAnnotation, nil, stdout, "lint", tempDirPath, "--diff")
+	assert.Contains(t, stdout.String(), "-  rpc Get(Query) returns (stream Result);\n+  rpc Get(GetRequest) returns (stream Result);\n")
+	assert.Contains(t, stdout.String(), "-message Query {\n-  int64 pageSize = 1;\n+message GetRequest {\n+  int64 page_size = 1;\n")
+	assert.Contains(t, stdout.String(), "-  Query query = 1;\n+  GetRequest query = 1;\n")
+	assert.Contains(
+		t,
+		stdout.String(),
+		fmt.Sprintf(
+			`%s:11:9:Message name "bar" should be PascalCase, such as "Bar".`,
+			filepath.Join(tempDirPath, "a", "a.proto"),
+		),
+	)
+	data, err := os.ReadFile(filepath.Join(tempDirPath, "a", "a.proto"))
+	require.NoError(t, err)
+	assert.Equal(t, aData, string(data))
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		fmt.Sprintf(
+			`%s:11:9:Message name "bar" should be PascalCase, such as "Bar".`,
+			filepath.Join(tempDirPath, "a", "a.proto"),
+		),
+		"lint",
+		tempDirPath,
+		"--fix",
+	)
+	data, err = os.ReadFile(filepath.Join(tempDirPath, "a", "b.proto"))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`syntax = "proto3";
+
+package a;
+
+message GetRequest {
+  int64 page_size = 1;
+}
+
+message Result {
+  GetRequest query = 1;
+}
+`,
+		string(data),
+	)
+	testRunStdout(
+		t,
+		nil,
+		1,
+		``,
+		"lint",
+		filepath.Join(tempDirPath, "image.bin"),
+		"--fix",
+	)
+}
+
+func TestLintFixPath(t *testing.T) {
+	t.Parallel()
+	tempDirPath := t.TempDir()
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "buf.yaml"),
+		`version: v1beta1
+lint:
+  use:
+    - RPC_REQUEST_STANDARD_NAME
+`,
+	)
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "a.proto"),
+		`syntax = "proto3";
+
+package a;
+
+import "a/b.proto";
+
+service Foo {
+  rpc Get(Query) returns (Result);
+}
+`,
+	)
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "b.proto"),
+		`syntax = "proto3";
+
+package a;
+
+message Query {}
+
+message Result {}
+`,
+	)
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "c.proto"),
+		`syntax = "proto3";
+
+package a;
+
+import "a/b.proto";
+
+message Other {
+  Query query = 1;
+}
+`,
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		``,
+		"lint",
+		tempDirPath,
+		"--path",
+		filepath.Join(tempDirPath, "a", "a.proto"),
+		"--fix",
+	)
+	// c.proto is not in --path, but still references the renamed message
+	data, err := os.ReadFile(filepath.Join(tempDirPath, "a", "c.proto"))
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		`syntax = "proto3";
+
+package a;
+
+import "a/b.proto";
+
+message Other {
+  GetRequest query = 1;
+}
+`,
+		string(data),
+	)
+	testRunStdout(t, nil, 0, ``, "build", tempDirPath)
+}
+
+func TestLintChangedSince(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	tempDirPath := t.TempDir()
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "buf.yaml"),
+		`version: v1beta1
+lint:
+  use:
+    - BASIC
+`,
+	)
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "a.proto"),
+		`syntax = "proto3";
+
+package a;
+
+message Foo {
+  int64 oneTwo = 1;
+}
+`,
+	)
+	testRunGit(t, tempDirPath, "init")
+	testRunGit(t, tempDirPath, "add", ".")
+	testRunGit(t, tempDirPath, "-c", "user.name=test", "-c", "user.email=test@test.com", "commit", "-m", "initial")
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "a.proto"),
+		`syntax = "proto3";
+
+package a;
+
+message Foo {
+  int64 oneTwo = 1;
+  int64 threeFour = 2;
+}
+`,
+	)
+	testWriteFile(
+		t,
+		filepath.Join(tempDirPath, "a", "b.proto"),
+		`syntax = "proto3";
+
+package a;
+
+message bar {}
+`,
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		fmt.Sprintf(
+			`%s:7:9:Field name "threeFour" should be lower_snake_case, such as "three_four".
+			%s:5:9:Message name "bar" should be PascalCase, such as "Bar".`,
+			filepath.Join(tempDirPath, "a", "a.proto"),
+			filepath.Join(tempDirPath, "a", "b.proto"),
+		),
+		"lint",
+		tempDirPath,
+		"--changed-since",
+		filepath.Join(tempDirPath, ".git")+"#ref=HEAD",
+	)
+	testRunStdout(
+		t,
+		nil,
+		1,
+		``,
+		"lint",
+		tempDirPath,
+		"--changed-since",
+		tempDirPath,
+	)
+}
+
 func TestFailArgAndDeprecatedFlag1(t *testing.T) {
 	t.Parallel()
 	testRunStdout(
@@ -535,51 +908,147 @@ func TestFailCheckBreaking1(t *testing.T) {
 	)
 }
 
+func TestFailCheckBreakingChangesetJSON(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`
+		{
+		  "changes": [
+		    {
+		      "type": "removed",
+		      "kind": "service",
+		      "name": "a.Three",
+		      "path": "../../bufcheck/bufbreaking/testdata/breaking_service_no_delete/1.proto",
+		      "message": "Previously present service \"a.Three\" was deleted.",
+		      "compatibility": "breaking",
+		      "rule_ids": [
+		        "SERVICE_NO_DELETE"
+		      ]
+		    },
+		    {
+		      "type": "removed",
+		      "kind": "service",
+		      "name": "a.Two",
+		      "path": "../../bufcheck/bufbreaking/testdata/breaking_service_no_delete/1.proto",
+		      "message": "Previously present service \"a.Two\" was deleted.",
+		      "compatibility": "breaking",
+		      "rule_ids": [
+		        "SERVICE_NO_DELETE"
+		      ]
+		    }
+		  ]
+		}
+		`,
+		"breaking",
+		// can't bother right now to filepath.Join this
+		"../../bufcheck/bufbreaking/testdata/breaking_service_no_delete",
+		"--against",
+		"../../bufcheck/bufbreaking/testdata_previous/breaking_service_no_delete",
+		"--format",
+		"changeset-json",
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		{
+		  "changes": []
+		}
+		`,
+		"breaking",
+		filepath.Join("testdata", "success"),
+		"--against",
+		filepath.Join("testdata", "success"),
+		"--format",
+		"changeset-json",
+	)
+}
+
+func TestFailCheckBreakingInvalidFormat(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		1,
+		``,
+		"breaking",
+		filepath.Join("testdata", "success"),
+		"--against",
+		filepath.Join("testdata", "success"),
+		"--format",
+		"changeset-yaml",
+	)
+}
+
 func TestCheckLsLintRules1(t *testing.T) {
 	t.Parallel()
 	expectedStdout := `
-ID                                CATEGORIES                                  PURPOSE
-DIRECTORY_SAME_PACKAGE            MINIMAL, BASIC, DEFAULT, FILE_LAYOUT        Checks that all files in a given directory are in the same package.
-PACKAGE_DIRECTORY_MATCH           MINIMAL, BASIC, DEFAULT, FILE_LAYOUT        Checks that all files are in a directory that matches their package name.
-PACKAGE_SAME_DIRECTORY            MINIMAL, BASIC, DEFAULT, FILE_LAYOUT        Checks that all files with a given package are in the same directory.
-PACKAGE_SAME_CSHARP_NAMESPACE     MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the csharp_namespace option.
-PACKAGE_SAME_GO_PACKAGE           MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the go_package option.
-PACKAGE_SAME_JAVA_MULTIPLE_FILES  MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the java_multiple_files option.
-PACKAGE_SAME_JAVA_PACKAGE         MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the java_package option.
-PACKAGE_SAME_PHP_NAMESPACE        MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the php_namespace option.
-PACKAGE_SAME_RUBY_PACKAGE         MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the ruby_package option.
-PACKAGE_SAME_SWIFT_PREFIX         MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the swift_prefix option.
-ENUM_NO_ALLOW_ALIAS               MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that enums do not have the allow_alias option set.
-FIELD_NO_DESCRIPTOR               MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that field names are not name capitalization of "descriptor" with any number of prefix or suffix underscores.
-IMPORT_NO_PUBLIC                  MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that imports are not public.
-IMPORT_NO_WEAK                    MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that imports are not weak.
-PACKAGE_DEFINED                   MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that all files have a package defined.
-ENUM_PASCAL_CASE                  BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that enums are PascalCase.
-ENUM_VALUE_UPPER_SNAKE_CASE       BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that enum values are UPPER_SNAKE_CASE.
-FIELD_LOWER_SNAKE_CASE            BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that field names are lower_snake_case.
-MESSAGE_PASCAL_CASE               BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that messages are PascalCase.
-ONEOF_LOWER_SNAKE_CASE            BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that oneof names are lower_snake_case.
-PACKAGE_LOWER_SNAKE_CASE          BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that packages are lower_snake.case.
-RPC_PASCAL_CASE                   BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that RPCs are PascalCase.
-SERVICE_PASCAL_CASE               BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that services are PascalCase.
-ENUM_VALUE_PREFIX                 DEFAULT, STYLE_DEFAULT                      Checks that enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE.
-ENUM_ZERO_VALUE_SUFFIX            DEFAULT, STYLE_DEFAULT                      Checks that enum zero values are suffixed with _UNSPECIFIED (suffix is configurable).
-FILE_LOWER_SNAKE_CASE             DEFAULT, STYLE_DEFAULT                      Checks that filenames are lower_snake_case.
-PACKAGE_VERSION_SUFFIX            DEFAULT, STYLE_DEFAULT                      Checks that the last component of all packages is a version of the form v\d+, v\d+test.*, v\d+(alpha|beta)\d+, or v\d+p\d+(alpha|beta)\d+, where numbers are >=1.
-RPC_REQUEST_RESPONSE_UNIQUE       DEFAULT, STYLE_DEFAULT                      Checks that RPC request and response types are only used in one RPC (configurable).
-RPC_REQUEST_STANDARD_NAME         DEFAULT, STYLE_DEFAULT                      Checks that RPC request type names are RPCNameRequest or ServiceNameRPCNameRequest (configurable).
-RPC_RESPONSE_STANDARD_NAME        DEFAULT, STYLE_DEFAULT                      Checks that RPC response type names are RPCNameResponse or ServiceNameRPCNameResponse (configurable).
-SERVICE_SUFFIX                    DEFAULT, STYLE_DEFAULT                      Checks that services are suffixed with Service (suffix is configurable).
-COMMENT_ENUM                      COMMENTS                                    Checks that enums have non-empty comments.
-COMMENT_ENUM_VALUE                COMMENTS                                    Checks that enum values have non-empty comments.
-COMMENT_FIELD                     COMMENTS                                    Checks that fields have non-empty comments.
-COMMENT_MESSAGE                   COMMENTS                                    Checks that messages have non-empty comments.
-COMMENT_ONEOF                     COMMENTS                                    Checks that oneof have non-empty comments.
-COMMENT_RPC                       COMMENTS                                    Checks that RPCs have non-empty comments.
-COMMENT_SERVICE                   COMMENTS                                    Checks that services have non-empty comments.
-RPC_NO_CLIENT_STREAMING           UNARY_RPC                                   Checks that RPCs are not client streaming.
-RPC_NO_SERVER_STREAMING           UNARY_RPC                                   Checks that RPCs are not server streaming.
-ENUM_FIRST_VALUE_ZERO             OTHER                                       Checks that all first values of enums have a numeric value of 0.
+ID                                 CATEGORIES                                  PURPOSE
+DIRECTORY_SAME_PACKAGE             MINIMAL, BASIC, DEFAULT, FILE_LAYOUT        Checks that all files in a given directory are in the same package.
+PACKAGE_DIRECTORY_MATCH            MINIMAL, BASIC, DEFAULT, FILE_LAYOUT        Checks that all files are in a directory that matches their package name.
+PACKAGE_SAME_DIRECTORY             MINIMAL, BASIC, DEFAULT, FILE_LAYOUT        Checks that all files with a given package are in the same directory.
+PACKAGE_SAME_CSHARP_NAMESPACE      MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the csharp_namespace option.
+PACKAGE_SAME_GO_PACKAGE            MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the go_package option.
+PACKAGE_SAME_JAVA_MULTIPLE_FILES   MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the java_multiple_files option.
+PACKAGE_SAME_JAVA_PACKAGE          MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the java_package option.
+PACKAGE_SAME_PHP_NAMESPACE         MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the php_namespace option.
+PACKAGE_SAME_RUBY_PACKAGE          MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the ruby_package option.
+PACKAGE_SAME_SWIFT_PREFIX          MINIMAL, BASIC, DEFAULT, PACKAGE_AFFINITY   Checks that all files with a given package have the same value for the swift_prefix option.
+ENUM_NO_ALLOW_ALIAS                MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that enums do not have the allow_alias option set.
+FIELD_NO_DESCRIPTOR                MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that field names are not name capitalization of "descriptor" with any number of prefix or suffix underscores.
+IMPORT_NO_PUBLIC                   MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that imports are not public.
+IMPORT_NO_WEAK                     MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that imports are not weak.
+PACKAGE_DEFINED                    MINIMAL, BASIC, DEFAULT, SENSIBLE           Checks that all files have a package defined.
+ENUM_PASCAL_CASE                   BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that enums are PascalCase.
+ENUM_VALUE_UPPER_SNAKE_CASE        BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that enum values are UPPER_SNAKE_CASE.
+FIELD_LOWER_SNAKE_CASE             BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that field names are lower_snake_case.
+MESSAGE_PASCAL_CASE                BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that messages are PascalCase.
+ONEOF_LOWER_SNAKE_CASE             BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that oneof names are lower_snake_case.
+PACKAGE_LOWER_SNAKE_CASE           BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that packages are lower_snake.case.
+RPC_PASCAL_CASE                    BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that RPCs are PascalCase.
+SERVICE_PASCAL_CASE                BASIC, DEFAULT, STYLE_BASIC, STYLE_DEFAULT  Checks that services are PascalCase.
+ENUM_VALUE_PREFIX                  DEFAULT, STYLE_DEFAULT                      Checks that enum values are prefixed with ENUM_NAME_UPPER_SNAKE_CASE.
+ENUM_ZERO_VALUE_SUFFIX             DEFAULT, STYLE_DEFAULT                      Checks that enum zero values are suffixed with _UNSPECIFIED (suffix is configurable).
+FILE_LOWER_SNAKE_CASE              DEFAULT, STYLE_DEFAULT                      Checks that filenames are lower_snake_case.
+PACKAGE_VERSION_SUFFIX             DEFAULT, STYLE_DEFAULT                      Checks that the last component of all packages is a version of the form v\d+, v\d+test.*, v\d+(alpha|beta)\d+, or v\d+p\d+(alpha|beta)\d+, where numbers are >=1.
+RPC_REQUEST_RESPONSE_UNIQUE        DEFAULT, STYLE_DEFAULT                      Checks that RPC request and response types are only used in one RPC (configurable).
+RPC_REQUEST_STANDARD_NAME          DEFAULT, STYLE_DEFAULT                      Checks that RPC request type names are RPCNameRequest or ServiceNameRPCNameRequest (configurable).
+RPC_RESPONSE_STANDARD_NAME         DEFAULT, STYLE_DEFAULT                      Checks that RPC response type names are RPCNameResponse or ServiceNameRPCNameResponse (configurable).
+SERVICE_SUFFIX                     DEFAULT, STYLE_DEFAULT                      Checks that services are suffixed with Service (suffix is configurable).
+COMMENT_ENUM                       COMMENTS                                    Checks that enums have non-empty comments that are not placeholders.
+COMMENT_ENUM_VALUE                 COMMENTS                                    Checks that enum values have non-empty comments that are not placeholders.
+COMMENT_FIELD                      COMMENTS                                    Checks that fields have non-empty comments that are not placeholders.
+COMMENT_MESSAGE                    COMMENTS                                    Checks that messages have non-empty comments that are not placeholders.
+COMMENT_ONEOF                      COMMENTS                                    Checks that oneof have non-empty comments that are not placeholders.
+COMMENT_RPC                        COMMENTS                                    Checks that RPCs have non-empty comments that are not placeholders.
+COMMENT_SERVICE                    COMMENTS                                    Checks that services have non-empty comments that are not placeholders.
+RPC_NO_CLIENT_STREAMING            UNARY_RPC                                   Checks that RPCs are not client streaming.
+RPC_NO_SERVER_STREAMING            UNARY_RPC                                   Checks that RPCs are not server streaming.
+AIP_HTTP_ANNOTATION                AIP                                         Checks that all RPCs have a google.api.http annotation.
+AIP_LIST_PAGINATION                AIP                                         Checks that List RPCs have page_size and page_token request fields and a next_page_token response field.
+AIP_STANDARD_METHOD_NAMES          AIP                                         Checks that Get, List, Create, Update, and Delete RPCs are named after their resource and have standard request and response types.
+AIP_UPDATE_MASK                    AIP                                         Checks that Update RPCs have an update_mask request field.
+COMMENT_STARTS_WITH_NAME           OTHER                                       Checks that non-empty comments start with the name of the element they document.
+DEPRECATED_COMMENT                 OTHER                                       Checks that deprecated elements have comments explaining what to use instead.
+DEPRECATED_TYPE_NO_USE             OTHER                                       Checks that fields and RPCs that use deprecated messages or enums are deprecated as well.
+ENUM_FIRST_VALUE_ZERO              OTHER                                       Checks that all first values of enums have a numeric value of 0.
+ENUM_VALUE_NUMBER_RANGE            OTHER                                       Checks that enum value numbers are within the ranges configured by enum_value_number_ranges (configurable).
+FIELD_NUMBER_MAX_GAP               OTHER                                       Checks that there are no more than 10 unused field numbers between used field numbers (gap is configurable).
+FIELD_NUMBER_RANGE                 OTHER                                       Checks that field and extension range numbers are within the ranges configured by field_number_ranges (configurable).
+IMPORT_NO_TRANSITIVE               OTHER                                       Checks that referenced files are imported directly instead of through public imports.
+IMPORT_USED                        OTHER                                       Checks that all imports are used.
+PACKAGE_NO_IMPORT_CYCLE            OTHER                                       Checks that packages do not have import cycles.
+PACKAGE_NO_IMPORT_RESTRICTED       OTHER                                       Checks that packages do not import packages restricted by package_import_restrictions (configurable).
+REQUIRE_OPTIONS                    OTHER                                       Checks that custom options required by require_options are set (configurable).
+STABLE_PACKAGE_NO_IMPORT_UNSTABLE  OTHER                                       Checks that stable packages do not import alpha, beta, or test packages.
+ENUM_VALUE_NOT_NEGATIVE            PROTO_HYGIENE                               Checks that enum values are not negative.
+FIELD_NOT_REQUIRED                 PROTO_HYGIENE                               Checks that fields are not required.
+FIELD_NO_DEFAULT_VALUE             PROTO_HYGIENE                               Checks that fields do not have default values.
+SYNTAX_SPECIFIED                   PROTO_HYGIENE                               Checks that all files have a syntax specified.
 		`
 	testRunStdout(
 		t,
@@ -624,15 +1093,20 @@ func TestCheckLsBreakingRules1(t *testing.T) {
 	expectedStdout := `
 ID                                              CATEGORIES                      PURPOSE
 ENUM_NO_DELETE                                  FILE                            Checks that enums are not deleted from a given file.
+EXTENSION_NO_DELETE                             FILE                            Checks that extensions are not deleted from a given file.
 FILE_NO_DELETE                                  FILE                            Checks that files are not deleted.
 FILE_SAME_PACKAGE                               FILE                            Checks that files have the same package.
 MESSAGE_NO_DELETE                               FILE                            Checks that messages are not deleted from a given file.
 SERVICE_NO_DELETE                               FILE                            Checks that services are not deleted from a given file.
 ENUM_VALUE_NO_DELETE                            FILE, PACKAGE                   Checks that enum values are not deleted from a given enum.
 EXTENSION_MESSAGE_NO_DELETE                     FILE, PACKAGE                   Checks that extension ranges are not deleted from a given message.
+EXTENSION_SAME_TYPE                             FILE, PACKAGE                   Checks that extensions have the same types.
 FIELD_NO_DELETE                                 FILE, PACKAGE                   Checks that fields are not deleted from a given message.
 FIELD_SAME_CTYPE                                FILE, PACKAGE                   Checks that fields have the same value for the ctype option.
 FIELD_SAME_JSTYPE                               FILE, PACKAGE                   Checks that fields have the same value for the jstype option.
+FIELD_SAME_PACKED                               FILE, PACKAGE                   Checks that repeated fields have the same packed encoding, including the proto3 default of packed.
+FIELD_SAME_PRESENCE                             FILE, PACKAGE                   Checks that singular fields have the same explicit or implicit presence.
+FIELD_SAME_TYPE                                 FILE, PACKAGE                   Checks that fields have the same types in a given message.
 FILE_SAME_CC_ENABLE_ARENAS                      FILE, PACKAGE                   Checks that files have the same value for the cc_enable_arenas option.
 FILE_SAME_CC_GENERIC_SERVICES                   FILE, PACKAGE                   Checks that files have the same value for the cc_generic_services option.
 FILE_SAME_CSHARP_NAMESPACE                      FILE, PACKAGE                   Checks that files have the same value for the csharp_namespace option.
@@ -658,11 +1132,14 @@ RPC_NO_DELETE                                   FILE, PACKAGE
 ENUM_VALUE_SAME_NAME                            FILE, PACKAGE, WIRE_JSON        Checks that enum values have the same name.
 FIELD_SAME_JSON_NAME                            FILE, PACKAGE, WIRE_JSON        Checks that fields have the same value for the json_name option.
 FIELD_SAME_NAME                                 FILE, PACKAGE, WIRE_JSON        Checks that fields have the same names in a given message.
+EXTENSION_SAME_EXTENDEE                         FILE, PACKAGE, WIRE_JSON, WIRE  Checks that extensions extend the same message.
+EXTENSION_SAME_NUMBER                           FILE, PACKAGE, WIRE_JSON, WIRE  Checks that extensions have the same numbers.
+FIELD_SAME_DEFAULT                              FILE, PACKAGE, WIRE_JSON, WIRE  Checks that fields have the same default values, including implicit defaults.
 FIELD_SAME_LABEL                                FILE, PACKAGE, WIRE_JSON, WIRE  Checks that fields have the same labels in a given message.
 FIELD_SAME_ONEOF                                FILE, PACKAGE, WIRE_JSON, WIRE  Checks that fields have the same oneofs in a given message.
-FIELD_SAME_TYPE                                 FILE, PACKAGE, WIRE_JSON, WIRE  Checks that fields have the same types in a given message.
 MESSAGE_SAME_MESSAGE_SET_WIRE_FORMAT            FILE, PACKAGE, WIRE_JSON, WIRE  Checks that messages have the same value for the message_set_wire_format option.
 MESSAGE_SAME_REQUIRED_FIELDS                    FILE, PACKAGE, WIRE_JSON, WIRE  Checks that messages have no added or deleted required fields.
+OPTION_SAME_VALUE                               FILE, PACKAGE, WIRE_JSON, WIRE  Checks that custom options listed in compared_options have the same values (configurable).
 RESERVED_ENUM_NO_DELETE                         FILE, PACKAGE, WIRE_JSON, WIRE  Checks that reserved ranges and names are not deleted from a given enum.
 RESERVED_MESSAGE_NO_DELETE                      FILE, PACKAGE, WIRE_JSON, WIRE  Checks that reserved ranges and names are not deleted from a given message.
 RPC_SAME_CLIENT_STREAMING                       FILE, PACKAGE, WIRE_JSON, WIRE  Checks that rpcs have the same client streaming value.
@@ -671,13 +1148,18 @@ RPC_SAME_REQUEST_TYPE                           FILE, PACKAGE, WIRE_JSON, WIRE
 RPC_SAME_RESPONSE_TYPE                          FILE, PACKAGE, WIRE_JSON, WIRE  Checks that rpcs are have the same response type.
 RPC_SAME_SERVER_STREAMING                       FILE, PACKAGE, WIRE_JSON, WIRE  Checks that rpcs have the same server streaming value.
 PACKAGE_ENUM_NO_DELETE                          PACKAGE                         Checks that enums are not deleted from a given package.
+PACKAGE_EXTENSION_NO_DELETE                     PACKAGE                         Checks that extensions are not deleted from a given package.
 PACKAGE_MESSAGE_NO_DELETE                       PACKAGE                         Checks that messages are not deleted from a given package.
 PACKAGE_NO_DELETE                               PACKAGE                         Checks that packages are not deleted.
 PACKAGE_SERVICE_NO_DELETE                       PACKAGE                         Checks that services are not deleted from a given package.
 ENUM_VALUE_NO_DELETE_UNLESS_NAME_RESERVED       WIRE_JSON                       Checks that enum values are not deleted from a given enum unless the name is reserved.
+EXTENSION_WIRE_JSON_COMPATIBLE_TYPE             WIRE_JSON                       Checks that extensions have wire and JSON compatible types.
 FIELD_NO_DELETE_UNLESS_NAME_RESERVED            WIRE_JSON                       Checks that fields are not deleted from a given message unless the name is reserved.
+FIELD_WIRE_JSON_COMPATIBLE_TYPE                 WIRE_JSON                       Checks that fields have wire and JSON compatible types in a given message.
 ENUM_VALUE_NO_DELETE_UNLESS_NUMBER_RESERVED     WIRE_JSON, WIRE                 Checks that enum values are not deleted from a given enum unless the number is reserved.
 FIELD_NO_DELETE_UNLESS_NUMBER_RESERVED          WIRE_JSON, WIRE                 Checks that fields are not deleted from a given message unless the number is reserved.
+EXTENSION_WIRE_COMPATIBLE_TYPE                  WIRE                            Checks that extensions have wire-compatible types.
+FIELD_WIRE_COMPATIBLE_TYPE                      WIRE                            Checks that fields have wire-compatible types in a given message.
 		`
 	testRunStdout(
 		t,
@@ -761,6 +1243,81 @@ func TestLsFiles(t *testing.T) {
 	)
 }
 
+func TestLsFreeFieldNumbers(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		ff.Foo.MEntry                       free: 3-INF
+		ff.Foo.G.InG                        free: 1 3-INF
+		ff.Foo.Nested                       free: 2-99
+		ff.Foo                              free: 11 14-19 21-536870910
+		ff.Empty                            free: 1-INF
+		`,
+		"ls-free-field-numbers",
+		filepath.Join("testdata", "freefieldnumbers"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		{"path":"testdata/freefieldnumbers/ff.proto","message":"ff.Foo.MEntry","free":[{"start":3,"end":536870911}]}
+		{"path":"testdata/freefieldnumbers/ff.proto","message":"ff.Foo.G.InG","free":[{"start":1,"end":1},{"start":3,"end":536870911}]}
+		{"path":"testdata/freefieldnumbers/ff.proto","message":"ff.Foo.Nested","free":[{"start":2,"end":99}]}
+		{"path":"testdata/freefieldnumbers/ff.proto","message":"ff.Foo","free":[{"start":11,"end":11},{"start":14,"end":19},{"start":21,"end":536870910}]}
+		{"path":"testdata/freefieldnumbers/ff.proto","message":"ff.Empty","free":[{"start":1,"end":536870911}]}
+		`,
+		"ls-free-field-numbers",
+		"--format",
+		"json",
+		filepath.Join("testdata", "freefieldnumbers"),
+	)
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	testRunStdout(
+		t,
+		nil,
+		0,
+		`
+		syntax = "proto3";
+
+		package buf;
+
+		import "google/protobuf/descriptor.proto";
+
+		message Foo {
+		  int64 one = 1;
+		  google.protobuf.DescriptorProto two = 2;
+		}
+		`,
+		"format",
+		"--exit-code",
+		filepath.Join("testdata", "success"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		bufcli.ExitCodeFileAnnotation,
+		`
+		syntax = "proto3";
+		package buf;
+
+		message Foo {
+		  // comment
+		  int64 one = 1;
+		}
+		`,
+		"format",
+		"--exit-code",
+		filepath.Join("testdata", "format"),
+	)
+}
+
 func TestLsFilesImage1(t *testing.T) {
 	t.Parallel()
 	stdout := bytes.NewBuffer(nil)
@@ -999,6 +1556,87 @@ func testConfigInit(t *testing.T, expectedData string, document bool, uncomment
 	require.Equal(t, expectedData, string(data))
 }
 
+func testWriteFile(t *testing.T, filePath string, data string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.NoError(t, os.WriteFile(filePath, []byte(data), 0600))
+}
+
+func testRunGit(t *testing.T, dirPath string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dirPath
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+func TestLintPluginsNonLocalInput(t *testing.T) {
+	t.Parallel()
+	archivePath := filepath.Join(t.TempDir(), "input.tar")
+	testWriteTar(
+		t,
+		archivePath,
+		map[string]string{
+			"buf.yaml": `version: v1beta1
+lint:
+  plugins:
+    - name: test
+      path: ./buf-plugin-test
+`,
+			"a.proto": `syntax = "proto3";
+
+package a;
+`,
+		},
+	)
+	// plugins are not run by commands that do not check
+	testRunStdout(t, nil, 0, ``, "build", archivePath, "-o", os.DevNull)
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failed to "lint": lint plugins can only be used with local inputs or --config.`,
+		"lint",
+		archivePath,
+	)
+}
+
+func TestLintPluginRelativePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin test uses a shell script")
+	}
+	t.Parallel()
+	tempDir := t.TempDir()
+	testWriteFile(
+		t,
+		filepath.Join(tempDir, bufconfig.ExternalConfigV1Beta1FilePath),
+		`version: v1beta1
+lint:
+  plugins:
+    - name: test
+      path: bin/buf-plugin-test
+`,
+	)
+	testWriteFile(t, filepath.Join(tempDir, "a.proto"), "syntax = \"proto3\";\n\npackage a;\n")
+	// the plugin only fails with a known message, so that running it can be
+	// distinguished from not finding it
+	pluginPath := filepath.Join(tempDir, "bin", "buf-plugin-test")
+	testWriteFile(t, pluginPath, "#!/bin/sh\necho plugin ran >&2\nexit 1\n")
+	require.NoError(t, os.Chmod(pluginPath, 0700))
+	// the plugin path is relative to the directory of buf.yaml, not to the
+	// current directory
+	testRunStdoutStderr(
+		t,
+		nil,
+		1,
+		``,
+		`Failed to "lint": plugin "test" failed: exit status 1: plugin ran.`,
+		"lint",
+		tempDir,
+	)
+}
+
 func testRunStdout(t *testing.T, stdin io.Reader, expectedExitCode int, expectedStdout string, args ...string) {
 	t.Helper()
 	appcmdtesting.RunCommandExitCodeStdout(
@@ -1089,3 +1727,24 @@ func testNewRootCommand(use string) *appcmd.Command {
 func useEnvVar(use string, suffix string) string {
 	return strings.ToUpper(use) + "_" + suffix
 }
+
+func testWriteTar(t *testing.T, path string, pathToData map[string]string) {
+	buffer := bytes.NewBuffer(nil)
+	tarWriter := tar.NewWriter(buffer)
+	for filePath, data := range pathToData {
+		require.NoError(
+			t,
+			tarWriter.WriteHeader(
+				&tar.Header{
+					Name: filePath,
+					Mode: 0644,
+					Size: int64(len(data)),
+				},
+			),
+		)
+		_, err := tarWriter.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tarWriter.Close())
+	require.NoError(t, os.WriteFile(path, buffer.Bytes(), 0600))
+}
@@ -37,6 +37,7 @@ import (
 
 const (
 	errorFormatFlagName       = "error-format"
+	formatFlagName            = "format"
 	excludeImportsFlagName    = "exclude-imports"
 	pathsFlagName             = "path"
 	limitToInputFilesFlagName = "limit-to-input-files"
@@ -54,8 +55,16 @@ const (
 	againstInputConfigFlagName = "against-input-config"
 	// deprecated
 	filesFlagName = "file"
+
+	formatFailures      = "failures"
+	formatChangesetJSON = "changeset-json"
 )
 
+var allFormats = []string{
+	formatFailures,
+	formatChangesetJSON,
+}
+
 // NewCommand returns a new Command.
 func NewCommand(
 	name string,
@@ -84,6 +93,7 @@ func NewCommand(
 
 type flags struct {
 	ErrorFormat       string
+	Format            string
 	ExcludeImports    bool
 	LimitToInputFiles bool
 	Paths             []string
@@ -121,6 +131,21 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 			stringutil.SliceToString(bufanalysis.AllFormatStrings),
 		),
 	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		formatFailures,
+		fmt.Sprintf(
+			`The output format, printed to stdout. Must be one of %s.
+%q prints the check violations in the format of --%s.
+%q prints all changes between the input and the against input as JSON, each either compatible or breaking.
+The exit code is the same for both formats.`,
+			stringutil.SliceToString(allFormats),
+			formatFailures,
+			errorFormatFlagName,
+			formatChangesetJSON,
+		),
+	)
 	flagSet.BoolVar(
 		&f.ExcludeImports,
 		excludeImportsFlagName,
@@ -256,6 +281,9 @@ func run(
 	if againstInput == "" {
 		return bufcli.NewFlagIsRequiredError(againstFlagName)
 	}
+	if flags.Format != formatFailures && flags.Format != formatChangesetJSON {
+		return appcmd.NewInvalidArgumentErrorf("--%s must be one of %s", formatFlagName, stringutil.SliceToString(allFormats))
+	}
 	paths, err := bufcli.GetStringSliceFlagOrDeprecatedFlag(
 		flags.Paths,
 		pathsFlagName,
@@ -309,6 +337,11 @@ func run(
 		}
 		return errors.New("")
 	}
+	for _, imageConfig := range imageConfigs {
+		if imageConfig.Config().Breaking.HasPlugins() && !bufcli.CheckPluginsAllowed(ref, inputConfig) {
+			return fmt.Errorf("breaking plugins can only be used with local inputs or --%s", configFlagName)
+		}
+	}
 	// TODO: this doesn't actually work because we're using the same file paths for both sides
 	// if the roots change, then we're torched
 	externalPaths := paths
@@ -361,6 +394,7 @@ func run(
 		return fmt.Errorf("input contained %d images, whereas against contained %d images", len(imageConfigs), len(againstImageConfigs))
 	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allChanges []bufbreaking.Change
 	for i, imageConfig := range imageConfigs {
 		fileAnnotations, err := breakingForImage(
 			ctx,
@@ -374,19 +408,46 @@ func run(
 			return err
 		}
 		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+		if flags.Format == formatChangesetJSON {
+			changes, err := diffForImage(
+				ctx,
+				container,
+				imageConfig,
+				againstImageConfigs[i],
+				flags.ExcludeImports,
+			)
+			if err != nil {
+				return err
+			}
+			allChanges = append(allChanges, changes...)
+		}
 	}
-	if len(allFileAnnotations) > 0 {
-		allFileAnnotations, err = bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations)
-		if err != nil {
+	allFileAnnotations, err = bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations)
+	if err != nil {
+		return err
+	}
+	if flags.Format == formatChangesetJSON {
+		// a document is always printed, even if there are no changes
+		if err := bufbreaking.PrintChanges(
+			container.Stdout(),
+			allChanges,
+		); err != nil {
 			return err
 		}
-		if err := bufanalysis.PrintFileAnnotations(
+	} else {
+		// this prints nothing if there are no FileAnnotations, except for the sarif
+		// and junit formats, for which an empty document is printed so that it can
+		// still be uploaded
+		if err := bufbreaking.PrintFileAnnotations(
 			container.Stdout(),
 			allFileAnnotations,
 			flags.ErrorFormat,
 		); err != nil {
 			return err
 		}
+	}
+	// only errors result in a non-zero exit code, warnings and infos are only printed
+	if bufanalysis.HasSeverityError(allFileAnnotations) {
 		return bufcli.ErrFileAnnotation
 	}
 	return nil
@@ -416,6 +477,29 @@ func breakingForImage(
 	)
 }
 
+func diffForImage(
+	ctx context.Context,
+	container appflag.Container,
+	imageConfig bufwire.ImageConfig,
+	againstImageConfig bufwire.ImageConfig,
+	excludeImports bool,
+) ([]bufbreaking.Change, error) {
+	image := imageConfig.Image()
+	if excludeImports {
+		image = bufimage.ImageWithoutImports(image)
+	}
+	againstImage := againstImageConfig.Image()
+	if excludeImports {
+		againstImage = bufimage.ImageWithoutImports(againstImage)
+	}
+	return bufbreaking.NewHandler(container.Logger()).Diff(
+		ctx,
+		imageConfig.Config().Breaking,
+		againstImage,
+		image,
+	)
+}
+
 func getExternalPathsForImages(imageConfigs []bufwire.ImageConfig, excludeImports bool) ([]string, error) {
 	externalPaths := make(map[string]struct{})
 	for _, imageConfig := range imageConfigs {
@@ -110,7 +110,11 @@ func run(
 		if err != nil {
 			return err
 		}
-		rules = config.Breaking.GetRules()
+		breakingConfig, err := bufbreaking.LoadPlugins(ctx, config.Breaking)
+		if err != nil {
+			return err
+		}
+		rules = breakingConfig.GetRules()
 	}
 	return bufcheck.PrintRules(
 		container.Stdout(),
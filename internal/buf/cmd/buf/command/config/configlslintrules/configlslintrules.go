@@ -110,7 +110,11 @@ func run(
 		if err != nil {
 			return err
 		}
-		rules = config.Lint.GetRules()
+		lintConfig, err := buflint.LoadPlugins(ctx, config.Lint)
+		if err != nil {
+			return err
+		}
+		rules = lintConfig.GetRules()
 	}
 	return bufcheck.PrintRules(
 		container.Stdout(),
@@ -0,0 +1,239 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package format
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+
+	"github.com/bufbuild/buf/internal/buf/bufcli"
+	"github.com/bufbuild/buf/internal/buf/bufconfig"
+	"github.com/bufbuild/buf/internal/buf/buffetch"
+	"github.com/bufbuild/buf/internal/buf/bufformat"
+	"github.com/bufbuild/buf/internal/buf/bufwork"
+	"github.com/bufbuild/buf/internal/pkg/app"
+	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
+	"github.com/bufbuild/buf/internal/pkg/app/appflag"
+	"github.com/bufbuild/buf/internal/pkg/diff"
+	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	configFlagName     = "config"
+	pathsFlagName      = "path"
+	diffFlagName       = "diff"
+	diffFlagShortName  = "d"
+	writeFlagName      = "write"
+	writeFlagShortName = "w"
+	exitCodeFlagName   = "exit-code"
+)
+
+// errDiff is returned when --exit-code is set and at least one file is not formatted.
+//
+// The diff or formatted content has already been printed, so there is no message.
+var errDiff = app.NewError(bufcli.ExitCodeFileAnnotation, "")
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+	moduleResolverReaderProvider bufcli.ModuleResolverReaderProvider,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input>",
+		Short: "Format all Protobuf files from the input.",
+		Long: bufcli.GetSourceOrModuleLong(`the source or module to format`) + `
+
+By default, the formatted content of all files is printed to stdout.
+All comments are preserved.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags, moduleResolverReaderProvider)
+			},
+			bufcli.NewErrorInterceptor(name),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	Config   string
+	Paths    []string
+	Diff     bool
+	Write    bool
+	ExitCode bool
+
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The config file or data to use.`,
+	)
+	flagSet.BoolVarP(
+		&f.Diff,
+		diffFlagName,
+		diffFlagShortName,
+		false,
+		"Print a diff of the files that are not formatted instead of the formatted content.",
+	)
+	flagSet.BoolVarP(
+		&f.Write,
+		writeFlagName,
+		writeFlagShortName,
+		false,
+		`Rewrite the files that are not formatted instead of printing the formatted content.
+Can only be used with a local directory input. Can be combined with --diff.`,
+	)
+	flagSet.BoolVar(
+		&f.ExitCode,
+		exitCodeFlagName,
+		false,
+		"Exit with a non-zero exit code if any file is not formatted.",
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+	moduleResolverReaderProvider bufcli.ModuleResolverReaderProvider,
+) error {
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, "", "", ".")
+	if err != nil {
+		return err
+	}
+	sourceOrModuleRef, err := buffetch.NewSourceOrModuleRefParser(container.Logger()).GetSourceOrModuleRef(ctx, input)
+	if err != nil {
+		return err
+	}
+	if flags.Write && !buffetch.IsLocalDirRef(sourceOrModuleRef) {
+		return appcmd.NewInvalidArgumentErrorf("--%s can only be used with a local directory input", writeFlagName)
+	}
+	configProvider := bufconfig.NewProvider(container.Logger())
+	workspaceConfigProvider := bufwork.NewProvider(container.Logger())
+	moduleResolver, err := moduleResolverReaderProvider.GetModuleResolver(ctx, container)
+	if err != nil {
+		return err
+	}
+	moduleReader, err := moduleResolverReaderProvider.GetModuleReader(ctx, container)
+	if err != nil {
+		return err
+	}
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	moduleConfigs, err := bufcli.NewWireModuleConfigReader(
+		container.Logger(),
+		storageosProvider,
+		configProvider,
+		workspaceConfigProvider,
+		moduleResolver,
+		moduleReader,
+	).GetModuleConfigs(
+		ctx,
+		container,
+		sourceOrModuleRef,
+		flags.Config,
+		flags.Paths,
+		false,
+	)
+	if err != nil {
+		return err
+	}
+	var hasDiff bool
+	for _, moduleConfig := range moduleConfigs {
+		module := moduleConfig.Module()
+		fileInfos, err := module.TargetFileInfos(ctx)
+		if err != nil {
+			return err
+		}
+		for _, fileInfo := range fileInfos {
+			moduleFile, err := module.GetModuleFile(ctx, fileInfo.Path())
+			if err != nil {
+				return err
+			}
+			data, err := io.ReadAll(moduleFile)
+			if err != nil {
+				_ = moduleFile.Close()
+				return err
+			}
+			if err := moduleFile.Close(); err != nil {
+				return err
+			}
+			formattedData, err := bufformat.Format(fileInfo.ExternalPath(), data)
+			if err != nil {
+				return err
+			}
+			if !bytes.Equal(data, formattedData) {
+				hasDiff = true
+			} else if flags.Diff || flags.Write {
+				continue
+			}
+			if !flags.Diff && !flags.Write {
+				if _, err := container.Stdout().Write(formattedData); err != nil {
+					return err
+				}
+				continue
+			}
+			if flags.Diff {
+				diffData, err := diff.Diff(
+					ctx,
+					data,
+					formattedData,
+					fileInfo.ExternalPath(),
+					fileInfo.ExternalPath(),
+				)
+				if err != nil {
+					return err
+				}
+				if _, err := container.Stdout().Write(diffData); err != nil {
+					return err
+				}
+			}
+			if flags.Write {
+				if err := writeFile(fileInfo.ExternalPath(), formattedData); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if flags.ExitCode && hasDiff {
+		return errDiff
+	}
+	return nil
+}
+
+func writeFile(filePath string, data []byte) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, fileInfo.Mode().Perm())
+}
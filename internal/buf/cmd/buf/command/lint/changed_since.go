@@ -0,0 +1,208 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"io"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufmodule"
+	"github.com/bufbuild/buf/internal/buf/buffetch"
+	"github.com/bufbuild/buf/internal/buf/bufwire"
+	"github.com/bufbuild/buf/internal/pkg/app"
+	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
+	"github.com/bufbuild/buf/internal/pkg/diff"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// getChangedSinceRef returns the git input for --changed-since.
+//
+// The input must be a source or module input so that the changed lines can be computed.
+func getChangedSinceRef(
+	ctx context.Context,
+	logger *zap.Logger,
+	ref buffetch.Ref,
+	changedSince string,
+) (buffetch.SourceRef, error) {
+	if _, ok := ref.(buffetch.SourceOrModuleRef); !ok {
+		return nil, appcmd.NewInvalidArgumentErrorf("--%s can only be used with source or module inputs", changedSinceFlagName)
+	}
+	changedSinceRef, err := buffetch.NewSourceRefParser(logger).GetSourceRef(ctx, changedSince)
+	if err != nil {
+		return nil, appcmd.NewInvalidArgumentErrorf("--%s: %v", changedSinceFlagName, err)
+	}
+	if !buffetch.IsGitRef(changedSinceRef) {
+		return nil, appcmd.NewInvalidArgumentErrorf("--%s must be a git input, for example .git#branch=main", changedSinceFlagName)
+	}
+	return changedSinceRef, nil
+}
+
+// getPathToChangedLineRanges returns a map from path to the line ranges that
+// changed in the input since the git input.
+//
+// Files that did not change are not in the map. Files that were added have a
+// single line range covering the entire file.
+func getPathToChangedLineRanges(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	moduleConfigReader bufwire.ModuleConfigReader,
+	sourceOrModuleRef buffetch.SourceOrModuleRef,
+	changedSinceRef buffetch.SourceRef,
+	config string,
+) (map[string][]diff.LineRange, error) {
+	pathToData, err := getPathToData(ctx, container, moduleConfigReader, sourceOrModuleRef, config)
+	if err != nil {
+		return nil, err
+	}
+	changedSincePathToData, err := getPathToData(ctx, container, moduleConfigReader, changedSinceRef, config)
+	if err != nil {
+		return nil, err
+	}
+	pathToChangedLineRanges := make(map[string][]diff.LineRange)
+	for path, data := range pathToData {
+		// if the file did not exist, this is compared to empty data,
+		// resulting in the entire file being changed
+		changedLineRanges, err := diff.ChangedLineRanges(ctx, changedSincePathToData[path], data)
+		if err != nil {
+			return nil, err
+		}
+		if len(changedLineRanges) > 0 {
+			pathToChangedLineRanges[path] = changedLineRanges
+		}
+	}
+	return pathToChangedLineRanges, nil
+}
+
+// filterFileAnnotationsForChangedLineRanges returns the FileAnnotations
+// whose range overlaps a changed line range.
+//
+// FileAnnotations without a location are kept if their file changed, and
+// FileAnnotations without a file are always kept.
+func filterFileAnnotationsForChangedLineRanges(
+	fileAnnotations []bufanalysis.FileAnnotation,
+	pathToChangedLineRanges map[string][]diff.LineRange,
+) []bufanalysis.FileAnnotation {
+	var filteredFileAnnotations []bufanalysis.FileAnnotation
+	for _, fileAnnotation := range fileAnnotations {
+		fileInfo := fileAnnotation.FileInfo()
+		if fileInfo == nil {
+			filteredFileAnnotations = append(filteredFileAnnotations, fileAnnotation)
+			continue
+		}
+		changedLineRanges, ok := pathToChangedLineRanges[fileInfo.Path()]
+		if !ok {
+			continue
+		}
+		if fileAnnotation.StartLine() == 0 {
+			filteredFileAnnotations = append(filteredFileAnnotations, fileAnnotation)
+			continue
+		}
+		startLine := fileAnnotation.StartLine()
+		endLine := fileAnnotation.EndLine()
+		if endLine < startLine {
+			endLine = startLine
+		}
+		for _, changedLineRange := range changedLineRanges {
+			if startLine <= changedLineRange.End && changedLineRange.Start <= endLine {
+				filteredFileAnnotations = append(filteredFileAnnotations, fileAnnotation)
+				break
+			}
+		}
+	}
+	return filteredFileAnnotations
+}
+
+func getPathToData(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	moduleConfigReader bufwire.ModuleConfigReader,
+	sourceOrModuleRef buffetch.SourceOrModuleRef,
+	config string,
+) (map[string][]byte, error) {
+	pathToModuleFile, err := getPathToModuleFile(ctx, container, moduleConfigReader, sourceOrModuleRef, config)
+	if err != nil {
+		return nil, err
+	}
+	pathToData := make(map[string][]byte, len(pathToModuleFile))
+	for path, moduleFile := range pathToModuleFile {
+		pathToData[path] = moduleFile.data
+	}
+	return pathToData, nil
+}
+
+// moduleFile is the data of a file of a module.
+type moduleFile struct {
+	externalPath string
+	data         []byte
+}
+
+// getPathToModuleFile returns the map from path to moduleFile for all files
+// of all modules of the input.
+func getPathToModuleFile(
+	ctx context.Context,
+	container app.EnvStdinContainer,
+	moduleConfigReader bufwire.ModuleConfigReader,
+	sourceOrModuleRef buffetch.SourceOrModuleRef,
+	config string,
+) (map[string]*moduleFile, error) {
+	moduleConfigs, err := moduleConfigReader.GetModuleConfigs(
+		ctx,
+		container,
+		sourceOrModuleRef,
+		config,
+		nil,
+		false,
+	)
+	if err != nil {
+		return nil, err
+	}
+	pathToModuleFile := make(map[string]*moduleFile)
+	for _, moduleConfig := range moduleConfigs {
+		module := moduleConfig.Module()
+		// no paths are given, so these are all files of the module
+		fileInfos, err := module.TargetFileInfos(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, fileInfo := range fileInfos {
+			data, err := readModuleFile(ctx, module, fileInfo.Path())
+			if err != nil {
+				return nil, err
+			}
+			pathToModuleFile[fileInfo.Path()] = &moduleFile{
+				externalPath: fileInfo.ExternalPath(),
+				data:         data,
+			}
+		}
+	}
+	return pathToModuleFile, nil
+}
+
+func readModuleFile(
+	ctx context.Context,
+	module bufmodule.Module,
+	path string,
+) (_ []byte, retErr error) {
+	moduleFile, err := module.GetModuleFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, moduleFile.Close())
+	}()
+	return io.ReadAll(moduleFile)
+}
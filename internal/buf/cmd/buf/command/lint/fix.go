@@ -0,0 +1,171 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"os"
+	"sort"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
+	"github.com/bufbuild/buf/internal/buf/buffetch"
+	"github.com/bufbuild/buf/internal/buf/bufwire"
+	"github.com/bufbuild/buf/internal/pkg/app"
+	"github.com/bufbuild/buf/internal/pkg/diff"
+)
+
+// fixFileAnnotations fixes the FileAnnotations that can be fixed automatically.
+//
+// The imageConfigs must contain all files of the modules of the input.
+//
+// A diff of the fixes is printed if printDiff is set, and the files are
+// rewritten if write is set.
+//
+// Returns the FileAnnotations that were not fixed, and whether any file was
+// rewritten.
+func fixFileAnnotations(
+	ctx context.Context,
+	container app.EnvStdioContainer,
+	moduleConfigReader bufwire.ModuleConfigReader,
+	sourceOrModuleRef buffetch.SourceOrModuleRef,
+	config string,
+	imageConfigs []bufwire.ImageConfig,
+	fileAnnotations []bufanalysis.FileAnnotation,
+	printDiff bool,
+	write bool,
+) ([]bufanalysis.FileAnnotation, bool, error) {
+	pathToModuleFile, err := getPathToModuleFile(ctx, container, moduleConfigReader, sourceOrModuleRef, config)
+	if err != nil {
+		return nil, false, err
+	}
+	pathToData := make(map[string][]byte, len(pathToModuleFile))
+	for path, moduleFile := range pathToModuleFile {
+		pathToData[path] = moduleFile.data
+	}
+	// the modules of a workspace can reference each other, so each module is
+	// fixed with all files of all modules
+	images := make([]bufimage.Image, len(imageConfigs))
+	for i, imageConfig := range imageConfigs {
+		images[i] = imageConfig.Image()
+	}
+	image, err := bufimage.MergeImages(images...)
+	if err != nil {
+		return nil, false, err
+	}
+	allPathToFixedData := make(map[string][]byte)
+	for _, imageConfig := range imageConfigs {
+		// each module is fixed with its own config, so only the FileAnnotations
+		// of the files of the module are given
+		moduleFileAnnotations, otherFileAnnotations := splitFileAnnotationsForImage(
+			fileAnnotations,
+			imageConfig.Image(),
+		)
+		pathToFixedData, unfixedFileAnnotations, err := buflint.Fix(
+			ctx,
+			imageConfig.Config().Lint,
+			image,
+			moduleFileAnnotations,
+			pathToData,
+		)
+		if err != nil {
+			return nil, false, err
+		}
+		if hasPathConflict(allPathToFixedData, pathToFixedData) {
+			// the fixes of multiple modules of a workspace touch the same file,
+			// these FileAnnotations are left unfixed until the next run
+			continue
+		}
+		for path, fixedData := range pathToFixedData {
+			allPathToFixedData[path] = fixedData
+		}
+		fileAnnotations = append(otherFileAnnotations, unfixedFileAnnotations...)
+	}
+	bufanalysis.SortFileAnnotations(fileAnnotations)
+	paths := make([]string, 0, len(allPathToFixedData))
+	for path := range allPathToFixedData {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		moduleFile := pathToModuleFile[path]
+		fixedData := allPathToFixedData[path]
+		if printDiff {
+			diffData, err := diff.Diff(
+				ctx,
+				moduleFile.data,
+				fixedData,
+				moduleFile.externalPath,
+				moduleFile.externalPath,
+			)
+			if err != nil {
+				return nil, false, err
+			}
+			if _, err := container.Stdout().Write(diffData); err != nil {
+				return nil, false, err
+			}
+		}
+		if write {
+			if err := writeFile(moduleFile.externalPath, fixedData); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return fileAnnotations, write && len(paths) > 0, nil
+}
+
+// splitFileAnnotationsForImage returns the FileAnnotations for the non-import
+// files of the image, and all other FileAnnotations.
+func splitFileAnnotationsForImage(
+	fileAnnotations []bufanalysis.FileAnnotation,
+	image bufimage.Image,
+) ([]bufanalysis.FileAnnotation, []bufanalysis.FileAnnotation) {
+	paths := make(map[string]struct{})
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			paths[imageFile.Path()] = struct{}{}
+		}
+	}
+	var imageFileAnnotations []bufanalysis.FileAnnotation
+	var otherFileAnnotations []bufanalysis.FileAnnotation
+	for _, fileAnnotation := range fileAnnotations {
+		if fileInfo := fileAnnotation.FileInfo(); fileInfo != nil {
+			if _, ok := paths[fileInfo.Path()]; ok {
+				imageFileAnnotations = append(imageFileAnnotations, fileAnnotation)
+				continue
+			}
+		}
+		otherFileAnnotations = append(otherFileAnnotations, fileAnnotation)
+	}
+	return imageFileAnnotations, otherFileAnnotations
+}
+
+func hasPathConflict(one map[string][]byte, two map[string][]byte) bool {
+	for path := range two {
+		if _, ok := one[path]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func writeFile(filePath string, data []byte) error {
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, fileInfo.Mode().Perm())
+}
@@ -17,6 +17,7 @@ package lint
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
@@ -25,18 +26,25 @@ import (
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
 	"github.com/bufbuild/buf/internal/buf/buffetch"
 	"github.com/bufbuild/buf/internal/buf/bufwork"
+	"github.com/bufbuild/buf/internal/pkg/app"
 	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
 	"github.com/bufbuild/buf/internal/pkg/app/appflag"
 	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"github.com/bufbuild/buf/internal/pkg/stringutil"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.uber.org/multierr"
 )
 
 const (
-	errorFormatFlagName = "error-format"
-	configFlagName      = "config"
-	pathsFlagName       = "path"
+	errorFormatFlagName   = "error-format"
+	configFlagName        = "config"
+	pathsFlagName         = "path"
+	baselineFlagName      = "baseline"
+	writeBaselineFlagName = "write-baseline"
+	changedSinceFlagName  = "changed-since"
+	fixFlagName           = "fix"
+	diffFlagName          = "diff"
 
 	// deprecated
 	inputFlagName = "input"
@@ -73,9 +81,14 @@ func NewCommand(
 }
 
 type flags struct {
-	ErrorFormat string
-	Config      string
-	Paths       []string
+	ErrorFormat   string
+	Config        string
+	Paths         []string
+	Baseline      string
+	WriteBaseline string
+	ChangedSince  string
+	Fix           bool
+	Diff          bool
 
 	// deprecated
 	Input string
@@ -109,6 +122,52 @@ func (f *flags) Bind(flagSet *pflag.FlagSet) {
 		"",
 		`The config file or data to use.`,
 	)
+	flagSet.StringVar(
+		&f.Baseline,
+		baselineFlagName,
+		"",
+		fmt.Sprintf(
+			`The baseline file to use. Check violations that are in the baseline are not printed.
+Baseline entries that no longer match a check violation are printed to stderr.
+Baseline files are created with --%s.`,
+			writeBaselineFlagName,
+		),
+	)
+	flagSet.StringVar(
+		&f.WriteBaseline,
+		writeBaselineFlagName,
+		"",
+		fmt.Sprintf(
+			`Write all check violations to the given baseline file instead of printing them.
+The baseline file can then be used with --%s to only print new check violations.`,
+			baselineFlagName,
+		),
+	)
+	flagSet.StringVar(
+		&f.ChangedSince,
+		changedSinceFlagName,
+		"",
+		`Only print check violations on lines that changed since the given git input, for example .git#branch=main.
+All files are still checked, but check violations outside of the changed lines are not printed.
+The git input must contain the input at the same location, use the subdir option if necessary.`,
+	)
+	flagSet.BoolVar(
+		&f.Fix,
+		fixFlagName,
+		false,
+		`Rewrite the files to fix the check violations that can be fixed automatically, and print the remaining check violations.
+Renames update every reference within the input. Can only be used with a local directory input. Can be combined with --diff.`,
+	)
+	flagSet.BoolVar(
+		&f.Diff,
+		diffFlagName,
+		false,
+		fmt.Sprintf(
+			`Print a diff of the fixes for the check violations that can be fixed automatically, followed by the remaining check violations.
+Without --%s, the files are not rewritten and the check violations that can be fixed still result in a non-zero exit code.`,
+			fixFlagName,
+		),
+	)
 
 	// deprecated
 	flagSet.StringVar(
@@ -167,10 +226,39 @@ func run(
 	if err != nil {
 		return err
 	}
+	if flags.Baseline != "" && flags.WriteBaseline != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s and --%s cannot be used together", baselineFlagName, writeBaselineFlagName)
+	}
+	if flags.ChangedSince != "" && flags.WriteBaseline != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s and --%s cannot be used together", changedSinceFlagName, writeBaselineFlagName)
+	}
+	if (flags.Fix || flags.Diff) && flags.WriteBaseline != "" {
+		return appcmd.NewInvalidArgumentErrorf("--%s and --%s cannot be used with --%s", fixFlagName, diffFlagName, writeBaselineFlagName)
+	}
+	var baseline *buflint.Baseline
+	if flags.Baseline != "" {
+		baseline, err = readBaseline(flags.Baseline)
+		if err != nil {
+			return err
+		}
+	}
 	ref, err := buffetch.NewRefParser(container.Logger()).GetRef(ctx, input)
 	if err != nil {
 		return err
 	}
+	if flags.Fix && !buffetch.IsLocalDirRef(ref) {
+		return appcmd.NewInvalidArgumentErrorf("--%s can only be used with a local directory input", fixFlagName)
+	}
+	if _, ok := ref.(buffetch.SourceOrModuleRef); flags.Diff && !ok {
+		return appcmd.NewInvalidArgumentErrorf("--%s can only be used with source or module inputs", diffFlagName)
+	}
+	var changedSinceRef buffetch.SourceRef
+	if flags.ChangedSince != "" {
+		changedSinceRef, err = getChangedSinceRef(ctx, container.Logger(), ref, flags.ChangedSince)
+		if err != nil {
+			return err
+		}
+	}
 	configProvider := bufconfig.NewProvider(container.Logger())
 	workspaceConfigProvider := bufwork.NewProvider(container.Logger())
 	moduleResolver, err := moduleResolverReaderProvider.GetModuleResolver(ctx, container)
@@ -182,14 +270,23 @@ func run(
 		return err
 	}
 	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
-	imageConfigs, fileAnnotations, err := bufcli.NewWireImageConfigReader(
+	moduleConfigReader := bufcli.NewWireModuleConfigReader(
+		container.Logger(),
+		storageosProvider,
+		configProvider,
+		workspaceConfigProvider,
+		moduleResolver,
+		moduleReader,
+	)
+	imageConfigReader := bufcli.NewWireImageConfigReader(
 		container.Logger(),
 		storageosProvider,
 		configProvider,
 		workspaceConfigProvider,
 		moduleResolver,
 		moduleReader,
-	).GetImageConfigs(
+	)
+	imageConfigs, fileAnnotations, err := imageConfigReader.GetImageConfigs(
 		ctx,
 		container,
 		ref,
@@ -211,31 +308,196 @@ func run(
 		}
 		return bufcli.ErrFileAnnotation
 	}
+	for _, imageConfig := range imageConfigs {
+		if imageConfig.Config().Lint.HasPlugins() && !bufcli.CheckPluginsAllowed(ref, inputConfig) {
+			return fmt.Errorf("lint plugins can only be used with local inputs or --%s", configFlagName)
+		}
+	}
 	var allFileAnnotations []bufanalysis.FileAnnotation
+	var allBaselineKeys []buflint.BaselineKey
+	// the paths of all checked files, only baseline entries for these files can be stale
+	checkedPaths := make(map[string]struct{})
+	// the configs with the rules of their plugins, for the rule metadata of the sarif format
+	var lintConfigs []*buflint.Config
 	for _, imageConfig := range imageConfigs {
+		image := bufimage.ImageWithoutImports(imageConfig.Image())
+		lintConfig, err := buflint.LoadPlugins(ctx, imageConfig.Config().Lint)
+		if err != nil {
+			return err
+		}
+		lintConfigs = append(lintConfigs, lintConfig)
 		fileAnnotations, err := buflint.NewHandler(container.Logger()).Check(
 			ctx,
-			imageConfig.Config().Lint,
-			bufimage.ImageWithoutImports(imageConfig.Image()),
+			lintConfig,
+			imageConfig.Image(),
 		)
 		if err != nil {
 			return err
 		}
 		allFileAnnotations = append(allFileAnnotations, fileAnnotations...)
+		if baseline != nil || flags.WriteBaseline != "" {
+			baselineKeys, err := buflint.GetBaselineKeys(ctx, image, fileAnnotations)
+			if err != nil {
+				return err
+			}
+			allBaselineKeys = append(allBaselineKeys, baselineKeys...)
+			for _, imageFile := range image.Files() {
+				checkedPaths[imageFile.Path()] = struct{}{}
+			}
+		}
+	}
+	if flags.WriteBaseline != "" {
+		return writeBaseline(flags.WriteBaseline, buflint.NewBaseline(allBaselineKeys))
 	}
-	if len(allFileAnnotations) > 0 {
-		allFileAnnotations, err = bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations)
+	var staleBaselineEntries []*buflint.BaselineEntry
+	if baseline != nil {
+		allFileAnnotations, staleBaselineEntries, err = baseline.Filter(allFileAnnotations, allBaselineKeys, checkedPaths)
 		if err != nil {
 			return err
 		}
-		if err := buflint.PrintFileAnnotations(
-			container.Stdout(),
+	}
+	if changedSinceRef != nil {
+		pathToChangedLineRanges, err := getPathToChangedLineRanges(
+			ctx,
+			container,
+			moduleConfigReader,
+			// this was validated to be a SourceOrModuleRef in getChangedSinceRef
+			ref.(buffetch.SourceOrModuleRef),
+			changedSinceRef,
+			inputConfig,
+		)
+		if err != nil {
+			return err
+		}
+		allFileAnnotations = filterFileAnnotationsForChangedLineRanges(allFileAnnotations, pathToChangedLineRanges)
+	}
+	allFileAnnotations, err = bufanalysis.DeduplicateAndSortFileAnnotations(allFileAnnotations)
+	if err != nil {
+		return err
+	}
+	// with --diff alone the files are not rewritten, so the check violations
+	// that can be fixed still result in a non-zero exit code
+	exitFileAnnotations := allFileAnnotations
+	if flags.Fix || flags.Diff {
+		// references to renamed descriptors must be updated in all files of
+		// the modules, not only in the files that were checked
+		fixImageConfigs := imageConfigs
+		if len(paths) > 0 {
+			fixImageConfigs, fileAnnotations, err = imageConfigReader.GetImageConfigs(
+				ctx,
+				container,
+				ref,
+				inputConfig,
+				nil,
+				false,
+				false,
+			)
+			if err != nil {
+				return err
+			}
+			if len(fileAnnotations) > 0 {
+				if err := bufanalysis.PrintFileAnnotations(container.Stdout(), fileAnnotations, flags.ErrorFormat); err != nil {
+					return err
+				}
+				return bufcli.ErrFileAnnotation
+			}
+		}
+		var rewritten bool
+		allFileAnnotations, rewritten, err = fixFileAnnotations(
+			ctx,
+			container,
+			moduleConfigReader,
+			// this was validated to be a SourceOrModuleRef above
+			ref.(buffetch.SourceOrModuleRef),
+			inputConfig,
+			fixImageConfigs,
 			allFileAnnotations,
-			flags.ErrorFormat,
-		); err != nil {
+			flags.Diff,
+			flags.Fix,
+		)
+		if err != nil {
 			return err
 		}
+		if rewritten {
+			// lint the rewritten files again so that the remaining check
+			// violations have the locations within the rewritten files
+			relintFlags := *flags
+			relintFlags.Fix = false
+			relintFlags.Diff = false
+			return run(ctx, container, &relintFlags, moduleResolverReaderProvider)
+		}
+	}
+	if err := printStaleBaselineEntries(container, flags.Baseline, staleBaselineEntries); err != nil {
+		return err
+	}
+	// this prints nothing if there are no FileAnnotations, except for the sarif
+	// and junit formats, for which an empty document is printed so that it can
+	// still be uploaded
+	if err := buflint.PrintFileAnnotations(
+		container.Stdout(),
+		allFileAnnotations,
+		flags.ErrorFormat,
+		lintConfigs...,
+	); err != nil {
+		return err
+	}
+	if flags.Fix {
+		exitFileAnnotations = allFileAnnotations
+	}
+	// only errors result in a non-zero exit code, warnings and infos are only printed
+	if bufanalysis.HasSeverityError(exitFileAnnotations) {
 		return bufcli.ErrFileAnnotation
 	}
 	return nil
 }
+
+func readBaseline(filePath string) (_ *buflint.Baseline, retErr error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	baseline, err := buflint.ReadBaseline(file)
+	if err != nil {
+		return nil, fmt.Errorf("--%s: %s: %v", baselineFlagName, filePath, err)
+	}
+	return baseline, nil
+}
+
+func writeBaseline(filePath string, baseline *buflint.Baseline) (retErr error) {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		retErr = multierr.Append(retErr, file.Close())
+	}()
+	return buflint.WriteBaseline(file, baseline)
+}
+
+func printStaleBaselineEntries(
+	container app.StderrContainer,
+	filePath string,
+	staleBaselineEntries []*buflint.BaselineEntry,
+) error {
+	if len(staleBaselineEntries) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(
+		container.Stderr(),
+		"%s has %d stale entries that no longer match a check violation, run with --%s to update it:\n",
+		filePath,
+		len(staleBaselineEntries),
+		writeBaselineFlagName,
+	); err != nil {
+		return err
+	}
+	for _, staleBaselineEntry := range staleBaselineEntries {
+		if _, err := fmt.Fprintf(container.Stderr(), "  %s\n", staleBaselineEntry.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
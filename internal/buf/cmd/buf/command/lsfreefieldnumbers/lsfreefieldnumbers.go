@@ -0,0 +1,243 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsfreefieldnumbers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
+	"github.com/bufbuild/buf/internal/buf/bufcli"
+	"github.com/bufbuild/buf/internal/buf/bufconfig"
+	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage/bufimageutil"
+	"github.com/bufbuild/buf/internal/buf/buffetch"
+	"github.com/bufbuild/buf/internal/buf/bufwork"
+	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
+	"github.com/bufbuild/buf/internal/pkg/app/appflag"
+	"github.com/bufbuild/buf/internal/pkg/protosource"
+	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
+	"github.com/bufbuild/buf/internal/pkg/stringutil"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	errorFormatFlagName = "error-format"
+	formatFlagName      = "format"
+	configFlagName      = "config"
+	pathsFlagName       = "path"
+)
+
+var allFormatStrings = []string{
+	"text",
+	"json",
+}
+
+// NewCommand returns a new Command.
+func NewCommand(
+	name string,
+	builder appflag.Builder,
+	moduleResolverReaderProvider bufcli.ModuleResolverReaderProvider,
+) *appcmd.Command {
+	flags := newFlags()
+	return &appcmd.Command{
+		Use:   name + " <input>",
+		Short: "List the free field numbers of all messages for the input.",
+		Long: bufcli.GetInputLong(`the source, module, or image to list from`) + `
+
+Nested messages are listed before their parent message. The fields of groups are
+considered to be part of their parent message. In text format, the output is
+equal to the output of protoc --print_free_field_numbers.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: builder.NewRunFunc(
+			func(ctx context.Context, container appflag.Container) error {
+				return run(ctx, container, flags, moduleResolverReaderProvider)
+			},
+			bufcli.NewErrorInterceptor(name),
+		),
+		BindFlags: flags.Bind,
+	}
+}
+
+type flags struct {
+	ErrorFormat string
+	Format      string
+	Config      string
+	Paths       []string
+
+	// special
+	InputHashtag string
+}
+
+func newFlags() *flags {
+	return &flags{}
+}
+
+func (f *flags) Bind(flagSet *pflag.FlagSet) {
+	bufcli.BindInputHashtag(flagSet, &f.InputHashtag)
+	bufcli.BindPaths(flagSet, &f.Paths, pathsFlagName)
+	flagSet.StringVar(
+		&f.ErrorFormat,
+		errorFormatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format for build errors, printed to stderr. Must be one of %s.",
+			stringutil.SliceToString(bufanalysis.AllFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Format,
+		formatFlagName,
+		"text",
+		fmt.Sprintf(
+			"The format to print the free field numbers as. Must be one of %s.",
+			stringutil.SliceToString(allFormatStrings),
+		),
+	)
+	flagSet.StringVar(
+		&f.Config,
+		configFlagName,
+		"",
+		`The config file or data to use.`,
+	)
+}
+
+func run(
+	ctx context.Context,
+	container appflag.Container,
+	flags *flags,
+	moduleResolverReaderProvider bufcli.ModuleResolverReaderProvider,
+) error {
+	asJSON, err := parseFormat(flags.Format)
+	if err != nil {
+		return err
+	}
+	input, err := bufcli.GetInputValue(container, flags.InputHashtag, "", "", ".")
+	if err != nil {
+		return err
+	}
+	ref, err := buffetch.NewRefParser(container.Logger()).GetRef(ctx, input)
+	if err != nil {
+		return err
+	}
+	configProvider := bufconfig.NewProvider(container.Logger())
+	workspaceConfigProvider := bufwork.NewProvider(container.Logger())
+	moduleResolver, err := moduleResolverReaderProvider.GetModuleResolver(ctx, container)
+	if err != nil {
+		return err
+	}
+	moduleReader, err := moduleResolverReaderProvider.GetModuleReader(ctx, container)
+	if err != nil {
+		return err
+	}
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	imageConfigs, fileAnnotations, err := bufcli.NewWireImageConfigReader(
+		container.Logger(),
+		storageosProvider,
+		configProvider,
+		workspaceConfigProvider,
+		moduleResolver,
+		moduleReader,
+	).GetImageConfigs(
+		ctx,
+		container,
+		ref,
+		flags.Config,
+		flags.Paths,
+		false, // input files must exist
+		true,  // we do not need source info for field numbers
+	)
+	if err != nil {
+		return err
+	}
+	if len(fileAnnotations) > 0 {
+		if err := bufanalysis.PrintFileAnnotations(container.Stderr(), fileAnnotations, flags.ErrorFormat); err != nil {
+			return err
+		}
+		return bufcli.ErrFileAnnotation
+	}
+	for _, imageConfig := range imageConfigs {
+		image := imageConfig.Image()
+		var filePaths []string
+		for _, imageFile := range image.Files() {
+			if !imageFile.IsImport() {
+				filePaths = append(filePaths, imageFile.Path())
+			}
+		}
+		sort.Strings(filePaths)
+		messages, err := bufimageutil.FreeMessageRangeMessages(ctx, filePaths, image)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := printMessage(container.Stdout(), message, asJSON); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func printMessage(writer io.Writer, message protosource.Message, asJSON bool) error {
+	if !asJSON {
+		_, err := fmt.Fprintln(writer, protosource.FreeMessageRangeString(message))
+		return err
+	}
+	freeRanges := protosource.FreeMessageRanges(message)
+	externalFreeMessage := externalFreeMessage{
+		Path:    message.File().ExternalPath(),
+		Message: message.FullName(),
+		// always print an array, even if empty
+		Free: make([]externalFreeMessageRange, len(freeRanges)),
+	}
+	for i, freeRange := range freeRanges {
+		externalFreeMessage.Free[i] = externalFreeMessageRange{
+			Start: freeRange.Start(),
+			End:   freeRange.End(),
+		}
+	}
+	data, err := json.Marshal(externalFreeMessage)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(data))
+	return err
+}
+
+func parseFormat(format string) (bool, error) {
+	switch s := strings.ToLower(strings.TrimSpace(format)); s {
+	case "", "text":
+		return false, nil
+	case "json":
+		return true, nil
+	default:
+		return false, appcmd.NewInvalidArgumentErrorf("--%s: unknown format: %q", formatFlagName, s)
+	}
+}
+
+type externalFreeMessage struct {
+	Path    string                     `json:"path,omitempty"`
+	Message string                     `json:"message,omitempty"`
+	Free    []externalFreeMessageRange `json:"free"`
+}
+
+type externalFreeMessageRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
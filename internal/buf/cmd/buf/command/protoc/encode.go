@@ -0,0 +1,376 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protoc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/buf/internal/pkg/protoencoding"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// rawRecursionLimit is the maximum depth of nested length-delimited fields
+// that are parsed as messages by --decode_raw, matching protoc.
+const rawRecursionLimit = 10
+
+var errFailedToParseInput = errors.New("failed to parse input")
+
+// encode reads a text-format message of the given type and returns it in binary.
+func encode(resolver protoencoding.Resolver, messageName string, data []byte) ([]byte, error) {
+	message, err := newMessage(resolver, messageName)
+	if err != nil {
+		return nil, err
+	}
+	if err := protoencoding.NewTextUnmarshaler(resolver).Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("%v: %w", errFailedToParseInput, err)
+	}
+	return protoencoding.NewWireMarshaler().Marshal(message)
+}
+
+// decode reads a binary message of the given type and returns it in the
+// text format printed by protoc --decode.
+//
+// We do not use prototext for printing as its output is deliberately unstable.
+func decode(resolver protoencoding.Resolver, messageName string, data []byte) ([]byte, error) {
+	message, err := newMessage(resolver, messageName)
+	if err != nil {
+		return nil, err
+	}
+	if err := protoencoding.NewWireUnmarshaler(resolver).Unmarshal(data, message); err != nil {
+		return nil, fmt.Errorf("%v: %w", errFailedToParseInput, err)
+	}
+	buffer := bytes.NewBuffer(nil)
+	if err := printMessage(buffer, message.ProtoReflect(), 0); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// decodeRaw reads an arbitrary binary message and returns its tag/value pairs
+// in the format printed by protoc --decode_raw.
+func decodeRaw(data []byte) ([]byte, error) {
+	rawFields, err := parseRawFields(data, rawRecursionLimit)
+	if err != nil {
+		return nil, errFailedToParseInput
+	}
+	buffer := bytes.NewBuffer(nil)
+	printRawFields(buffer, rawFields, 0)
+	return buffer.Bytes(), nil
+}
+
+func newMessage(resolver protoencoding.Resolver, messageName string) (proto.Message, error) {
+	if resolver == nil {
+		return nil, newTypeNotDefinedError(messageName)
+	}
+	messageType, err := resolver.FindMessageByName(protoreflect.FullName(messageName))
+	if err != nil {
+		return nil, newTypeNotDefinedError(messageName)
+	}
+	return messageType.New().Interface(), nil
+}
+
+func printMessage(buffer *bytes.Buffer, message protoreflect.Message, depth int) error {
+	// like protoc, known fields and extensions are printed in field number order
+	var fieldDescriptors []protoreflect.FieldDescriptor
+	message.Range(
+		func(fieldDescriptor protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			fieldDescriptors = append(fieldDescriptors, fieldDescriptor)
+			return true
+		},
+	)
+	sort.Slice(
+		fieldDescriptors,
+		func(i int, j int) bool {
+			return fieldDescriptors[i].Number() < fieldDescriptors[j].Number()
+		},
+	)
+	for _, fieldDescriptor := range fieldDescriptors {
+		name := getTextFieldName(fieldDescriptor)
+		value := message.Get(fieldDescriptor)
+		switch {
+		case fieldDescriptor.IsMap():
+			if err := printMap(buffer, name, fieldDescriptor, value.Map(), depth); err != nil {
+				return err
+			}
+		case fieldDescriptor.IsList():
+			list := value.List()
+			for i := 0; i < list.Len(); i++ {
+				if err := printField(buffer, name, fieldDescriptor, list.Get(i), depth); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := printField(buffer, name, fieldDescriptor, value, depth); err != nil {
+				return err
+			}
+		}
+	}
+	rawFields, err := parseRawFields(message.GetUnknown(), rawRecursionLimit)
+	if err != nil {
+		return err
+	}
+	printRawFields(buffer, rawFields, depth)
+	return nil
+}
+
+func printMap(
+	buffer *bytes.Buffer,
+	name string,
+	fieldDescriptor protoreflect.FieldDescriptor,
+	protoMap protoreflect.Map,
+	depth int,
+) error {
+	indent := strings.Repeat("  ", depth)
+	var mapKeys []protoreflect.MapKey
+	protoMap.Range(
+		func(mapKey protoreflect.MapKey, _ protoreflect.Value) bool {
+			mapKeys = append(mapKeys, mapKey)
+			return true
+		},
+	)
+	// like protoc, map entries are sorted by key
+	sort.Slice(
+		mapKeys,
+		func(i int, j int) bool {
+			return lessMapKey(mapKeys[i], mapKeys[j])
+		},
+	)
+	for _, mapKey := range mapKeys {
+		_, _ = fmt.Fprintf(buffer, "%s%s {\n", indent, name)
+		if err := printField(buffer, "key", fieldDescriptor.MapKey(), mapKey.Value(), depth+1); err != nil {
+			return err
+		}
+		if err := printField(buffer, "value", fieldDescriptor.MapValue(), protoMap.Get(mapKey), depth+1); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(buffer, "%s}\n", indent)
+	}
+	return nil
+}
+
+func printField(
+	buffer *bytes.Buffer,
+	name string,
+	fieldDescriptor protoreflect.FieldDescriptor,
+	value protoreflect.Value,
+	depth int,
+) error {
+	indent := strings.Repeat("  ", depth)
+	switch fieldDescriptor.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		_, _ = fmt.Fprintf(buffer, "%s%s {\n", indent, name)
+		if err := printMessage(buffer, value.Message(), depth+1); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(buffer, "%s}\n", indent)
+	default:
+		scalar, err := getTextScalar(fieldDescriptor, value)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(buffer, "%s%s: %s\n", indent, name, scalar)
+	}
+	return nil
+}
+
+func getTextFieldName(fieldDescriptor protoreflect.FieldDescriptor) string {
+	if fieldDescriptor.IsExtension() {
+		return "[" + string(fieldDescriptor.FullName()) + "]"
+	}
+	if fieldDescriptor.Kind() == protoreflect.GroupKind {
+		return string(fieldDescriptor.Message().Name())
+	}
+	return string(fieldDescriptor.Name())
+}
+
+func getTextScalar(fieldDescriptor protoreflect.FieldDescriptor, value protoreflect.Value) (string, error) {
+	switch kind := fieldDescriptor.Kind(); kind {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(value.Bool()), nil
+	case protoreflect.EnumKind:
+		if enumValueDescriptor := fieldDescriptor.Enum().Values().ByNumber(value.Enum()); enumValueDescriptor != nil {
+			return string(enumValueDescriptor.Name()), nil
+		}
+		return strconv.FormatInt(int64(value.Enum()), 10), nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case protoreflect.FloatKind:
+		return formatTextFloat(value.Float(), 32), nil
+	case protoreflect.DoubleKind:
+		return formatTextFloat(value.Float(), 64), nil
+	case protoreflect.StringKind:
+		return `"` + cEscape([]byte(value.String())) + `"`, nil
+	case protoreflect.BytesKind:
+		return `"` + cEscape(value.Bytes()) + `"`, nil
+	default:
+		return "", fmt.Errorf("unknown field kind: %v", kind)
+	}
+}
+
+func formatTextFloat(value float64, bitSize int) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "inf"
+	case math.IsInf(value, -1):
+		return "-inf"
+	case math.IsNaN(value):
+		return "nan"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, bitSize)
+	}
+}
+
+func lessMapKey(one protoreflect.MapKey, two protoreflect.MapKey) bool {
+	switch oneValue := one.Interface().(type) {
+	case bool:
+		return !oneValue && two.Bool()
+	case int32, int64:
+		return one.Int() < two.Int()
+	case uint32, uint64:
+		return one.Uint() < two.Uint()
+	default:
+		return one.String() < two.String()
+	}
+}
+
+// rawField is a field read from the wire format without a schema.
+type rawField struct {
+	number   protowire.Number
+	wireType protowire.Type
+	// set for varint, fixed32 and fixed64 fields
+	value uint64
+	// set for length-delimited fields
+	bytes []byte
+	// set for groups and length-delimited fields that are printed as messages
+	fields    []*rawField
+	isMessage bool
+}
+
+func parseRawFields(data []byte, recursionBudget int) ([]*rawField, error) {
+	var rawFields []*rawField
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		rawField := &rawField{
+			number:   number,
+			wireType: wireType,
+		}
+		switch wireType {
+		case protowire.VarintType:
+			rawField.value, n = protowire.ConsumeVarint(data)
+		case protowire.Fixed32Type:
+			var value uint32
+			value, n = protowire.ConsumeFixed32(data)
+			rawField.value = uint64(value)
+		case protowire.Fixed64Type:
+			rawField.value, n = protowire.ConsumeFixed64(data)
+		case protowire.BytesType:
+			rawField.bytes, n = protowire.ConsumeBytes(data)
+			// like protoc, any non-empty value that parses as a message is printed as a message
+			if n >= 0 && len(rawField.bytes) > 0 && recursionBudget > 0 {
+				if fields, err := parseRawFields(rawField.bytes, recursionBudget-1); err == nil {
+					rawField.fields = fields
+					rawField.isMessage = true
+				}
+			}
+		case protowire.StartGroupType:
+			var value []byte
+			value, n = protowire.ConsumeGroup(number, data)
+			if n >= 0 {
+				if recursionBudget <= 0 {
+					return nil, errors.New("exceeded maximum recursion depth")
+				}
+				fields, err := parseRawFields(value, recursionBudget-1)
+				if err != nil {
+					return nil, err
+				}
+				rawField.fields = fields
+				rawField.isMessage = true
+			}
+		default:
+			return nil, fmt.Errorf("unexpected wire type %d", wireType)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		data = data[n:]
+		rawFields = append(rawFields, rawField)
+	}
+	return rawFields, nil
+}
+
+func printRawFields(buffer *bytes.Buffer, rawFields []*rawField, depth int) {
+	indent := strings.Repeat("  ", depth)
+	for _, rawField := range rawFields {
+		switch {
+		case rawField.isMessage:
+			_, _ = fmt.Fprintf(buffer, "%s%d {\n", indent, rawField.number)
+			printRawFields(buffer, rawField.fields, depth+1)
+			_, _ = fmt.Fprintf(buffer, "%s}\n", indent)
+		case rawField.wireType == protowire.BytesType:
+			_, _ = fmt.Fprintf(buffer, "%s%d: \"%s\"\n", indent, rawField.number, cEscape(rawField.bytes))
+		case rawField.wireType == protowire.Fixed32Type:
+			_, _ = fmt.Fprintf(buffer, "%s%d: 0x%08x\n", indent, rawField.number, rawField.value)
+		case rawField.wireType == protowire.Fixed64Type:
+			_, _ = fmt.Fprintf(buffer, "%s%d: 0x%016x\n", indent, rawField.number, rawField.value)
+		default:
+			_, _ = fmt.Fprintf(buffer, "%s%d: %d\n", indent, rawField.number, rawField.value)
+		}
+	}
+}
+
+// cEscape escapes the bytes the same way as the C++ CEscape function that
+// protoc uses to print strings.
+func cEscape(value []byte) string {
+	var builder strings.Builder
+	for _, b := range value {
+		switch b {
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '"':
+			builder.WriteString(`\"`)
+		case '\'':
+			builder.WriteString(`\'`)
+		case '\\':
+			builder.WriteString(`\\`)
+		default:
+			if b < 0x20 || b >= 0x7f {
+				_, _ = fmt.Fprintf(&builder, `\%03o`, b)
+			} else {
+				builder.WriteByte(b)
+			}
+		}
+	}
+	return builder.String()
+}
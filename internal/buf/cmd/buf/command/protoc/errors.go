@@ -64,44 +64,6 @@ func newDuplicatePluginPathError(pluginName string) error {
 	return fmt.Errorf("duplicate --%s for protoc-gen-%s", pluginPathValuesFlagName, pluginName)
 }
 
-func newEncodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		encodeFlagName,
-	)
-}
-
-func newDecodeNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeFlagName,
-	)
-}
-
-func newDecodeRawNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf only handles the binary and JSON formats for now, however we can support this flag if there is sufficient demand.
-Please email us at support@buf.build if this is a need for your organization.`,
-		decodeRawFlagName,
-	)
-}
-
-func newDescriptorSetInNotSupportedError() error {
-	return fmt.Errorf(
-		`--%s is not supported by buf.
-
-Buf will work with cross-repository imports Buf Schema Registry, which will be based on source files, not pre-compiled images.
-We think this is a much safer option that leads to less errors and more consistent results.
-
-Please email us at support@buf.build if this is a need for your organization.`,
-		descriptorSetInFlagName,
-	)
+func newTypeNotDefinedError(messageName string) error {
+	return fmt.Errorf("type not defined: %s", messageName)
 }
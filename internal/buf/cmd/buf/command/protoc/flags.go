@@ -58,6 +58,10 @@ type flags struct {
 	Output                string
 	ErrorFormat           string
 	ByDir                 bool
+	Encode                string
+	Decode                string
+	DecodeRaw             bool
+	DescriptorSetIn       []string
 }
 
 type env struct {
@@ -73,11 +77,6 @@ type flagsBuilder struct {
 
 	PluginPathValues []string
 
-	Encode          string
-	Decode          string
-	DecodeRaw       bool
-	DescriptorSetIn []string
-
 	pluginFake        []string
 	pluginNameToValue map[string]*pluginValue
 }
@@ -166,30 +165,34 @@ func (f *flagsBuilder) Bind(flagSet *pflag.FlagSet) {
 		&f.Encode,
 		encodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a text-format message of the given type from stdin and write it in binary to stdout.
+The message type must be defined in the input files or their imports.`,
 	)
-	_ = flagSet.MarkHidden(encodeFlagName)
 	flagSet.StringVar(
 		&f.Decode,
 		decodeFlagName,
 		"",
-		`Not supported by buf.`,
+		`Read a binary message of the given type from stdin and write it in text format to stdout.
+The message type must be defined in the input files or their imports.`,
 	)
-	_ = flagSet.MarkHidden(decodeFlagName)
 	flagSet.BoolVar(
 		&f.DecodeRaw,
 		decodeRawFlagName,
 		false,
-		`Not supported by buf.`,
+		`Read an arbitrary binary message from stdin and write the raw tag/value pairs in text format to stdout.
+No input files should be given when using this flag.`,
 	)
-	_ = flagSet.MarkHidden(decodeRawFlagName)
 	flagSet.StringSliceVar(
 		&f.DescriptorSetIn,
 		descriptorSetInFlagName,
 		nil,
-		`Not supported by buf.`,
+		fmt.Sprintf(
+			`The FileDescriptorSets to use for imports that are not found in the include directory paths.
+Multiple values may be delimited by %q. Each must be one of format %s.`,
+			string(filepath.ListSeparator),
+			buffetch.ImageFormatsString,
+		),
 	)
-	_ = flagSet.MarkHidden(descriptorSetInFlagName)
 }
 
 func (f *flagsBuilder) Normalize(flagSet *pflag.FlagSet, name string) string {
@@ -211,9 +214,6 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := f.checkUnsupported(); err != nil {
-		return nil, err
-	}
 	for pluginName, pluginInfo := range pluginNameToPluginInfo {
 		if pluginInfo.Out == "" && len(pluginInfo.Opt) > 0 {
 			return nil, newCannotSpecifyOptWithoutOutError(pluginName)
@@ -232,7 +232,8 @@ func (f *flagsBuilder) Build(args []string) (*env, error) {
 	if f.ErrorFormat == "" {
 		f.ErrorFormat = defaultErrorFormat
 	}
-	if len(filePaths) == 0 {
+	// --decode_raw is the only mode that does not take input files
+	if len(filePaths) == 0 && !f.DecodeRaw {
 		return nil, errNoInputFiles
 	}
 	return &env{
@@ -473,22 +474,6 @@ func (f *flagsBuilder) getPluginNamesSortedByOutIndex(
 	return pluginNames, nil
 }
 
-func (f *flagsBuilder) checkUnsupported() error {
-	if f.Encode != "" {
-		return newEncodeNotSupportedError()
-	}
-	if f.Decode != "" {
-		return newDecodeNotSupportedError()
-	}
-	if f.DecodeRaw {
-		return newDecodeRawNotSupportedError()
-	}
-	if len(f.DescriptorSetIn) > 0 {
-		return newDescriptorSetInNotSupportedError()
-	}
-	return nil
-}
-
 type pluginValue struct {
 	OutIndexes []int
 	OptIndexes []int
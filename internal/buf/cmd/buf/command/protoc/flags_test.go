@@ -461,6 +461,56 @@ func TestParseFlags(t *testing.T) {
 				},
 			},
 		},
+		{
+			Args: []string{
+				"--decode_raw",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					DecodeRaw:       true,
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--encode",
+				"foo.Bar",
+				"foo.proto",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					Encode:          "foo.Bar",
+				},
+				FilePaths: []string{
+					"foo.proto",
+				},
+			},
+		},
+		{
+			Args: []string{
+				"--descriptor_set_in",
+				"foo.bin",
+				"--descriptor_set_in=bar.bin",
+				"foo.proto",
+			},
+			Expected: &env{
+				flags: flags{
+					IncludeDirPaths: defaultIncludeDirPaths,
+					ErrorFormat:     defaultErrorFormat,
+					DescriptorSetIn: []string{
+						"foo.bin",
+						"bar.bin",
+					},
+				},
+				FilePaths: []string{
+					"foo.proto",
+				},
+			},
+		},
 	}
 	for i, testCase := range testCases {
 		name := fmt.Sprintf("%d", i)
@@ -17,6 +17,9 @@ package protoc
 import (
 	"context"
 	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/bufbuild/buf/internal/buf/bufanalysis"
@@ -29,6 +32,8 @@ import (
 	"github.com/bufbuild/buf/internal/pkg/app"
 	"github.com/bufbuild/buf/internal/pkg/app/appcmd"
 	"github.com/bufbuild/buf/internal/pkg/app/appflag"
+	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"github.com/bufbuild/buf/internal/pkg/protoencoding"
 	"github.com/bufbuild/buf/internal/pkg/storage/storageos"
 	"go.opencensus.io/trace"
 	"go.uber.org/zap"
@@ -86,6 +91,9 @@ func run(
 	if len(env.PluginNameToPluginInfo) > 0 && env.Output != "" {
 		return fmt.Errorf("cannot call --%s and plugins at the same time", outputFlagName)
 	}
+	if err := checkCodecFlags(env); err != nil {
+		return err
+	}
 
 	if checkedEntry := container.Logger().Check(zapcore.DebugLevel, "env"); checkedEntry != nil {
 		checkedEntry.Write(
@@ -94,65 +102,111 @@ func run(
 		)
 	}
 
-	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
-	module, err := bufmodulebuild.NewModuleIncludeBuilder(container.Logger(), storageosProvider).BuildForIncludes(
-		ctx,
-		env.IncludeDirPaths,
-		bufmodulebuild.WithPaths(env.FilePaths),
-	)
-	if err != nil {
+	if env.DecodeRaw {
+		data, err := io.ReadAll(container.Stdin())
+		if err != nil {
+			return err
+		}
+		decodedData, err := decodeRaw(data)
+		if err != nil {
+			return err
+		}
+		_, err = container.Stdout().Write(decodedData)
 		return err
 	}
-	moduleReader, err := moduleResolverReaderProvider.GetModuleReader(ctx, container)
-	if err != nil {
-		return err
+
+	storageosProvider := storageos.NewProvider(storageos.ProviderWithSymlinks())
+	// we always need source code info if we are doing generation
+	excludeSourceCodeInfo := len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo
+	var descriptorSetInImage bufimage.Image
+	if len(env.DescriptorSetIn) > 0 {
+		var err error
+		descriptorSetInImage, err = getDescriptorSetInImage(
+			ctx,
+			container,
+			storageosProvider,
+			env.DescriptorSetIn,
+			excludeSourceCodeInfo,
+		)
+		if err != nil {
+			return err
+		}
 	}
-	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
-		zap.NewNop(),
-		moduleReader,
-	).Build(
-		ctx,
-		module,
-	)
-	if err != nil {
-		return err
+	// like protoc, input files can also be given as names of files within the
+	// FileDescriptorSets, in which case they are not compiled
+	var sourceFilePaths []string
+	var descriptorSetInFilePaths []string
+	for _, filePath := range env.FilePaths {
+		if descriptorSetInImage != nil && descriptorSetInImage.GetFile(filePath) != nil {
+			descriptorSetInFilePaths = append(descriptorSetInFilePaths, filePath)
+		} else {
+			sourceFilePaths = append(sourceFilePaths, filePath)
+		}
 	}
-	var buildOptions []bufimagebuild.BuildOption
-	// we always need source code info if we are doing generation
-	if len(env.PluginNameToPluginInfo) == 0 && !env.IncludeSourceInfo {
-		buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
+	var images []bufimage.Image
+	if len(sourceFilePaths) > 0 {
+		image, fileAnnotations, err := buildSourceImage(
+			ctx,
+			container,
+			storageosProvider,
+			moduleResolverReaderProvider,
+			env,
+			sourceFilePaths,
+			excludeSourceCodeInfo,
+			descriptorSetInImage,
+		)
+		if err != nil {
+			return err
+		}
+		if len(fileAnnotations) > 0 {
+			if err := bufanalysis.PrintFileAnnotations(
+				container.Stderr(),
+				fileAnnotations,
+				env.ErrorFormat,
+			); err != nil {
+				return err
+			}
+			// we do this even though we're in protoc compatibility mode as we just need to do non-zero
+			// but this also makes us consistent with the rest of buf
+			return bufcli.ErrFileAnnotation
+		}
+		images = append(images, image)
 	}
-	image, fileAnnotations, err := bufimagebuild.NewBuilder(container.Logger()).Build(
-		ctx,
-		moduleFileSet,
-		buildOptions...,
-	)
+	if len(descriptorSetInFilePaths) > 0 {
+		image, err := bufimage.ImageWithOnlyPaths(descriptorSetInImage, descriptorSetInFilePaths)
+		if err != nil {
+			return err
+		}
+		images = append(images, image)
+	}
+	image, err := bufimage.MergeImages(images...)
 	if err != nil {
 		return err
 	}
-	if len(fileAnnotations) > 0 {
-		if err := bufanalysis.PrintFileAnnotations(
-			container.Stderr(),
-			fileAnnotations,
-			env.ErrorFormat,
-		); err != nil {
+
+	if env.Encode != "" || env.Decode != "" {
+		resolver, err := protoencoding.NewResolver(bufimage.ImageToFileDescriptorProtos(image)...)
+		if err != nil {
 			return err
 		}
-		// we do this even though we're in protoc compatibility mode as we just need to do non-zero
-		// but this also makes us consistent with the rest of buf
-		return bufcli.ErrFileAnnotation
-	}
-
-	if env.PrintFreeFieldNumbers {
-		fileInfos, err := module.TargetFileInfos(ctx)
+		data, err := io.ReadAll(container.Stdin())
 		if err != nil {
 			return err
 		}
-		var filePaths []string
-		for _, fileInfo := range fileInfos {
-			filePaths = append(filePaths, fileInfo.Path())
+		var codedData []byte
+		if env.Encode != "" {
+			codedData, err = encode(resolver, env.Encode, data)
+		} else {
+			codedData, err = decode(resolver, env.Decode, data)
+		}
+		if err != nil {
+			return err
 		}
-		s, err := bufimageutil.FreeMessageRangeStrings(ctx, filePaths, image)
+		_, err = container.Stdout().Write(codedData)
+		return err
+	}
+	if env.PrintFreeFieldNumbers {
+		s, err := bufimageutil.FreeMessageRangeStrings(ctx, getTargetFilePathsInOrder(image, env.FilePaths), image)
 		if err != nil {
 			return err
 		}
@@ -206,3 +260,163 @@ func run(
 		!env.IncludeImports,
 	)
 }
+
+func buildSourceImage(
+	ctx context.Context,
+	container appflag.Container,
+	storageosProvider storageos.Provider,
+	moduleResolverReaderProvider bufcli.ModuleResolverReaderProvider,
+	env *env,
+	filePaths []string,
+	excludeSourceCodeInfo bool,
+	descriptorSetInImage bufimage.Image,
+) (bufimage.Image, []bufanalysis.FileAnnotation, error) {
+	module, err := bufmodulebuild.NewModuleIncludeBuilder(container.Logger(), storageosProvider).BuildForIncludes(
+		ctx,
+		env.IncludeDirPaths,
+		bufmodulebuild.WithPaths(filePaths),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	moduleReader, err := moduleResolverReaderProvider.GetModuleReader(ctx, container)
+	if err != nil {
+		return nil, nil, err
+	}
+	moduleFileSet, err := bufmodulebuild.NewModuleFileSetBuilder(
+		zap.NewNop(),
+		moduleReader,
+	).Build(
+		ctx,
+		module,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	var buildOptions []bufimagebuild.BuildOption
+	if excludeSourceCodeInfo {
+		buildOptions = append(buildOptions, bufimagebuild.WithExcludeSourceCodeInfo())
+	}
+	if descriptorSetInImage != nil {
+		buildOptions = append(buildOptions, bufimagebuild.WithImportImage(descriptorSetInImage))
+	}
+	return bufimagebuild.NewBuilder(container.Logger()).Build(
+		ctx,
+		moduleFileSet,
+		buildOptions...,
+	)
+}
+
+// getDescriptorSetInImage reads all FileDescriptorSets given with --descriptor_set_in
+// and merges them into a single Image.
+//
+// All files are marked as imports, so that a file can be contained in multiple
+// FileDescriptorSets as long as its content is equal.
+func getDescriptorSetInImage(
+	ctx context.Context,
+	container appflag.Container,
+	storageosProvider storageos.Provider,
+	descriptorSetIns []string,
+	excludeSourceCodeInfo bool,
+) (bufimage.Image, error) {
+	imageRefParser := buffetch.NewImageRefParser(container.Logger())
+	imageReader := bufcli.NewWireImageReader(container.Logger(), storageosProvider)
+	var image bufimage.Image
+	for _, descriptorSetIn := range descriptorSetIns {
+		// protoc delimits multiple files with the OS-specific path list separator
+		for _, descriptorSetInPath := range filepath.SplitList(descriptorSetIn) {
+			imageRef, err := imageRefParser.GetImageRef(ctx, descriptorSetInPath)
+			if err != nil {
+				return nil, fmt.Errorf("--%s: %v", descriptorSetInFlagName, err)
+			}
+			currentImage, err := imageReader.GetImage(
+				ctx,
+				container,
+				imageRef,
+				nil,
+				false,
+				excludeSourceCodeInfo,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("--%s: %s: %v", descriptorSetInFlagName, descriptorSetInPath, err)
+			}
+			currentImage = bufimage.ImageAsImports(currentImage)
+			if image == nil {
+				image = currentImage
+				continue
+			}
+			image, err = bufimage.MergeImagesWithEqualDuplicates(image, currentImage)
+			if err != nil {
+				return nil, fmt.Errorf("--%s: %s: %v", descriptorSetInFlagName, descriptorSetInPath, err)
+			}
+		}
+	}
+	return image, nil
+}
+
+// getTargetFilePathsInOrder returns the paths of the non-import files of the
+// image in the order the input files were given, as protoc does.
+//
+// Image files are in DAG order, so we cannot use the order of the image files.
+func getTargetFilePathsInOrder(image bufimage.Image, inputFilePaths []string) []string {
+	inputFilePathToIndex := make(map[string]int, len(inputFilePaths))
+	for i, inputFilePath := range inputFilePaths {
+		inputFilePathToIndex[normalpath.Normalize(inputFilePath)] = i
+	}
+	getIndex := func(imageFile bufimage.ImageFile) int {
+		// source files are given by their external path, and files within
+		// the FileDescriptorSets by their path
+		if index, ok := inputFilePathToIndex[normalpath.Normalize(imageFile.ExternalPath())]; ok {
+			return index
+		}
+		if index, ok := inputFilePathToIndex[imageFile.Path()]; ok {
+			return index
+		}
+		return len(inputFilePaths)
+	}
+	var targetImageFiles []bufimage.ImageFile
+	for _, imageFile := range image.Files() {
+		if !imageFile.IsImport() {
+			targetImageFiles = append(targetImageFiles, imageFile)
+		}
+	}
+	sort.SliceStable(
+		targetImageFiles,
+		func(i int, j int) bool {
+			return getIndex(targetImageFiles[i]) < getIndex(targetImageFiles[j])
+		},
+	)
+	filePaths := make([]string, len(targetImageFiles))
+	for i, imageFile := range targetImageFiles {
+		filePaths[i] = imageFile.Path()
+	}
+	return filePaths
+}
+
+// checkCodecFlags checks that --encode, --decode and --decode_raw are used on their own.
+func checkCodecFlags(env *env) error {
+	var numCodecFlags int
+	for _, isSet := range []bool{env.Encode != "", env.Decode != "", env.DecodeRaw} {
+		if isSet {
+			numCodecFlags++
+		}
+	}
+	if numCodecFlags == 0 {
+		return nil
+	}
+	if numCodecFlags > 1 {
+		return fmt.Errorf("only one of --%s, --%s and --%s can be specified", encodeFlagName, decodeFlagName, decodeRawFlagName)
+	}
+	if len(env.PluginNameToPluginInfo) > 0 || env.Output != "" || env.PrintFreeFieldNumbers {
+		return fmt.Errorf(
+			"cannot use --%s, --%s or --%s and generate code or descriptors at the same time",
+			encodeFlagName,
+			decodeFlagName,
+			decodeRawFlagName,
+		)
+	}
+	if env.DecodeRaw && len(env.FilePaths) > 0 {
+		return fmt.Errorf("no input files should be given when using --%s", decodeRawFlagName)
+	}
+	return nil
+}
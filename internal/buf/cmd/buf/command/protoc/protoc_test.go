@@ -18,8 +18,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/bufbuild/buf/internal/buf/bufcli"
@@ -79,6 +81,231 @@ func TestOverlap(t *testing.T) {
 	)
 }
 
+func TestEncodeDecode(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "codec")
+	filePath := filepath.Join(dirPath, "codec.proto")
+	encoded := testRunStdout(
+		t,
+		strings.NewReader(`name: "foo" bar { id: 5 } values: [1, 2] fixed: 7`),
+		"-I",
+		dirPath,
+		"--encode",
+		"codec.Foo",
+		filePath,
+	)
+	decodedRaw := testRunStdout(
+		t,
+		bytes.NewReader(encoded),
+		"--decode_raw",
+	)
+	assert.Equal(
+		t,
+		`1: "foo"
+2 {
+  1: 5
+}
+3: "\001\002"
+4: 0x00000007
+`,
+		string(decodedRaw),
+	)
+	decoded := testRunStdout(
+		t,
+		bytes.NewReader(encoded),
+		"-I",
+		dirPath,
+		"--decode",
+		"codec.Foo",
+		filePath,
+	)
+	assert.Equal(
+		t,
+		`name: "foo"
+bar {
+  id: 5
+}
+values: 1
+values: 2
+fixed: 7
+`,
+		string(decoded),
+	)
+}
+
+func TestEncodeTypeNotDefined(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "codec")
+	appcmdtesting.RunCommandExitCodeStderr(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+				bufcli.NopModuleResolverReaderProvider{},
+			)
+		},
+		1,
+		"type not defined: codec.Baz",
+		nil,
+		strings.NewReader(""),
+		"-I",
+		dirPath,
+		"--encode",
+		"codec.Baz",
+		filepath.Join(dirPath, "codec.proto"),
+	)
+}
+
+func TestPrintFreeFieldNumbers(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "freefieldnumbers")
+	stdout := testRunStdout(
+		t,
+		nil,
+		"-I",
+		dirPath,
+		"--print_free_field_numbers",
+		filepath.Join(dirPath, "ff.proto"),
+	)
+	assert.Equal(
+		t,
+		`ff.Foo.MEntry                       free: 3-INF
+ff.Foo.G.InG                        free: 1 3-INF
+ff.Foo.Nested                       free: 2-99
+ff.Foo                              free: 11 14-19 21-536870910
+ff.Empty                            free: 1-INF
+`,
+		string(stdout),
+	)
+}
+
+func TestPrintFreeFieldNumbersInputOrder(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "freefieldnumbers_order")
+	// b.proto imports a.proto, but the output is in the order of the input files
+	stdout := testRunStdout(
+		t,
+		nil,
+		"-I",
+		dirPath,
+		"--print_free_field_numbers",
+		filepath.Join(dirPath, "b.proto"),
+		filepath.Join(dirPath, "a.proto"),
+	)
+	assert.Equal(
+		t,
+		`order.B                             free: 1 3-INF
+order.A                             free: 2-INF
+`,
+		string(stdout),
+	)
+}
+
+func TestDescriptorSetIn(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "descriptorsetin")
+	tempDirPath := t.TempDir()
+	aFilePath := filepath.Join(tempDirPath, "a.bin")
+	bFilePath := filepath.Join(tempDirPath, "b.bin")
+	testRunStdout(
+		t,
+		nil,
+		"-I",
+		filepath.Join(dirPath, "a"),
+		"-o",
+		aFilePath,
+		filepath.Join(dirPath, "a", "a.proto"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		"-I",
+		filepath.Join(dirPath, "b"),
+		"--descriptor_set_in",
+		aFilePath,
+		"--include_imports",
+		"-o",
+		bFilePath,
+		filepath.Join(dirPath, "b", "b.proto"),
+	)
+	data, err := os.ReadFile(bFilePath)
+	require.NoError(t, err)
+	fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+	require.NoError(t, protoencoding.NewWireUnmarshaler(nil).Unmarshal(data, fileDescriptorSet))
+	require.Len(t, fileDescriptorSet.File, 2)
+	assert.Equal(t, "a.proto", fileDescriptorSet.File[0].GetName())
+	assert.Equal(t, "b.proto", fileDescriptorSet.File[1].GetName())
+	// files within the FileDescriptorSets can be given as input files
+	decoded := testRunStdout(
+		t,
+		bytes.NewReader([]byte{0x0a, 0x03, 0x0a, 0x01, 'x'}),
+		"--descriptor_set_in",
+		bFilePath,
+		"--decode",
+		"b.B",
+		"b.proto",
+	)
+	assert.Equal(
+		t,
+		`a {
+  name: "x"
+}
+`,
+		string(decoded),
+	)
+}
+
+func TestDescriptorSetInMismatchedDuplicate(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.Join("testdata", "descriptorsetin")
+	tempDirPath := t.TempDir()
+	aFilePath := filepath.Join(tempDirPath, "a.bin")
+	a2FilePath := filepath.Join(tempDirPath, "a2.bin")
+	testRunStdout(
+		t,
+		nil,
+		"-I",
+		filepath.Join(dirPath, "a"),
+		"-o",
+		aFilePath,
+		filepath.Join(dirPath, "a", "a.proto"),
+	)
+	testRunStdout(
+		t,
+		nil,
+		"-I",
+		filepath.Join(dirPath, "a2"),
+		"-o",
+		a2FilePath,
+		filepath.Join(dirPath, "a2", "a.proto"),
+	)
+	appcmdtesting.RunCommandExitCodeStderr(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+				bufcli.NopModuleResolverReaderProvider{},
+			)
+		},
+		1,
+		fmt.Sprintf(
+			"--descriptor_set_in: %s: a.proto is contained in multiple images with different content",
+			a2FilePath,
+		),
+		nil,
+		nil,
+		"-I",
+		filepath.Join(dirPath, "b"),
+		"--descriptor_set_in",
+		aFilePath+string(filepath.ListSeparator)+a2FilePath,
+		"-o",
+		filepath.Join(tempDirPath, "b.bin"),
+		filepath.Join(dirPath, "b", "b.proto"),
+	)
+}
+
 func TestComparePrintFreeFieldNumbersGoogleapis(t *testing.T) {
 	t.Parallel()
 	googleapisDirPath := buftesting.GetGoogleapisDirPath(t, buftestingDirPath)
@@ -429,3 +656,22 @@ func testGetBufProtocFileDescriptorSetBytes(t *testing.T, dirPath string) []byte
 	)
 	return stdout.Bytes()
 }
+
+func testRunStdout(t *testing.T, stdin io.Reader, args ...string) []byte {
+	stdout := bytes.NewBuffer(nil)
+	appcmdtesting.RunCommandSuccess(
+		t,
+		func(name string) *appcmd.Command {
+			return NewCommand(
+				name,
+				appflag.NewBuilder(name),
+				bufcli.NopModuleResolverReaderProvider{},
+			)
+		},
+		nil,
+		stdin,
+		stdout,
+		args...,
+	)
+	return stdout.Bytes()
+}
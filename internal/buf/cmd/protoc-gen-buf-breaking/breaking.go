@@ -176,7 +176,12 @@ func handle(
 	}
 	if len(fileAnnotations) > 0 {
 		buffer := bytes.NewBuffer(nil)
-		if err := bufanalysis.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
+		if err := bufbreaking.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
+			return err
+		}
+		// warnings and infos do not fail the plugin, so they are printed to stderr instead
+		if !bufanalysis.HasSeverityError(fileAnnotations) {
+			_, err := container.Stderr().Write(buffer.Bytes())
 			return err
 		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
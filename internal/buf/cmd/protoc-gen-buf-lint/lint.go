@@ -21,6 +21,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bufbuild/buf/internal/buf/bufanalysis"
 	"github.com/bufbuild/buf/internal/buf/bufcheck/buflint"
 	"github.com/bufbuild/buf/internal/buf/bufconfig"
 	"github.com/bufbuild/buf/internal/buf/bufcore/bufimage"
@@ -132,7 +133,6 @@ func handle(
 	if err != nil {
 		return err
 	}
-	image = bufimage.ImageWithoutImports(image)
 	fileAnnotations, err := buflint.NewHandler(logger).Check(
 		ctx,
 		config.Lint,
@@ -146,6 +146,11 @@ func handle(
 		if err := buflint.PrintFileAnnotations(buffer, fileAnnotations, externalConfig.ErrorFormat); err != nil {
 			return err
 		}
+		// warnings and infos do not fail the plugin, so they are printed to stderr instead
+		if !bufanalysis.HasSeverityError(fileAnnotations) {
+			_, err := container.Stderr().Write(buffer.Bytes())
+			return err
+		}
 		responseWriter.AddError(strings.TrimSpace(buffer.String()))
 	}
 	return nil
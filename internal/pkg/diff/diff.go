@@ -32,9 +32,16 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 )
 
+// unifiedHunkHeaderRegexp matches the header of a hunk in unified format.
+//
+// The counts are omitted if they are 1.
+var unifiedHunkHeaderRegexp = regexp.MustCompile(`(?m)^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
 // Diff does a diff.
 //
 // Returns nil if no diff.
@@ -61,6 +68,67 @@ func Diff(
 	)
 }
 
+// LineRange is a range of lines.
+//
+// Lines are 1-indexed, and both Start and End are inclusive.
+type LineRange struct {
+	Start int
+	End   int
+}
+
+// ChangedLineRanges returns the ranges of lines in b2 that were added or modified
+// when compared to b1.
+//
+// Lines that were only deleted from b1 are represented by a LineRange for
+// the line of b2 directly following the deletion, or the last line of b2 if
+// the deletion was at the end.
+//
+// Returns nil if no diff.
+func ChangedLineRanges(
+	ctx context.Context,
+	b1 []byte,
+	b2 []byte,
+) ([]LineRange, error) {
+	if bytes.Equal(b1, b2) {
+		return nil, nil
+	}
+	// no context lines so that the hunks only contain changed lines
+	data, err := runDiff(ctx, b1, b2, "-U0")
+	if err != nil {
+		return nil, err
+	}
+	var lineRanges []LineRange
+	for _, match := range unifiedHunkHeaderRegexp.FindAllSubmatch(data, -1) {
+		start, err := strconv.Atoi(string(match[1]))
+		if err != nil {
+			return nil, err
+		}
+		count := 1
+		if len(match[2]) > 0 {
+			count, err = strconv.Atoi(string(match[2]))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if count == 0 {
+			// for deletions, start is the line before the deletion
+			start++
+			if lastLine := bytes.Count(b2, []byte{'\n'}); start > lastLine && lastLine > 0 {
+				start = lastLine
+			}
+			count = 1
+		}
+		lineRanges = append(
+			lineRanges,
+			LineRange{
+				Start: start,
+				End:   start + count - 1,
+			},
+		)
+	}
+	return lineRanges, nil
+}
+
 // DiffOption is an option for Diff.
 type DiffOption func(*diffOptions)
 
@@ -90,7 +158,20 @@ func doDiff(
 	if bytes.Equal(b1, b2) {
 		return nil, nil
 	}
+	data, err := runDiff(ctx, b1, b2, "-u")
+	if err != nil {
+		return nil, err
+	}
+	return tryModifyHeader(data, filename1, filename2, suppressCommands, suppressTimestamps), nil
+}
 
+// runDiff runs diff on the data with the given format argument.
+func runDiff(
+	ctx context.Context,
+	b1 []byte,
+	b2 []byte,
+	formatArg string,
+) ([]byte, error) {
 	f1, err := writeTempFile("", "", b1)
 	if err != nil {
 		return nil, err
@@ -113,7 +194,7 @@ func doDiff(
 	}
 
 	buffer := bytes.NewBuffer(nil)
-	cmd := exec.CommandContext(ctx, binaryPath, "-u", f1, f2)
+	cmd := exec.CommandContext(ctx, binaryPath, formatArg, f1, f2)
 	cmd.Stdout = buffer
 	cmd.Stderr = buffer
 	err = cmd.Run()
@@ -121,7 +202,7 @@ func doDiff(
 	if len(data) > 0 {
 		// diff exits with a non-zero status when the files don't match.
 		// Ignore that failure as long as we get output.
-		return tryModifyHeader(data, filename1, filename2, suppressCommands, suppressTimestamps), nil
+		return data, nil
 	}
 	return nil, err
 }
@@ -92,3 +92,10 @@ func NewWireUnmarshaler(resolver Resolver) Unmarshaler {
 func NewJSONUnmarshaler(resolver Resolver) Unmarshaler {
 	return newJSONUnmarshaler(resolver)
 }
+
+// NewTextUnmarshaler returns a new Unmarshaler for the Protobuf text format.
+//
+// resolver can be nil if unknown and are only needed for extensions.
+func NewTextUnmarshaler(resolver Resolver) Unmarshaler {
+	return newTextUnmarshaler(resolver)
+}
@@ -16,26 +16,43 @@ package protosource
 
 type enum struct {
 	namedDescriptor
+	optionExtensionDescriptor
 
 	values             []EnumValue
 	allowAlias         bool
 	allowAliasPath     []int32
 	reservedEnumRanges []EnumRange
 	reservedNames      []ReservedName
+	deprecated         bool
+	deprecatedPath     []int32
 }
 
 func newEnum(
 	namedDescriptor namedDescriptor,
+	optionExtensionDescriptor optionExtensionDescriptor,
 	allowAlias bool,
 	allowAliasPath []int32,
+	deprecated bool,
+	deprecatedPath []int32,
 ) *enum {
 	return &enum{
-		namedDescriptor: namedDescriptor,
-		allowAlias:      allowAlias,
-		allowAliasPath:  allowAliasPath,
+		namedDescriptor:           namedDescriptor,
+		optionExtensionDescriptor: optionExtensionDescriptor,
+		allowAlias:                allowAlias,
+		allowAliasPath:            allowAliasPath,
+		deprecated:                deprecated,
+		deprecatedPath:            deprecatedPath,
 	}
 }
 
+func (e *enum) Deprecated() bool {
+	return e.deprecated
+}
+
+func (e *enum) DeprecatedLocation() Location {
+	return e.getLocation(e.deprecatedPath)
+}
+
 func (e *enum) Values() []EnumValue {
 	return e.values
 }
@@ -16,26 +16,43 @@ package protosource
 
 type enumValue struct {
 	namedDescriptor
+	optionExtensionDescriptor
 
-	enum       Enum
-	number     int
-	numberPath []int32
+	enum           Enum
+	number         int
+	numberPath     []int32
+	deprecated     bool
+	deprecatedPath []int32
 }
 
 func newEnumValue(
 	namedDescriptor namedDescriptor,
+	optionExtensionDescriptor optionExtensionDescriptor,
 	enum Enum,
 	number int,
 	numberPath []int32,
+	deprecated bool,
+	deprecatedPath []int32,
 ) *enumValue {
 	return &enumValue{
-		namedDescriptor: namedDescriptor,
-		enum:            enum,
-		number:          number,
-		numberPath:      numberPath,
+		namedDescriptor:           namedDescriptor,
+		optionExtensionDescriptor: optionExtensionDescriptor,
+		enum:                      enum,
+		number:                    number,
+		numberPath:                numberPath,
+		deprecated:                deprecated,
+		deprecatedPath:            deprecatedPath,
 	}
 }
 
+func (e *enumValue) Deprecated() bool {
+	return e.deprecated
+}
+
+func (e *enumValue) DeprecatedLocation() Location {
+	return e.getLocation(e.deprecatedPath)
+}
+
 func (e *enumValue) Enum() Enum {
 	return e.enum
 }
@@ -16,6 +16,7 @@ package protosource
 
 type field struct {
 	namedDescriptor
+	optionExtensionDescriptor
 
 	message  Message
 	number   int
@@ -24,23 +25,31 @@ type field struct {
 	typeName string
 	// this has to be the pointer to the private struct or you have the bug where the
 	// interface is nil but value == nil is false
-	oneof          *oneof
-	proto3Optional bool
-	jsonName       string
-	jsType         FieldOptionsJSType
-	cType          FieldOptionsCType
-	packed         *bool
-	numberPath     []int32
-	typePath       []int32
-	typeNamePath   []int32
-	jsonNamePath   []int32
-	jsTypePath     []int32
-	cTypePath      []int32
-	packedPath     []int32
+	oneof            *oneof
+	proto3Optional   bool
+	jsonName         string
+	jsType           FieldOptionsJSType
+	cType            FieldOptionsCType
+	packed           *bool
+	defaultValue     *string
+	numberPath       []int32
+	labelPath        []int32
+	typePath         []int32
+	typeNamePath     []int32
+	jsonNamePath     []int32
+	jsTypePath       []int32
+	cTypePath        []int32
+	packedPath       []int32
+	defaultValuePath []int32
+	deprecated       bool
+	deprecatedPath   []int32
+	extendee         string
+	extendeePath     []int32
 }
 
 func newField(
 	namedDescriptor namedDescriptor,
+	optionExtensionDescriptor optionExtensionDescriptor,
 	message Message,
 	number int,
 	label FieldDescriptorProtoLabel,
@@ -52,41 +61,72 @@ func newField(
 	jsType FieldOptionsJSType,
 	cType FieldOptionsCType,
 	packed *bool,
+	defaultValue *string,
 	numberPath []int32,
+	labelPath []int32,
 	typePath []int32,
 	typeNamePath []int32,
 	jsonNamePath []int32,
 	jsTypePath []int32,
 	cTypePath []int32,
 	packedPath []int32,
+	defaultValuePath []int32,
+	deprecated bool,
+	deprecatedPath []int32,
+	extendee string,
+	extendeePath []int32,
 ) *field {
 	return &field{
-		namedDescriptor: namedDescriptor,
-		message:         message,
-		number:          number,
-		label:           label,
-		typ:             typ,
-		typeName:        typeName,
-		oneof:           oneof,
-		proto3Optional:  proto3Optional,
-		jsonName:        jsonName,
-		jsType:          jsType,
-		cType:           cType,
-		packed:          packed,
-		numberPath:      numberPath,
-		typePath:        typePath,
-		typeNamePath:    typeNamePath,
-		jsonNamePath:    jsonNamePath,
-		jsTypePath:      jsTypePath,
-		cTypePath:       cTypePath,
-		packedPath:      packedPath,
+		namedDescriptor:           namedDescriptor,
+		optionExtensionDescriptor: optionExtensionDescriptor,
+		message:                   message,
+		number:                    number,
+		label:                     label,
+		typ:                       typ,
+		typeName:                  typeName,
+		oneof:                     oneof,
+		proto3Optional:            proto3Optional,
+		jsonName:                  jsonName,
+		jsType:                    jsType,
+		cType:                     cType,
+		packed:                    packed,
+		defaultValue:              defaultValue,
+		numberPath:                numberPath,
+		labelPath:                 labelPath,
+		typePath:                  typePath,
+		typeNamePath:              typeNamePath,
+		jsonNamePath:              jsonNamePath,
+		jsTypePath:                jsTypePath,
+		cTypePath:                 cTypePath,
+		packedPath:                packedPath,
+		defaultValuePath:          defaultValuePath,
+		deprecated:                deprecated,
+		deprecatedPath:            deprecatedPath,
+		extendee:                  extendee,
+		extendeePath:              extendeePath,
 	}
 }
 
+func (f *field) Deprecated() bool {
+	return f.deprecated
+}
+
+func (f *field) DeprecatedLocation() Location {
+	return f.getLocation(f.deprecatedPath)
+}
+
 func (f *field) Message() Message {
 	return f.message
 }
 
+func (f *field) Extendee() string {
+	return f.extendee
+}
+
+func (f *field) ExtendeeLocation() Location {
+	return f.getLocation(f.extendeePath)
+}
+
 func (f *field) Number() int {
 	return f.number
 }
@@ -132,10 +172,18 @@ func (f *field) Packed() *bool {
 	return f.packed
 }
 
+func (f *field) DefaultValue() *string {
+	return f.defaultValue
+}
+
 func (f *field) NumberLocation() Location {
 	return f.getLocation(f.numberPath)
 }
 
+func (f *field) LabelLocation() Location {
+	return f.getLocation(f.labelPath)
+}
+
 func (f *field) TypeLocation() Location {
 	return f.getLocation(f.typePath)
 }
@@ -159,3 +207,7 @@ func (f *field) CTypeLocation() Location {
 func (f *field) PackedLocation() Location {
 	return f.getLocation(f.packedPath)
 }
+
+func (f *field) DefaultValueLocation() Location {
+	return f.getLocation(f.defaultValuePath)
+}
@@ -24,12 +24,15 @@ type file struct {
 	FileInfo
 	descriptor
 
+	optionExtensionDescriptor
+
 	fileDescriptorProto *descriptorpb.FileDescriptorProto
 	syntax              Syntax
 	fileImports         []FileImport
 	messages            []Message
 	enums               []Enum
 	services            []Service
+	extensions          []Field
 	optimizeMode        FileOptionsOptimizeMode
 }
 
@@ -57,6 +60,10 @@ func (f *file) Services() []Service {
 	return f.services
 }
 
+func (f *file) Extensions() []Field {
+	return f.extensions
+}
+
 func (f *file) CsharpNamespace() string {
 	return f.fileDescriptorProto.GetOptions().GetCsharpNamespace()
 }
@@ -221,6 +228,11 @@ func newFile(inputFile InputFile) (*file, error) {
 		newLocationStore(f.fileDescriptorProto.GetSourceCodeInfo().GetLocation()),
 	)
 	f.descriptor = descriptor
+	f.optionExtensionDescriptor = newOptionExtensionDescriptor(
+		f.fileDescriptorProto.GetOptions(),
+		getFileOptionsPath(),
+		f.descriptor.locationStore,
+	)
 
 	syntaxString := f.fileDescriptorProto.GetSyntax()
 	if syntaxString == "" || syntaxString == "proto2" {
@@ -287,6 +299,16 @@ func newFile(inputFile InputFile) (*file, error) {
 		}
 		f.messages = append(f.messages, message)
 	}
+	for extensionIndex, fieldDescriptorProto := range f.fileDescriptorProto.GetExtension() {
+		extension, err := f.populateExtension(
+			fieldDescriptorProto,
+			extensionIndex,
+		)
+		if err != nil {
+			return nil, err
+		}
+		f.extensions = append(f.extensions, extension)
+	}
 	for serviceIndex, serviceDescriptorProto := range f.fileDescriptorProto.GetService() {
 		service, err := f.populateService(
 			serviceDescriptorProto,
@@ -328,8 +350,15 @@ func (f *file) populateEnum(
 	}
 	enum := newEnum(
 		enumNamedDescriptor,
+		newOptionExtensionDescriptor(
+			enumDescriptorProto.GetOptions(),
+			getEnumOptionsPath(enumIndex, nestedMessageIndexes...),
+			f.descriptor.locationStore,
+		),
 		enumDescriptorProto.GetOptions().GetAllowAlias(),
 		getEnumAllowAliasPath(enumIndex, nestedMessageIndexes...),
+		enumDescriptorProto.GetOptions().GetDeprecated(),
+		getEnumDeprecatedPath(enumIndex, nestedMessageIndexes...),
 	)
 
 	for enumValueIndex, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
@@ -347,9 +376,16 @@ func (f *file) populateEnum(
 		}
 		enumValue := newEnumValue(
 			enumValueNamedDescriptor,
+			newOptionExtensionDescriptor(
+				enumValueDescriptorProto.GetOptions(),
+				getEnumValueOptionsPath(enumIndex, enumValueIndex, nestedMessageIndexes...),
+				f.descriptor.locationStore,
+			),
 			enum,
 			int(enumValueDescriptorProto.GetNumber()),
 			getEnumValueNumberPath(enumIndex, enumValueIndex, nestedMessageIndexes...),
+			enumValueDescriptorProto.GetOptions().GetDeprecated(),
+			getEnumValueDeprecatedPath(enumIndex, enumValueIndex, nestedMessageIndexes...),
 		)
 		enum.addValue(enumValue)
 	}
@@ -408,12 +444,19 @@ func (f *file) populateMessage(
 	}
 	message := newMessage(
 		messageNamedDescriptor,
+		newOptionExtensionDescriptor(
+			descriptorProto.GetOptions(),
+			getMessageOptionsPath(topLevelMessageIndex, nestedMessageIndexes...),
+			f.descriptor.locationStore,
+		),
 		parent,
 		descriptorProto.GetOptions().GetMapEntry(),
 		descriptorProto.GetOptions().GetMessageSetWireFormat(),
 		descriptorProto.GetOptions().GetNoStandardDescriptorAccessor(),
 		getMessageMessageSetWireFormatPath(topLevelMessageIndex, nestedMessageIndexes...),
 		getMessageNoStandardDescriptorAccessorPath(topLevelMessageIndex, nestedMessageIndexes...),
+		descriptorProto.GetOptions().GetDeprecated(),
+		getMessageDeprecatedPath(topLevelMessageIndex, nestedMessageIndexes...),
 	)
 	oneofIndexToOneof := make(map[int]*oneof)
 	for oneofIndex, oneofDescriptorProto := range descriptorProto.GetOneofDecl() {
@@ -431,6 +474,11 @@ func (f *file) populateMessage(
 		}
 		oneof := newOneof(
 			oneofNamedDescriptor,
+			newOptionExtensionDescriptor(
+				oneofDescriptorProto.GetOptions(),
+				getMessageOneofOptionsPath(oneofIndex, topLevelMessageIndex, nestedMessageIndexes...),
+				f.descriptor.locationStore,
+			),
 			message,
 		)
 		message.addOneof(oneof)
@@ -481,6 +529,11 @@ func (f *file) populateMessage(
 		}
 		field := newField(
 			fieldNamedDescriptor,
+			newOptionExtensionDescriptor(
+				fieldDescriptorProto.GetOptions(),
+				getMessageFieldOptionsPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+				f.descriptor.locationStore,
+			),
 			message,
 			int(fieldDescriptorProto.GetNumber()),
 			label,
@@ -492,13 +545,20 @@ func (f *file) populateMessage(
 			jsType,
 			cType,
 			packed,
+			fieldDescriptorProto.DefaultValue,
 			getMessageFieldNumberPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageFieldLabelPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldTypeNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldJSONNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldJSTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldCTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageFieldPackedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageFieldDefaultValuePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			fieldDescriptorProto.GetOptions().GetDeprecated(),
+			getMessageFieldDeprecatedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			fieldDescriptorProto.GetExtendee(),
+			nil,
 		)
 		message.addField(field)
 		if oneof != nil {
@@ -550,6 +610,11 @@ func (f *file) populateMessage(
 		}
 		field := newField(
 			fieldNamedDescriptor,
+			newOptionExtensionDescriptor(
+				fieldDescriptorProto.GetOptions(),
+				getMessageExtensionOptionsPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+				f.descriptor.locationStore,
+			),
 			message,
 			int(fieldDescriptorProto.GetNumber()),
 			label,
@@ -561,13 +626,20 @@ func (f *file) populateMessage(
 			jsType,
 			cType,
 			packed,
+			fieldDescriptorProto.DefaultValue,
 			getMessageExtensionNumberPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageExtensionLabelPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionTypeNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionJSONNamePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionJSTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionCTypePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 			getMessageExtensionPackedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			getMessageExtensionDefaultValuePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			fieldDescriptorProto.GetOptions().GetDeprecated(),
+			getMessageExtensionDeprecatedPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
+			fieldDescriptorProto.GetExtendee(),
+			getMessageExtensionExtendeePath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...),
 		)
 		message.addExtension(field)
 		if oneof != nil {
@@ -644,6 +716,79 @@ func (f *file) populateMessage(
 	return message, nil
 }
 
+func (f *file) populateExtension(
+	fieldDescriptorProto *descriptorpb.FieldDescriptorProto,
+	extensionIndex int,
+) (Field, error) {
+	fieldNamedDescriptor, err := newNamedDescriptor(
+		newLocationDescriptor(
+			f.descriptor,
+			getFileExtensionPath(extensionIndex),
+		),
+		fieldDescriptorProto.GetName(),
+		getFileExtensionNamePath(extensionIndex),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	var packed *bool
+	if fieldDescriptorProto.Options != nil {
+		packed = fieldDescriptorProto.GetOptions().Packed
+	}
+	label, err := getFieldDescriptorProtoLabel(fieldDescriptorProto.GetLabel())
+	if err != nil {
+		return nil, err
+	}
+	typ, err := getFieldDescriptorProtoType(fieldDescriptorProto.GetType())
+	if err != nil {
+		return nil, err
+	}
+	jsType, err := getFieldOptionsJSType(fieldDescriptorProto.GetOptions().GetJstype())
+	if err != nil {
+		return nil, err
+	}
+	cType, err := getFieldOptionsCType(fieldDescriptorProto.GetOptions().GetCtype())
+	if err != nil {
+		return nil, err
+	}
+	return newField(
+		fieldNamedDescriptor,
+		newOptionExtensionDescriptor(
+			fieldDescriptorProto.GetOptions(),
+			getFileExtensionOptionsPath(extensionIndex),
+			f.descriptor.locationStore,
+		),
+		// top-level extensions are not declared in a message
+		nil,
+		int(fieldDescriptorProto.GetNumber()),
+		label,
+		typ,
+		fieldDescriptorProto.GetTypeName(),
+		// extensions cannot be in a oneof
+		nil,
+		fieldDescriptorProto.GetProto3Optional(),
+		fieldDescriptorProto.GetJsonName(),
+		jsType,
+		cType,
+		packed,
+		fieldDescriptorProto.DefaultValue,
+		getFileExtensionNumberPath(extensionIndex),
+		getFileExtensionLabelPath(extensionIndex),
+		getFileExtensionTypePath(extensionIndex),
+		getFileExtensionTypeNamePath(extensionIndex),
+		getFileExtensionJSONNamePath(extensionIndex),
+		getFileExtensionJSTypePath(extensionIndex),
+		getFileExtensionCTypePath(extensionIndex),
+		getFileExtensionPackedPath(extensionIndex),
+		getFileExtensionDefaultValuePath(extensionIndex),
+		fieldDescriptorProto.GetOptions().GetDeprecated(),
+		getFileExtensionDeprecatedPath(extensionIndex),
+		fieldDescriptorProto.GetExtendee(),
+		getFileExtensionExtendeePath(extensionIndex),
+	), nil
+}
+
 func (f *file) populateService(
 	serviceDescriptorProto *descriptorpb.ServiceDescriptorProto,
 	serviceIndex int,
@@ -662,6 +807,13 @@ func (f *file) populateService(
 	}
 	service := newService(
 		serviceNamedDescriptor,
+		newOptionExtensionDescriptor(
+			serviceDescriptorProto.GetOptions(),
+			getServiceOptionsPath(serviceIndex),
+			f.descriptor.locationStore,
+		),
+		serviceDescriptorProto.GetOptions().GetDeprecated(),
+		getServiceDeprecatedPath(serviceIndex),
 	)
 	for methodIndex, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
 		methodNamedDescriptor, err := newNamedDescriptor(
@@ -682,6 +834,11 @@ func (f *file) populateService(
 		}
 		method, err := newMethod(
 			methodNamedDescriptor,
+			newOptionExtensionDescriptor(
+				methodDescriptorProto.GetOptions(),
+				getMethodOptionsPath(serviceIndex, methodIndex),
+				f.descriptor.locationStore,
+			),
 			service,
 			methodDescriptorProto.GetInputType(),
 			methodDescriptorProto.GetOutputType(),
@@ -691,6 +848,8 @@ func (f *file) populateService(
 			getMethodOutputTypePath(serviceIndex, methodIndex),
 			idempotencyLevel,
 			getMethodIdempotencyLevelPath(serviceIndex, methodIndex),
+			methodDescriptorProto.GetOptions().GetDeprecated(),
+			getMethodDeprecatedPath(serviceIndex, methodIndex),
 		)
 		if err != nil {
 			return nil, err
@@ -34,6 +34,10 @@ func (i *inputFile) ExternalPath() string {
 	return i.fileDescriptorProto.GetName()
 }
 
+func (i *inputFile) IsImport() bool {
+	return false
+}
+
 func (i *inputFile) Proto() *descriptorpb.FileDescriptorProto {
 	return i.fileDescriptorProto
 }
@@ -16,6 +16,7 @@ package protosource
 
 type message struct {
 	namedDescriptor
+	optionExtensionDescriptor
 
 	fields                           []Field
 	extensions                       []Field
@@ -31,27 +32,44 @@ type message struct {
 	noStandardDescriptorAccessor     bool
 	messageSetWireFormatPath         []int32
 	noStandardDescriptorAccessorPath []int32
+	deprecated                       bool
+	deprecatedPath                   []int32
 }
 
 func newMessage(
 	namedDescriptor namedDescriptor,
+	optionExtensionDescriptor optionExtensionDescriptor,
 	parent Message,
 	isMapEntry bool,
 	messageSetWireFormat bool,
 	noStandardDescriptorAccessor bool,
 	messageSetWireFormatPath []int32,
 	noStandardDescriptorAccessorPath []int32,
+	deprecated bool,
+	deprecatedPath []int32,
 ) *message {
 	return &message{
 		namedDescriptor:                  namedDescriptor,
+		optionExtensionDescriptor:        optionExtensionDescriptor,
+		parent:                           parent,
 		isMapEntry:                       isMapEntry,
 		messageSetWireFormat:             messageSetWireFormat,
 		noStandardDescriptorAccessor:     noStandardDescriptorAccessor,
 		messageSetWireFormatPath:         messageSetWireFormatPath,
 		noStandardDescriptorAccessorPath: noStandardDescriptorAccessorPath,
+		deprecated:                       deprecated,
+		deprecatedPath:                   deprecatedPath,
 	}
 }
 
+func (m *message) Deprecated() bool {
+	return m.deprecated
+}
+
+func (m *message) DeprecatedLocation() Location {
+	return m.getLocation(m.deprecatedPath)
+}
+
 func (m *message) Fields() []Field {
 	return m.fields
 }
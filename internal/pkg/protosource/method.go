@@ -18,6 +18,7 @@ import "fmt"
 
 type method struct {
 	namedDescriptor
+	optionExtensionDescriptor
 
 	service              Service
 	inputTypeName        string
@@ -28,10 +29,13 @@ type method struct {
 	outputTypePath       []int32
 	idempotencyLevel     MethodOptionsIdempotencyLevel
 	idempotencyLevelPath []int32
+	deprecated           bool
+	deprecatedPath       []int32
 }
 
 func newMethod(
 	namedDescriptor namedDescriptor,
+	optionExtensionDescriptor optionExtensionDescriptor,
 	service Service,
 	inputTypeName string,
 	outputTypeName string,
@@ -41,6 +45,8 @@ func newMethod(
 	outputTypePath []int32,
 	idempotencyLevel MethodOptionsIdempotencyLevel,
 	idempotencyLevelPath []int32,
+	deprecated bool,
+	deprecatedPath []int32,
 ) (*method, error) {
 	if inputTypeName == "" {
 		return nil, fmt.Errorf("no inputTypeName on %q", namedDescriptor.name)
@@ -49,19 +55,30 @@ func newMethod(
 		return nil, fmt.Errorf("no outputTypeName on %q", namedDescriptor.name)
 	}
 	return &method{
-		namedDescriptor:      namedDescriptor,
-		service:              service,
-		inputTypeName:        inputTypeName,
-		outputTypeName:       outputTypeName,
-		clientStreaming:      clientStreaming,
-		serverStreaming:      serverStreaming,
-		inputTypePath:        inputTypePath,
-		outputTypePath:       outputTypePath,
-		idempotencyLevel:     idempotencyLevel,
-		idempotencyLevelPath: idempotencyLevelPath,
+		namedDescriptor:           namedDescriptor,
+		optionExtensionDescriptor: optionExtensionDescriptor,
+		service:                   service,
+		inputTypeName:             inputTypeName,
+		outputTypeName:            outputTypeName,
+		clientStreaming:           clientStreaming,
+		serverStreaming:           serverStreaming,
+		inputTypePath:             inputTypePath,
+		outputTypePath:            outputTypePath,
+		idempotencyLevel:          idempotencyLevel,
+		idempotencyLevelPath:      idempotencyLevelPath,
+		deprecated:                deprecated,
+		deprecatedPath:            deprecatedPath,
 	}, nil
 }
 
+func (m *method) Deprecated() bool {
+	return m.deprecated
+}
+
+func (m *method) DeprecatedLocation() Location {
+	return m.getLocation(m.deprecatedPath)
+}
+
 func (m *method) Service() Service {
 	return m.service
 }
@@ -0,0 +1,146 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protosource
+
+import (
+	"github.com/bufbuild/buf/internal/pkg/protoencoding"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type optionExtensionDescriptor struct {
+	options proto.Message
+	// optionExtensionNumbers are the field numbers of the extensions
+	// set on the options.
+	optionExtensionNumbers map[int32]struct{}
+	// optionsPath is the path of the options within the file.
+	optionsPath   []int32
+	locationStore *locationStore
+}
+
+func newOptionExtensionDescriptor(
+	options proto.Message,
+	optionsPath []int32,
+	locationStore *locationStore,
+) optionExtensionDescriptor {
+	return optionExtensionDescriptor{
+		options:                options,
+		optionExtensionNumbers: getOptionExtensionNumbers(options),
+		optionsPath:            optionsPath,
+		locationStore:          locationStore,
+	}
+}
+
+func newExtensionTypeResolver(files ...File) (protoregistry.ExtensionTypeResolver, error) {
+	if len(files) == 0 {
+		return &protoregistry.Types{}, nil
+	}
+	fileDescriptorProtos := make([]*descriptorpb.FileDescriptorProto, len(files))
+	for i, file := range files {
+		fileDescriptorProto, err := getFileDescriptorProto(file)
+		if err != nil {
+			return nil, err
+		}
+		fileDescriptorProtos[i] = fileDescriptorProto
+	}
+	return protoencoding.NewResolver(fileDescriptorProtos...)
+}
+
+func (o *optionExtensionDescriptor) HasOptionExtension(number int32) bool {
+	_, ok := o.optionExtensionNumbers[number]
+	return ok
+}
+
+func (o *optionExtensionDescriptor) OptionExtension(extensionType protoreflect.ExtensionType) (protoreflect.Value, bool) {
+	extensionDescriptor := extensionType.TypeDescriptor()
+	if !o.HasOptionExtension(int32(extensionDescriptor.Number())) {
+		return protoreflect.Value{}, false
+	}
+	message := o.options.ProtoReflect()
+	if message.Descriptor().FullName() != extensionDescriptor.ContainingMessage().FullName() {
+		return protoreflect.Value{}, false
+	}
+	// The extension is either unknown or of a type from the global registry,
+	// so the options are re-parsed with the given type to read the value.
+	data, err := proto.MarshalOptions{AllowPartial: true}.Marshal(o.options)
+	if err != nil {
+		return protoreflect.Value{}, false
+	}
+	resolver := &protoregistry.Types{}
+	if err := resolver.RegisterExtension(extensionType); err != nil {
+		return protoreflect.Value{}, false
+	}
+	parsedMessage := message.New()
+	if err := (proto.UnmarshalOptions{AllowPartial: true, Resolver: resolver}).Unmarshal(data, parsedMessage.Interface()); err != nil {
+		return protoreflect.Value{}, false
+	}
+	if !parsedMessage.Has(extensionDescriptor) {
+		return protoreflect.Value{}, false
+	}
+	return parsedMessage.Get(extensionDescriptor), true
+}
+
+func (o *optionExtensionDescriptor) OptionExtensionLocation(extensionType protoreflect.ExtensionType) Location {
+	if o.locationStore == nil {
+		return nil
+	}
+	extensionDescriptor := extensionType.TypeDescriptor()
+	path := make([]int32, len(o.optionsPath), len(o.optionsPath)+2)
+	copy(path, o.optionsPath)
+	path = append(path, int32(extensionDescriptor.Number()))
+	if location := o.locationStore.getLocation(path); location != nil {
+		return location
+	}
+	if extensionDescriptor.IsList() {
+		// each element of a repeated option has its own location, the
+		// location of the first element is used
+		return o.locationStore.getLocation(append(path, 0))
+	}
+	return nil
+}
+
+// getOptionExtensionNumbers returns the field numbers of the extensions set
+// on the options message.
+//
+// Extensions are either known to the global registry, in which case they are
+// set fields, or unknown, in which case they are in the unknown fields.
+func getOptionExtensionNumbers(options proto.Message) map[int32]struct{} {
+	optionExtensionNumbers := make(map[int32]struct{})
+	message := options.ProtoReflect()
+	if !message.IsValid() {
+		return optionExtensionNumbers
+	}
+	message.Range(
+		func(fieldDescriptor protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+			if fieldDescriptor.IsExtension() {
+				optionExtensionNumbers[int32(fieldDescriptor.Number())] = struct{}{}
+			}
+			return true
+		},
+	)
+	unknown := message.GetUnknown()
+	for len(unknown) > 0 {
+		number, _, n := protowire.ConsumeField(unknown)
+		if n < 0 {
+			break
+		}
+		optionExtensionNumbers[int32(number)] = struct{}{}
+		unknown = unknown[n:]
+	}
+	return optionExtensionNumbers
+}
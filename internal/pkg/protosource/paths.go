@@ -41,6 +41,10 @@ func getDependencyPath(dependencyIndex int) []int32 {
 	return []int32{3, int32(dependencyIndex)}
 }
 
+func getFileOptionsPath() []int32 {
+	return []int32{8}
+}
+
 func getMessagePath(topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	path := []int32{4, int32(topLevelMessageIndex)}
 	for _, nestedMessageIndex := range nestedMessageIndexes {
@@ -61,6 +65,14 @@ func getMessageNoStandardDescriptorAccessorPath(messageIndex int, nestedMessageI
 	return append(getMessagePath(messageIndex, nestedMessageIndexes...), 7, 2)
 }
 
+func getMessageDeprecatedPath(messageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessagePath(messageIndex, nestedMessageIndexes...), 7, 3)
+}
+
+func getMessageOptionsPath(messageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessagePath(messageIndex, nestedMessageIndexes...), 7)
+}
+
 func getMessageFieldPath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessagePath(topLevelMessageIndex, nestedMessageIndexes...), 2, int32(fieldIndex))
 }
@@ -73,6 +85,10 @@ func getMessageFieldNumberPath(fieldIndex int, topLevelMessageIndex int, nestedM
 	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 3)
 }
 
+func getMessageFieldLabelPath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 4)
+}
+
 func getMessageFieldTypePath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 5)
 }
@@ -97,6 +113,18 @@ func getMessageFieldPackedPath(fieldIndex int, topLevelMessageIndex int, nestedM
 	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 2)
 }
 
+func getMessageFieldDeprecatedPath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 3)
+}
+
+func getMessageFieldDefaultValuePath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 7)
+}
+
+func getMessageFieldOptionsPath(fieldIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageFieldPath(fieldIndex, topLevelMessageIndex, nestedMessageIndexes...), 8)
+}
+
 func getMessageExtensionPath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessagePath(topLevelMessageIndex, nestedMessageIndexes...), 6, int32(extensionIndex))
 }
@@ -109,6 +137,10 @@ func getMessageExtensionNumberPath(extensionIndex int, topLevelMessageIndex int,
 	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 3)
 }
 
+func getMessageExtensionLabelPath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 4)
+}
+
 func getMessageExtensionTypePath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 5)
 }
@@ -133,6 +165,22 @@ func getMessageExtensionPackedPath(extensionIndex int, topLevelMessageIndex int,
 	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 2)
 }
 
+func getMessageExtensionDeprecatedPath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 8, 3)
+}
+
+func getMessageExtensionExtendeePath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 2)
+}
+
+func getMessageExtensionDefaultValuePath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 7)
+}
+
+func getMessageExtensionOptionsPath(extensionIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageExtensionPath(extensionIndex, topLevelMessageIndex, nestedMessageIndexes...), 8)
+}
+
 func getMessageOneofPath(oneofIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessagePath(topLevelMessageIndex, nestedMessageIndexes...), 8, int32(oneofIndex))
 }
@@ -141,6 +189,10 @@ func getMessageOneofNamePath(oneofIndex int, topLevelMessageIndex int, nestedMes
 	return append(getMessageOneofPath(oneofIndex, topLevelMessageIndex, nestedMessageIndexes...), 1)
 }
 
+func getMessageOneofOptionsPath(oneofIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getMessageOneofPath(oneofIndex, topLevelMessageIndex, nestedMessageIndexes...), 2)
+}
+
 func getMessageReservedRangePath(reservedRangeIndex int, topLevelMessageIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getMessagePath(topLevelMessageIndex, nestedMessageIndexes...), 9, int32(reservedRangeIndex))
 }
@@ -171,6 +223,14 @@ func getEnumAllowAliasPath(enumIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 3, 2)
 }
 
+func getEnumDeprecatedPath(enumIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 3, 3)
+}
+
+func getEnumOptionsPath(enumIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 3)
+}
+
 func getEnumValuePath(enumIndex int, enumValueIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 2, int32(enumValueIndex))
 }
@@ -183,6 +243,14 @@ func getEnumValueNumberPath(enumIndex int, enumValueIndex int, nestedMessageInde
 	return append(getEnumValuePath(enumIndex, enumValueIndex, nestedMessageIndexes...), 2)
 }
 
+func getEnumValueDeprecatedPath(enumIndex int, enumValueIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getEnumValuePath(enumIndex, enumValueIndex, nestedMessageIndexes...), 3, 1)
+}
+
+func getEnumValueOptionsPath(enumIndex int, enumValueIndex int, nestedMessageIndexes ...int) []int32 {
+	return append(getEnumValuePath(enumIndex, enumValueIndex, nestedMessageIndexes...), 3)
+}
+
 func getEnumReservedRangePath(enumIndex int, reservedRangeIndex int, nestedMessageIndexes ...int) []int32 {
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 4, int32(reservedRangeIndex))
 }
@@ -191,6 +259,62 @@ func getEnumReservedNamePath(enumIndex int, reservedNameIndex int, nestedMessage
 	return append(getEnumPath(enumIndex, nestedMessageIndexes...), 5, int32(reservedNameIndex))
 }
 
+func getFileExtensionPath(extensionIndex int) []int32 {
+	return []int32{7, int32(extensionIndex)}
+}
+
+func getFileExtensionNamePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 1)
+}
+
+func getFileExtensionExtendeePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 2)
+}
+
+func getFileExtensionNumberPath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 3)
+}
+
+func getFileExtensionLabelPath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 4)
+}
+
+func getFileExtensionTypePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 5)
+}
+
+func getFileExtensionTypeNamePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 6)
+}
+
+func getFileExtensionDefaultValuePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 7)
+}
+
+func getFileExtensionJSONNamePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 10)
+}
+
+func getFileExtensionJSTypePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 8, 6)
+}
+
+func getFileExtensionCTypePath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 8, 1)
+}
+
+func getFileExtensionPackedPath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 8, 2)
+}
+
+func getFileExtensionDeprecatedPath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 8, 3)
+}
+
+func getFileExtensionOptionsPath(extensionIndex int) []int32 {
+	return append(getFileExtensionPath(extensionIndex), 8)
+}
+
 func getServicePath(serviceIndex int) []int32 {
 	return []int32{6, int32(serviceIndex)}
 }
@@ -199,6 +323,14 @@ func getServiceNamePath(serviceIndex int) []int32 {
 	return append(getServicePath(serviceIndex), 1)
 }
 
+func getServiceDeprecatedPath(serviceIndex int) []int32 {
+	return append(getServicePath(serviceIndex), 3, 33)
+}
+
+func getServiceOptionsPath(serviceIndex int) []int32 {
+	return append(getServicePath(serviceIndex), 3)
+}
+
 func getMethodPath(serviceIndex int, methodIndex int) []int32 {
 	return []int32{6, int32(serviceIndex), 2, int32(methodIndex)}
 }
@@ -218,3 +350,11 @@ func getMethodOutputTypePath(serviceIndex int, methodIndex int) []int32 {
 func getMethodIdempotencyLevelPath(serviceIndex int, methodIndex int) []int32 {
 	return append(getMethodPath(serviceIndex, methodIndex), 4, 34)
 }
+
+func getMethodDeprecatedPath(serviceIndex int, methodIndex int) []int32 {
+	return append(getMethodPath(serviceIndex, methodIndex), 4, 33)
+}
+
+func getMethodOptionsPath(serviceIndex int, methodIndex int) []int32 {
+	return append(getMethodPath(serviceIndex, methodIndex), 4)
+}
@@ -31,6 +31,8 @@ import (
 	"strings"
 
 	"github.com/bufbuild/buf/internal/pkg/normalpath"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -103,6 +105,25 @@ type NamedDescriptor interface {
 	NameLocation() Location
 }
 
+// OptionExtensionDescriptor is a descriptor that can have custom options,
+// that is extensions of its options message, set.
+type OptionExtensionDescriptor interface {
+	// HasOptionExtension returns true if the extension of the options with
+	// the given field number is set, for example 72295728 for google.api.http
+	// on a Method.
+	HasOptionExtension(number int32) bool
+	// OptionExtension returns the value of the extension of the options with
+	// the given type, and true if it is set.
+	//
+	// Use NewExtensionTypeResolver to get extension types by name.
+	OptionExtension(extensionType protoreflect.ExtensionType) (protoreflect.Value, bool)
+	// OptionExtensionLocation returns the location of the extension of the
+	// options with the given type.
+	//
+	// Can return nil.
+	OptionExtensionLocation(extensionType protoreflect.ExtensionType) Location
+}
+
 // ContainerDescriptor contains Enums and Messages.
 type ContainerDescriptor interface {
 	Enums() []Enum
@@ -143,12 +164,15 @@ type FileInfo interface {
 	//   RootDirPath: proto
 	//   ExternalPath: /foo/bar/proto/one/one.proto
 	ExternalPath() string
+	// IsImport returns true if this file is an import.
+	IsImport() bool
 }
 
 // File is a file descriptor.
 type File interface {
 	Descriptor
 	FileInfo
+	OptionExtensionDescriptor
 
 	// Top-level only.
 	ContainerDescriptor
@@ -157,6 +181,10 @@ type File interface {
 	Package() string
 	FileImports() []FileImport
 	Services() []Service
+	// Extensions are the extensions declared at the top level of the file.
+	//
+	// Extensions declared within messages are on Message.Extensions.
+	Extensions() []Field
 
 	CsharpNamespace() string
 	GoPackage() string
@@ -253,6 +281,7 @@ type MessageRange interface {
 // Enum is an enum descriptor.
 type Enum interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 	ReservedDescriptor
 
 	Values() []EnumValue
@@ -260,21 +289,27 @@ type Enum interface {
 
 	AllowAlias() bool
 	AllowAliasLocation() Location
+	Deprecated() bool
+	DeprecatedLocation() Location
 }
 
 // EnumValue is an enum value descriptor.
 type EnumValue interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 
 	Enum() Enum
 	Number() int
+	Deprecated() bool
 
 	NumberLocation() Location
+	DeprecatedLocation() Location
 }
 
 // Message is a message descriptor.
 type Message interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 	// Only those directly nested under this message.
 	ContainerDescriptor
 	ReservedDescriptor
@@ -292,15 +327,24 @@ type Message interface {
 
 	MessageSetWireFormat() bool
 	NoStandardDescriptorAccessor() bool
+	Deprecated() bool
 	MessageSetWireFormatLocation() Location
 	NoStandardDescriptorAccessorLocation() Location
+	DeprecatedLocation() Location
 }
 
 // Field is a field descriptor.
 type Field interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 
+	// Message is the message the field is declared in.
+	//
+	// This is nil for extensions declared at the top level of a file.
 	Message() Message
+	// Extendee is the full name of the message this extension extends, with a
+	// leading dot, or empty if this is not an extension.
+	Extendee() string
 	Number() int
 	Label() FieldDescriptorProtoLabel
 	Type() FieldDescriptorProtoType
@@ -314,19 +358,30 @@ type Field interface {
 	// Set vs unset matters for packed
 	// See the comments on descriptor.proto
 	Packed() *bool
+	// DefaultValue is the default value as it appears in the FieldDescriptorProto,
+	// or nil if no default value is set.
+	//
+	// See the comments on default_value in descriptor.proto for the format.
+	DefaultValue() *string
+	Deprecated() bool
 
 	NumberLocation() Location
+	LabelLocation() Location
 	TypeLocation() Location
 	TypeNameLocation() Location
 	JSONNameLocation() Location
 	JSTypeLocation() Location
 	CTypeLocation() Location
 	PackedLocation() Location
+	DefaultValueLocation() Location
+	DeprecatedLocation() Location
+	ExtendeeLocation() Location
 }
 
 // Oneof is a oneof descriptor.
 type Oneof interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 
 	Message() Message
 	Fields() []Field
@@ -335,13 +390,18 @@ type Oneof interface {
 // Service is a service descriptor.
 type Service interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 
 	Methods() []Method
+
+	Deprecated() bool
+	DeprecatedLocation() Location
 }
 
 // Method is a method descriptor.
 type Method interface {
 	NamedDescriptor
+	OptionExtensionDescriptor
 
 	Service() Service
 	InputTypeName() string
@@ -353,6 +413,8 @@ type Method interface {
 
 	IdempotencyLevel() MethodOptionsIdempotencyLevel
 	IdempotencyLevelLocation() Location
+	Deprecated() bool
+	DeprecatedLocation() Location
 }
 
 // InputFile is an input file for NewFile.
@@ -435,6 +497,35 @@ func PackageToFiles(files ...File) (map[string][]File, error) {
 	return mapFiles(files, File.Package)
 }
 
+// ReferenceResolver resolves the Files referenced by a File.
+type ReferenceResolver interface {
+	// ReferencedFilePaths returns the paths of the Files that define the messages,
+	// enums, extendees, and custom options referenced by the given File.
+	//
+	// References that cannot be resolved are ignored, and the path of the given
+	// File is never included.
+	ReferencedFilePaths(file File) (map[string]struct{}, error)
+}
+
+// NewReferenceResolver returns a new ReferenceResolver that resolves
+// references within the Files.
+//
+// The Files should include the imports of the Files that references are
+// resolved for. The resolver is built once for all Files, so it should be
+// reused across Files.
+func NewReferenceResolver(files ...File) (ReferenceResolver, error) {
+	return newReferenceResolver(files...)
+}
+
+// NewExtensionTypeResolver returns a new resolver for the extensions defined
+// in the Files, which can be used to read custom options by extension name
+// with OptionExtension.
+//
+// The Files should include all imports so that the extensions can be built.
+func NewExtensionTypeResolver(files ...File) (protoregistry.ExtensionTypeResolver, error) {
+	return newExtensionTypeResolver(files...)
+}
+
 // ForEachEnum calls f on each Enum in the given ContainerDescriptor, including nested Enums.
 //
 // Returns error and stops iterating if f returns error
@@ -634,6 +725,40 @@ func FullNameToMessage(files ...File) (map[string]Message, error) {
 	return fullNameToMessage, nil
 }
 
+// FullNameToExtension maps the extensions in the Files to a map from full name
+// to extension, including both top-level extensions and extensions declared
+// within messages.
+//
+// Returns error if the extensions do not have unique full names within the Files,
+// which should generally never happen for properly-formed Files.
+func FullNameToExtension(files ...File) (map[string]Field, error) {
+	fullNameToExtension := make(map[string]Field)
+	addExtensions := func(extensions []Field) error {
+		for _, extension := range extensions {
+			fullName := extension.FullName()
+			if _, ok := fullNameToExtension[fullName]; ok {
+				return fmt.Errorf("duplicate extension: %q", fullName)
+			}
+			fullNameToExtension[fullName] = extension
+		}
+		return nil
+	}
+	for _, file := range files {
+		if err := addExtensions(file.Extensions()); err != nil {
+			return nil, err
+		}
+		if err := ForEachMessage(
+			func(message Message) error {
+				return addExtensions(message.Extensions())
+			},
+			file,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return fullNameToExtension, nil
+}
+
 // PackageToNestedNameToMessage maps the Messages in the Files to a map from
 // package to nested name to Message.
 //
@@ -895,36 +1020,37 @@ func TagRangeString(tagRange TagRange) string {
 }
 
 // FreeMessageRangeString returns the string representation of the free ranges for the message.
+//
+// This matches the output of protoc --print_free_field_numbers for a single message.
 func FreeMessageRangeString(message Message) string {
 	freeRanges := FreeMessageRanges(message)
-	if len(freeRanges) == 0 {
-		return ""
-	}
 	suffixes := make([]string, len(freeRanges))
 	for i, freeRange := range freeRanges {
-		suffixes[i] = freeMessageRangeStringSuffix(freeRange)
+		suffixes[i] = " " + freeMessageRangeStringSuffix(freeRange)
 	}
 	return fmt.Sprintf(
-		"%- 35s free: %s",
-		freeRanges[0].Message().FullName(),
-		strings.Join(suffixes, " "),
+		"%-35s free:%s",
+		message.FullName(),
+		strings.Join(suffixes, ""),
 	)
 }
 
+// FreeMessageRangeMessages returns the message and all of its nested messages
+// in the order protoc --print_free_field_numbers prints them, that is nested
+// messages are returned before their parent message.
+//
+// Like protoc, groups are not returned, as their fields are considered to be part
+// of their parent message by FreeMessageRanges. Messages nested within groups are returned.
+func FreeMessageRangeMessages(message Message) []Message {
+	return freeMessageRangeMessagesRec(nil, message)
+}
+
 // FreeMessageRanges returns the free message ranges for the given message.
 //
-// Not recursive.
+// Not recursive, except that like protoc, the fields, reserved ranges, and extension
+// ranges of groups are considered to be part of their parent message.
 func FreeMessageRanges(message Message) []MessageRange {
-	used := append(
-		message.ReservedMessageRanges(),
-		message.ExtensionMessageRanges()...,
-	)
-	for _, field := range message.Fields() {
-		used = append(
-			used,
-			newFreeMessageRange(message, field.Number(), field.Number()),
-		)
-	}
+	used := usedMessageRangesRec(nil, message, message)
 	sort.Slice(used, func(i, j int) bool {
 		return used[i].Start() < used[j].Start()
 	})
@@ -932,14 +1058,16 @@ func FreeMessageRanges(message Message) []MessageRange {
 	unused := make([]MessageRange, 0, len(used)+1)
 	last := 0
 	for _, r := range used {
-		if r.Start() <= last+1 {
-			last = r.End()
+		// this happens when ranges overlap, for example a field within a reserved range
+		if r.End() <= last {
 			continue
 		}
-		unused = append(
-			unused,
-			newFreeMessageRange(message, last+1, r.Start()-1),
-		)
+		if r.Start() > last+1 {
+			unused = append(
+				unused,
+				newFreeMessageRange(message, last+1, r.Start()-1),
+			)
+		}
 		last = r.End()
 	}
 	if last < messageRangeInclusiveMax {
@@ -951,15 +1079,59 @@ func FreeMessageRanges(message Message) []MessageRange {
 	return unused
 }
 
+func freeMessageRangeMessagesRec(messages []Message, message Message) []Message {
+	groupMessageNames := getGroupMessageNames(message)
+	for _, nestedMessage := range message.Messages() {
+		if _, ok := groupMessageNames[nestedMessage.FullName()]; ok {
+			// the group itself is not returned, but the messages nested within it are
+			for _, groupNestedMessage := range nestedMessage.Messages() {
+				messages = freeMessageRangeMessagesRec(messages, groupNestedMessage)
+			}
+			continue
+		}
+		messages = freeMessageRangeMessagesRec(messages, nestedMessage)
+	}
+	return append(messages, message)
+}
+
+func usedMessageRangesRec(used []MessageRange, freeMessage Message, message Message) []MessageRange {
+	used = append(used, message.ReservedMessageRanges()...)
+	used = append(used, message.ExtensionMessageRanges()...)
+	for _, field := range message.Fields() {
+		used = append(
+			used,
+			newFreeMessageRange(freeMessage, field.Number(), field.Number()),
+		)
+	}
+	groupMessageNames := getGroupMessageNames(message)
+	for _, nestedMessage := range message.Messages() {
+		if _, ok := groupMessageNames[nestedMessage.FullName()]; ok {
+			used = usedMessageRangesRec(used, freeMessage, nestedMessage)
+		}
+	}
+	return used
+}
+
+func getGroupMessageNames(message Message) map[string]struct{} {
+	groupMessageNames := make(map[string]struct{})
+	for _, field := range message.Fields() {
+		if field.Type() == FieldDescriptorProtoTypeGroup {
+			groupMessageNames[strings.TrimPrefix(field.TypeName(), ".")] = struct{}{}
+		}
+	}
+	return groupMessageNames
+}
+
 func freeMessageRangeStringSuffix(freeRange MessageRange) string {
 	start := freeRange.Start()
+	// protoc prints the range to the maximum as open-ended even if it is a single number
+	if freeRange.Max() {
+		return fmt.Sprintf("%d-INF", start)
+	}
 	end := freeRange.End()
 	if start == end {
 		return fmt.Sprintf("%d", start)
 	}
-	if freeRange.Max() {
-		return fmt.Sprintf("%d-INF", start)
-	}
 	return fmt.Sprintf("%d-%d", start, end)
 }
 
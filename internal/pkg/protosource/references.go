@@ -0,0 +1,298 @@
+// Copyright 2020-2021 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protosource
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// extensionKey identifies an extension by the full name of the
+// message it extends and its field number.
+type extensionKey struct {
+	extendee string
+	number   int32
+}
+
+// referenceExtension is an extension and the path of the file that defines it.
+type referenceExtension struct {
+	filePath             string
+	fieldDescriptorProto *descriptorpb.FieldDescriptorProto
+}
+
+// referenceResolver resolves full names and extensions to the paths
+// of the files that define them.
+type referenceResolver struct {
+	fullNameToFilePath        map[string]string
+	fullNameToDescriptorProto map[string]*descriptorpb.DescriptorProto
+	extensionKeyToExtension   map[extensionKey]*referenceExtension
+}
+
+func newReferenceResolver(files ...File) (*referenceResolver, error) {
+	referenceResolver := &referenceResolver{
+		fullNameToFilePath:        make(map[string]string),
+		fullNameToDescriptorProto: make(map[string]*descriptorpb.DescriptorProto),
+		extensionKeyToExtension:   make(map[extensionKey]*referenceExtension),
+	}
+	for _, file := range files {
+		fileDescriptorProto, err := getFileDescriptorProto(file)
+		if err != nil {
+			return nil, err
+		}
+		referenceResolver.addFile(file.Path(), fileDescriptorProto)
+	}
+	return referenceResolver, nil
+}
+
+func (r *referenceResolver) ReferencedFilePaths(file File) (map[string]struct{}, error) {
+	fileDescriptorProto, err := getFileDescriptorProto(file)
+	if err != nil {
+		return nil, err
+	}
+	referencedFilePaths := make(map[string]struct{})
+	if err := r.walkFile(fileDescriptorProto, referencedFilePaths); err != nil {
+		return nil, err
+	}
+	delete(referencedFilePaths, file.Path())
+	return referencedFilePaths, nil
+}
+
+func (r *referenceResolver) addFile(filePath string, fileDescriptorProto *descriptorpb.FileDescriptorProto) {
+	pkg := fileDescriptorProto.GetPackage()
+	for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+		r.addMessage(filePath, joinFullName(pkg, descriptorProto.GetName()), descriptorProto)
+	}
+	for _, enumDescriptorProto := range fileDescriptorProto.GetEnumType() {
+		r.fullNameToFilePath[joinFullName(pkg, enumDescriptorProto.GetName())] = filePath
+	}
+	for _, fieldDescriptorProto := range fileDescriptorProto.GetExtension() {
+		r.addExtension(filePath, fieldDescriptorProto)
+	}
+}
+
+func (r *referenceResolver) addMessage(filePath string, fullName string, descriptorProto *descriptorpb.DescriptorProto) {
+	r.fullNameToFilePath[fullName] = filePath
+	r.fullNameToDescriptorProto[fullName] = descriptorProto
+	for _, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+		r.addMessage(filePath, joinFullName(fullName, nestedDescriptorProto.GetName()), nestedDescriptorProto)
+	}
+	for _, enumDescriptorProto := range descriptorProto.GetEnumType() {
+		r.fullNameToFilePath[joinFullName(fullName, enumDescriptorProto.GetName())] = filePath
+	}
+	for _, fieldDescriptorProto := range descriptorProto.GetExtension() {
+		r.addExtension(filePath, fieldDescriptorProto)
+	}
+}
+
+func (r *referenceResolver) addExtension(filePath string, fieldDescriptorProto *descriptorpb.FieldDescriptorProto) {
+	r.extensionKeyToExtension[extensionKey{
+		extendee: strings.TrimPrefix(fieldDescriptorProto.GetExtendee(), "."),
+		number:   fieldDescriptorProto.GetNumber(),
+	}] = &referenceExtension{
+		filePath:             filePath,
+		fieldDescriptorProto: fieldDescriptorProto,
+	}
+}
+
+func (r *referenceResolver) walkFile(fileDescriptorProto *descriptorpb.FileDescriptorProto, referencedFilePaths map[string]struct{}) error {
+	if err := r.walkOptions(fileDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+		return err
+	}
+	for _, descriptorProto := range fileDescriptorProto.GetMessageType() {
+		if err := r.walkMessage(descriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, enumDescriptorProto := range fileDescriptorProto.GetEnumType() {
+		if err := r.walkEnum(enumDescriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, fieldDescriptorProto := range fileDescriptorProto.GetExtension() {
+		if err := r.walkField(fieldDescriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, serviceDescriptorProto := range fileDescriptorProto.GetService() {
+		if err := r.walkOptions(serviceDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+			return err
+		}
+		for _, methodDescriptorProto := range serviceDescriptorProto.GetMethod() {
+			if err := r.walkOptions(methodDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+				return err
+			}
+			r.addFullName(methodDescriptorProto.GetInputType(), referencedFilePaths)
+			r.addFullName(methodDescriptorProto.GetOutputType(), referencedFilePaths)
+		}
+	}
+	return nil
+}
+
+func (r *referenceResolver) walkMessage(descriptorProto *descriptorpb.DescriptorProto, referencedFilePaths map[string]struct{}) error {
+	if err := r.walkOptions(descriptorProto.GetOptions(), referencedFilePaths); err != nil {
+		return err
+	}
+	for _, fieldDescriptorProto := range descriptorProto.GetField() {
+		if err := r.walkField(fieldDescriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, fieldDescriptorProto := range descriptorProto.GetExtension() {
+		if err := r.walkField(fieldDescriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, oneofDescriptorProto := range descriptorProto.GetOneofDecl() {
+		if err := r.walkOptions(oneofDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, extensionRange := range descriptorProto.GetExtensionRange() {
+		if err := r.walkOptions(extensionRange.GetOptions(), referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, nestedDescriptorProto := range descriptorProto.GetNestedType() {
+		if err := r.walkMessage(nestedDescriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	for _, enumDescriptorProto := range descriptorProto.GetEnumType() {
+		if err := r.walkEnum(enumDescriptorProto, referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *referenceResolver) walkEnum(enumDescriptorProto *descriptorpb.EnumDescriptorProto, referencedFilePaths map[string]struct{}) error {
+	if err := r.walkOptions(enumDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+		return err
+	}
+	for _, enumValueDescriptorProto := range enumDescriptorProto.GetValue() {
+		if err := r.walkOptions(enumValueDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *referenceResolver) walkField(fieldDescriptorProto *descriptorpb.FieldDescriptorProto, referencedFilePaths map[string]struct{}) error {
+	if err := r.walkOptions(fieldDescriptorProto.GetOptions(), referencedFilePaths); err != nil {
+		return err
+	}
+	r.addFullName(fieldDescriptorProto.GetTypeName(), referencedFilePaths)
+	r.addFullName(fieldDescriptorProto.GetExtendee(), referencedFilePaths)
+	return nil
+}
+
+// walkOptions adds the files that define the custom options set on the options message.
+//
+// Custom options are either known extension fields or, more commonly, unknown fields
+// that have not been resolved against their extension. Both are handled by walking
+// the serialized options, which also finds the extensions set within the values of
+// custom options, such as (b) in "option (a).(b) = 1;".
+func (r *referenceResolver) walkOptions(options proto.Message, referencedFilePaths map[string]struct{}) error {
+	message := options.ProtoReflect()
+	if !message.IsValid() {
+		return nil
+	}
+	data, err := proto.MarshalOptions{AllowPartial: true}.Marshal(options)
+	if err != nil {
+		return err
+	}
+	r.walkMessageData(string(message.Descriptor().FullName()), data, referencedFilePaths)
+	return nil
+}
+
+// walkMessageData adds the files that define the extensions set within the
+// serialized message with the given full name, recursing into the values of
+// message fields and extensions.
+//
+// Fields of messages that are not defined within the files cannot be
+// resolved, except for extensions, and are skipped.
+func (r *referenceResolver) walkMessageData(fullName string, data []byte, referencedFilePaths map[string]struct{}) {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return
+		}
+		data = data[n:]
+		n = protowire.ConsumeFieldValue(number, wireType, data)
+		if n < 0 {
+			return
+		}
+		value := data[:n]
+		data = data[n:]
+		var fieldDescriptorProto *descriptorpb.FieldDescriptorProto
+		if extension, ok := r.extensionKeyToExtension[extensionKey{extendee: fullName, number: int32(number)}]; ok {
+			referencedFilePaths[extension.filePath] = struct{}{}
+			fieldDescriptorProto = extension.fieldDescriptorProto
+		} else {
+			fieldDescriptorProto = r.getField(fullName, int32(number))
+		}
+		typeName := strings.TrimPrefix(fieldDescriptorProto.GetTypeName(), ".")
+		switch {
+		case wireType == protowire.BytesType && fieldDescriptorProto.GetType() == descriptorpb.FieldDescriptorProto_TYPE_MESSAGE:
+			if messageData, n := protowire.ConsumeBytes(value); n >= 0 {
+				r.walkMessageData(typeName, messageData, referencedFilePaths)
+			}
+		case wireType == protowire.StartGroupType && fieldDescriptorProto.GetType() == descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+			if groupData, n := protowire.ConsumeGroup(number, value); n >= 0 {
+				r.walkMessageData(typeName, groupData, referencedFilePaths)
+			}
+		}
+	}
+}
+
+// getField returns the field of the message with the given full name and
+// number, or nil if the message is not defined within the files or has no
+// such field.
+func (r *referenceResolver) getField(fullName string, number int32) *descriptorpb.FieldDescriptorProto {
+	for _, fieldDescriptorProto := range r.fullNameToDescriptorProto[fullName].GetField() {
+		if fieldDescriptorProto.GetNumber() == number {
+			return fieldDescriptorProto
+		}
+	}
+	return nil
+}
+
+func (r *referenceResolver) addFullName(fullName string, referencedFilePaths map[string]struct{}) {
+	if fullName == "" {
+		return
+	}
+	if filePath, ok := r.fullNameToFilePath[strings.TrimPrefix(fullName, ".")]; ok {
+		referencedFilePaths[filePath] = struct{}{}
+	}
+}
+
+func getFileDescriptorProto(f File) (*descriptorpb.FileDescriptorProto, error) {
+	fileImpl, ok := f.(*file)
+	if !ok {
+		return nil, fmt.Errorf("unknown File implementation: %T", f)
+	}
+	return fileImpl.fileDescriptorProto, nil
+}
+
+func joinFullName(prefix string, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
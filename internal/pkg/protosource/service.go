@@ -16,18 +16,35 @@ package protosource
 
 type service struct {
 	namedDescriptor
+	optionExtensionDescriptor
 
-	methods []Method
+	methods        []Method
+	deprecated     bool
+	deprecatedPath []int32
 }
 
 func newService(
 	namedDescriptor namedDescriptor,
+	optionExtensionDescriptor optionExtensionDescriptor,
+	deprecated bool,
+	deprecatedPath []int32,
 ) *service {
 	return &service{
-		namedDescriptor: namedDescriptor,
+		namedDescriptor:           namedDescriptor,
+		optionExtensionDescriptor: optionExtensionDescriptor,
+		deprecated:                deprecated,
+		deprecatedPath:            deprecatedPath,
 	}
 }
 
+func (m *service) Deprecated() bool {
+	return m.deprecated
+}
+
+func (m *service) DeprecatedLocation() Location {
+	return m.getLocation(m.deprecatedPath)
+}
+
 func (m *service) Methods() []Method {
 	return m.methods
 }